@@ -11,6 +11,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -115,6 +116,8 @@ func serve(cfg config.Config, startedAt time.Time, logger *log.Logger) error {
 	runCtx, stopSignal := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stopSignal()
 
+	app.RunJobs(runCtx, logger)
+
 	if err := runHTTPServer(runCtx, srv, addr, logger); err != nil {
 		return fmt.Errorf("server failed: %w", err)
 	}
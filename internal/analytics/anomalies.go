@@ -0,0 +1,263 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	anomalyDefaultReviewDays = 7
+	anomalyDefaultWindowDays = 180
+	anomalyMinWindowDays     = 28
+	anomalyMaxWindowDays     = 730
+	anomalyDefaultThreshold  = 3.5
+	anomalyMinSamples        = 5
+	anomalyMinWeeks          = 4
+
+	// madScale makes the median absolute deviation comparable to a standard
+	// deviation for normally distributed data.
+	madScale = 0.6745
+)
+
+type amountOutlier struct {
+	Row    ExpenseRow
+	Median int64
+	Score  float64
+}
+
+type newMerchant struct {
+	Key   string
+	First ExpenseRow
+	Count int
+	Total int64
+}
+
+type categorySpike struct {
+	CategoryID     *uuid.UUID
+	CategoryName   string
+	WeekStart      time.Time
+	Total          int64
+	BaselineMedian int64
+	Score          float64
+}
+
+type anomalyReport struct {
+	Outliers  []amountOutlier
+	Merchants []newMerchant
+	Spikes    []categorySpike
+}
+
+// detectAnomalies splits rows into a trailing baseline [baselineFrom,
+// reviewFrom) and a review period [reviewFrom, reviewTo) and reports
+// review-period spending that stands out above the baseline.
+func detectAnomalies(rows []ExpenseRow, baselineFrom, reviewFrom, reviewTo time.Time, threshold float64) anomalyReport {
+	var baseline, review []ExpenseRow
+	for _, r := range rows {
+		switch {
+		case r.OccurredAt.Before(baselineFrom):
+		case r.OccurredAt.Before(reviewFrom):
+			baseline = append(baseline, r)
+		case r.OccurredAt.Before(reviewTo):
+			review = append(review, r)
+		}
+	}
+
+	return anomalyReport{
+		Outliers:  detectAmountOutliers(baseline, review, threshold),
+		Merchants: detectNewMerchants(baseline, review),
+		Spikes:    detectCategorySpikes(baseline, review, baselineFrom, reviewFrom, reviewTo, threshold),
+	}
+}
+
+func categoryKey(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// detectAmountOutliers flags review expenses far above their category's
+// usual amount. Unusually small expenses are not anomalies worth reporting.
+func detectAmountOutliers(baseline, review []ExpenseRow, threshold float64) []amountOutlier {
+	history := map[string][]float64{}
+	for _, r := range baseline {
+		k := categoryKey(r.CategoryID)
+		history[k] = append(history[k], float64(r.AmountMinor))
+	}
+
+	var out []amountOutlier
+	for _, r := range review {
+		h := history[categoryKey(r.CategoryID)]
+		if len(h) < anomalyMinSamples {
+			continue
+		}
+		med, score, ok := robustZ(h, float64(r.AmountMinor))
+		if !ok || score < threshold {
+			continue
+		}
+		out = append(out, amountOutlier{Row: r, Median: int64(math.Round(med)), Score: score})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+func detectNewMerchants(baseline, review []ExpenseRow) []newMerchant {
+	if len(baseline) == 0 {
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+	for _, r := range baseline {
+		if k := normalizeNote(r.Note); k != "" {
+			seen[k] = struct{}{}
+		}
+	}
+
+	byKey := map[string]*newMerchant{}
+	var order []string
+	for _, r := range review {
+		k := normalizeNote(r.Note)
+		if k == "" {
+			continue
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		m, ok := byKey[k]
+		if !ok {
+			m = &newMerchant{Key: k, First: r}
+			byKey[k] = m
+			order = append(order, k)
+		}
+		m.Count++
+		m.Total += r.AmountMinor
+	}
+
+	out := make([]newMerchant, 0, len(order))
+	for _, k := range order {
+		out = append(out, *byKey[k])
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Total > out[j].Total })
+	return out
+}
+
+// detectCategorySpikes compares each category's spending in 7-day windows of
+// the review period with its 7-day windows in the baseline. Windows are
+// anchored on reviewFrom so none straddles the two periods. The oldest
+// baseline window is dropped when it would start before baselineFrom, and a
+// short last review window is compared with a pro-rated baseline, so partial
+// weeks never look like drops or spikes.
+func detectCategorySpikes(baseline, review []ExpenseRow, baselineFrom, reviewFrom, reviewTo time.Time, threshold float64) []categorySpike {
+	baseWindows := daysBetween(baselineFrom, reviewFrom) / 7
+	if len(baseline) == 0 || baseWindows < anomalyMinWeeks {
+		return nil
+	}
+	reviewDays := daysBetween(reviewFrom, reviewTo)
+
+	names := map[string]string{}
+	ids := map[string]*uuid.UUID{}
+	remember := func(r ExpenseRow) string {
+		k := categoryKey(r.CategoryID)
+		names[k] = r.CategoryName
+		ids[k] = r.CategoryID
+		return k
+	}
+
+	// baseWeeks[k][i] is the total of the i-th window before reviewFrom.
+	baseWeeks := map[string][]float64{}
+	for _, r := range baseline {
+		back := (daysBetween(r.OccurredAt, reviewFrom) - 1) / 7
+		if back < 0 || back >= baseWindows {
+			continue
+		}
+		k := remember(r)
+		if baseWeeks[k] == nil {
+			baseWeeks[k] = make([]float64, baseWindows)
+		}
+		baseWeeks[k][back] += float64(r.AmountMinor)
+	}
+
+	reviewWeeks := map[string]map[int]int64{}
+	for _, r := range review {
+		k := remember(r)
+		if reviewWeeks[k] == nil {
+			reviewWeeks[k] = map[int]int64{}
+		}
+		reviewWeeks[k][daysBetween(reviewFrom, r.OccurredAt)/7] += r.AmountMinor
+	}
+
+	var out []categorySpike
+	for k, weeks := range reviewWeeks {
+		hist := baseWeeks[k]
+		if hist == nil {
+			hist = make([]float64, baseWindows)
+		}
+
+		for i, total := range weeks {
+			expected := hist
+			if days := reviewDays - 7*i; days < 7 {
+				expected = make([]float64, len(hist))
+				for j, v := range hist {
+					expected[j] = v * float64(days) / 7
+				}
+			}
+			med, score, ok := robustZ(expected, float64(total))
+			if !ok || score < threshold {
+				continue
+			}
+			out = append(out, categorySpike{
+				CategoryID:     ids[k],
+				CategoryName:   names[k],
+				WeekStart:      reviewFrom.AddDate(0, 0, 7*i),
+				Total:          total,
+				BaselineMedian: int64(math.Round(med)),
+				Score:          score,
+			})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].WeekStart.Equal(out[j].WeekStart) {
+			return out[i].WeekStart.Before(out[j].WeekStart)
+		}
+		return out[i].Score > out[j].Score
+	})
+	return out
+}
+
+// daysBetween counts calendar days from a to b, both in the same location.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return int(time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// robustZ returns the median of xs and the modified z-score of x against it.
+// When the MAD is zero (e.g. a fixed monthly fee) it falls back to the mean
+// absolute deviation; ok is false if the sample has no spread at all.
+func robustZ(xs []float64, x float64) (float64, float64, bool) {
+	med := median(xs)
+
+	dev := make([]float64, len(xs))
+	for i, v := range xs {
+		dev[i] = math.Abs(v - med)
+	}
+	mad := median(dev)
+	if mad > 0 {
+		return med, madScale * (x - med) / mad, true
+	}
+
+	var sum float64
+	for _, d := range dev {
+		sum += d
+	}
+	meanAD := sum / float64(len(dev))
+	if meanAD > 0 {
+		return med, (x - med) / (1.253314 * meanAD), true
+	}
+	return med, 0, false
+}
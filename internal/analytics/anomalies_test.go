@@ -0,0 +1,131 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	groceries := uuid.New()
+	reviewFrom := day(2025, 3, 3)
+	reviewTo := day(2025, 3, 10)
+
+	var rows []ExpenseRow
+	for i := 0; i < 8; i++ {
+		rows = append(rows, ExpenseRow{
+			ID:           uuid.New(),
+			CategoryID:   &groceries,
+			CategoryName: "Groceries",
+			Note:         "Silpo",
+			AmountMinor:  int64(1000 + 50*(i%3)),
+			OccurredAt:   day(2025, 1, 6).AddDate(0, 0, 7*i),
+		})
+	}
+
+	spike := ExpenseRow{
+		ID:           uuid.New(),
+		CategoryID:   &groceries,
+		CategoryName: "Groceries",
+		Note:         "Metro Cash&Carry",
+		AmountMinor:  9000,
+		OccurredAt:   day(2025, 3, 4),
+	}
+	normal := ExpenseRow{
+		ID:           uuid.New(),
+		CategoryID:   &groceries,
+		CategoryName: "Groceries",
+		Note:         "Silpo",
+		AmountMinor:  1050,
+		OccurredAt:   day(2025, 3, 5),
+	}
+	rows = append(rows, spike, normal)
+
+	rep := detectAnomalies(rows, day(2025, 1, 6), reviewFrom, reviewTo, anomalyDefaultThreshold)
+
+	if len(rep.Outliers) != 1 || rep.Outliers[0].Row.ID != spike.ID {
+		t.Fatalf("expected only the 9000 expense as outlier, got %+v", rep.Outliers)
+	}
+	if len(rep.Merchants) != 1 || rep.Merchants[0].Key != "metro cash carry" {
+		t.Fatalf("expected Metro as new merchant, got %+v", rep.Merchants)
+	}
+	if len(rep.Spikes) != 1 || rep.Spikes[0].Total != 10050 {
+		t.Fatalf("expected one weekly spike of 10050, got %+v", rep.Spikes)
+	}
+}
+
+func TestRobustZ_ZeroSpread(t *testing.T) {
+	if _, _, ok := robustZ([]float64{500, 500, 500}, 900); ok {
+		t.Fatalf("expected no score for a sample without spread")
+	}
+}
+
+// dailyExpenses returns one expense per day in [from, to), cycling through
+// amounts.
+func dailyExpenses(cat *uuid.UUID, from, to time.Time, amounts ...int64) []ExpenseRow {
+	var rows []ExpenseRow
+	for i, d := 0, from; d.Before(to); i, d = i+1, d.AddDate(0, 0, 1) {
+		rows = append(rows, ExpenseRow{
+			ID:           uuid.New(),
+			CategoryID:   cat,
+			CategoryName: "Groceries",
+			AmountMinor:  amounts[i%len(amounts)],
+			OccurredAt:   d,
+		})
+	}
+	return rows
+}
+
+func TestCategorySpikes_PartialWeeks(t *testing.T) {
+	groceries := uuid.New()
+	reviewFrom := day(2025, 3, 5) // a Wednesday
+	baselineFrom := reviewFrom.AddDate(0, 0, -30)
+
+	// Four full windows of 100..300 a day plus two leftover days of huge
+	// spending that would inflate the baseline if counted.
+	rows := dailyExpenses(&groceries, baselineFrom.AddDate(0, 0, 2), reviewFrom, 100, 200, 300)
+	rows = append(rows, dailyExpenses(&groceries, baselineFrom, baselineFrom.AddDate(0, 0, 2), 50000)...)
+
+	// Three review days at the usual rate are not a spike, even though a
+	// full baseline week is more than twice as much.
+	normal := append(rows, dailyExpenses(&groceries, reviewFrom, reviewFrom.AddDate(0, 0, 3), 200)...)
+	if rep := detectAnomalies(normal, baselineFrom, reviewFrom, reviewFrom.AddDate(0, 0, 3), anomalyDefaultThreshold); len(rep.Spikes) != 0 {
+		t.Fatalf("expected no spike for usual spending over a short window, got %+v", rep.Spikes)
+	}
+
+	// The same short window at four times the rate is.
+	heavy := append(rows, dailyExpenses(&groceries, reviewFrom, reviewFrom.AddDate(0, 0, 3), 800)...)
+	rep := detectAnomalies(heavy, baselineFrom, reviewFrom, reviewFrom.AddDate(0, 0, 3), anomalyDefaultThreshold)
+	if len(rep.Spikes) != 1 {
+		t.Fatalf("expected one spike, got %+v", rep.Spikes)
+	}
+	sp := rep.Spikes[0]
+	if !sp.WeekStart.Equal(reviewFrom) || sp.Total != 2400 {
+		t.Fatalf("unexpected spike %+v", sp)
+	}
+	if sp.BaselineMedian < 500 || sp.BaselineMedian > 700 {
+		t.Fatalf("expected a pro-rated baseline of about 600, got %d", sp.BaselineMedian)
+	}
+}
+
+func TestDetectAnomalies_IgnoresDrops(t *testing.T) {
+	groceries := uuid.New()
+	reviewFrom := day(2025, 3, 3)
+	reviewTo := reviewFrom.AddDate(0, 0, 7)
+	baselineFrom := reviewFrom.AddDate(0, 0, -56)
+
+	rows := dailyExpenses(&groceries, baselineFrom, reviewFrom, 1000, 1200, 1500)
+	rows = append(rows, ExpenseRow{
+		ID:           uuid.New(),
+		CategoryID:   &groceries,
+		CategoryName: "Groceries",
+		AmountMinor:  1,
+		OccurredAt:   reviewFrom.AddDate(0, 0, 1),
+	})
+
+	rep := detectAnomalies(rows, baselineFrom, reviewFrom, reviewTo, anomalyDefaultThreshold)
+	if len(rep.Outliers) != 0 || len(rep.Spikes) != 0 {
+		t.Fatalf("expected low spending not to be flagged, got %+v / %+v", rep.Outliers, rep.Spikes)
+	}
+}
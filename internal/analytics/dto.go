@@ -32,10 +32,94 @@ type TimeseriesPoint struct {
 }
 
 type TimeseriesResponse struct {
-	From     string            `json:"from"`
-	To       string            `json:"to"`
-	Currency string            `json:"currency"`
-	Bucket   string            `json:"bucket"`
-	Type     string            `json:"type"`
-	Points   []TimeseriesPoint `json:"points"`
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Currency  string            `json:"currency"`
+	Bucket    string            `json:"bucket"`
+	WeekStart string            `json:"week_start,omitempty"`
+	Type      string            `json:"type"`
+	Points    []TimeseriesPoint `json:"points"`
+}
+
+type RecurringItem struct {
+	Name             string  `json:"name"`
+	CategoryID       *string `json:"category_id"`
+	Cadence          string  `json:"cadence"`
+	Occurrences      int     `json:"occurrences"`
+	AverageAmount    int64   `json:"average_amount"`
+	LastAmount       int64   `json:"last_amount"`
+	PreviousAmount   int64   `json:"previous_amount"`
+	FirstDate        string  `json:"first_date"`
+	LastDate         string  `json:"last_date"`
+	NextExpectedDate string  `json:"next_expected_date"`
+	AnnualizedCost   int64   `json:"annualized_cost"`
+	PriceIncreased   bool    `json:"price_increased"`
+	Active           bool    `json:"active"`
+}
+
+type RecurringResponse struct {
+	From                string          `json:"from"`
+	To                  string          `json:"to"`
+	Currency            string          `json:"currency"`
+	TotalAnnualizedCost int64           `json:"total_annualized_cost"`
+	Items               []RecurringItem `json:"items"`
+}
+
+type AmountOutlierItem struct {
+	TransactionID string  `json:"transaction_id"`
+	CategoryID    *string `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	Note          string  `json:"note,omitempty"`
+	Amount        int64   `json:"amount"`
+	OccurredAt    string  `json:"occurred_at"`
+	Median        int64   `json:"median"`
+	Score         float64 `json:"score"`
+}
+
+type NewMerchantItem struct {
+	Name          string  `json:"name"`
+	CategoryID    *string `json:"category_id"`
+	TransactionID string  `json:"transaction_id"`
+	FirstSeen     string  `json:"first_seen"`
+	Count         int     `json:"count"`
+	Total         int64   `json:"total"`
+}
+
+type CategorySpikeItem struct {
+	CategoryID     *string `json:"category_id"`
+	CategoryName   string  `json:"category_name"`
+	WeekStart      string  `json:"week_start"`
+	Total          int64   `json:"total"`
+	BaselineMedian int64   `json:"baseline_median"`
+	Score          float64 `json:"score"`
+}
+
+type AnomaliesResponse struct {
+	From           string              `json:"from"`
+	To             string              `json:"to"`
+	Currency       string              `json:"currency"`
+	WindowDays     int                 `json:"window_days"`
+	Threshold      float64             `json:"threshold"`
+	AmountOutliers []AmountOutlierItem `json:"amount_outliers"`
+	NewMerchants   []NewMerchantItem   `json:"new_merchants"`
+	CategorySpikes []CategorySpikeItem `json:"category_spikes"`
+}
+
+type NetWorthPoint struct {
+	Period      string `json:"period"`
+	PeriodEnd   string `json:"period_end"`
+	Cash        int64  `json:"cash"`
+	Assets      int64  `json:"assets"`
+	Liabilities int64  `json:"liabilities"`
+	NetWorth    int64  `json:"net_worth"`
+}
+
+type NetWorthResponse struct {
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	Currency     string          `json:"currency"`
+	Bucket       string          `json:"bucket"`
+	WeekStart    string          `json:"week_start,omitempty"`
+	MissingRates []string        `json:"missing_rates"`
+	Points       []NetWorthPoint `json:"points"`
 }
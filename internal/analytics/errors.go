@@ -5,7 +5,10 @@ import "errors"
 var (
 	ErrInvalidDateRange = errors.New("invalid date range")
 	ErrInvalidBucket    = errors.New("invalid bucket")
+	ErrInvalidWeekStart = errors.New("invalid week start")
 	ErrInvalidType      = errors.New("invalid type")
 	ErrInvalidCurrency  = errors.New("invalid currency")
 	ErrInvalidTop       = errors.New("invalid top")
+	ErrInvalidWindow    = errors.New("invalid window")
+	ErrInvalidThreshold = errors.New("invalid threshold")
 )
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/skelbigo/FinanceTracker/internal/httpx"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
 	"github.com/skelbigo/FinanceTracker/internal/workspaces"
 )
 
@@ -14,20 +15,24 @@ type Handler struct {
 	svc    *Service
 	authMW gin.HandlerFunc
 	wsRepo workspaces.RoleProvider
+	views  transactions.ViewResolver
 }
 
-func NewHandler(svc *Service, authMW gin.HandlerFunc, wsRepo workspaces.RoleProvider) *Handler {
-	return &Handler{svc: svc, authMW: authMW, wsRepo: wsRepo}
+func NewHandler(svc *Service, authMW gin.HandlerFunc, wsRepo workspaces.RoleProvider, views transactions.ViewResolver) *Handler {
+	return &Handler{svc: svc, authMW: authMW, wsRepo: wsRepo, views: views}
 }
 
 func (h *Handler) RegisterRoutes(r gin.IRouter) {
 	g := r.Group("/workspaces/:id/analytics")
 	g.Use(h.authMW)
-	g.Use(workspaces.RequireWorkspaceRole(h.wsRepo, workspaces.RoleViewer))
+	g.Use(workspaces.RequirePermission(h.wsRepo, workspaces.PermTxRead))
 
 	g.GET("/summary", h.summary)
 	g.GET("/by-category", h.byCategory)
 	g.GET("/timeseries", h.timeseries)
+	g.GET("/recurring", h.recurring)
+	g.GET("/anomalies", h.anomalies)
+	g.GET("/net-worth", h.netWorth)
 }
 
 func (h *Handler) summary(c *gin.Context) {
@@ -35,12 +40,16 @@ func (h *Handler) summary(c *gin.Context) {
 	if !ok {
 		return
 	}
+	svc, ok := h.service(c)
+	if !ok {
+		return
+	}
 
 	from := c.Query("from")
 	to := c.Query("to")
 	currency := c.Query("currency")
 
-	resp, err := h.svc.Summary(c.Request.Context(), workspaceID, from, to, currency)
+	resp, err := svc.Summary(c.Request.Context(), workspaceID, workspaces.GetLocation(c), from, to, currency)
 	if err != nil {
 		writeErr(c, err)
 		return
@@ -53,6 +62,10 @@ func (h *Handler) byCategory(c *gin.Context) {
 	if !ok {
 		return
 	}
+	svc, ok := h.service(c)
+	if !ok {
+		return
+	}
 
 	from := c.Query("from")
 	to := c.Query("to")
@@ -69,7 +82,7 @@ func (h *Handler) byCategory(c *gin.Context) {
 		top = v
 	}
 
-	resp, err := h.svc.ByCategory(c.Request.Context(), workspaceID, from, to, currency, typ, top)
+	resp, err := svc.ByCategory(c.Request.Context(), workspaceID, workspaces.GetLocation(c), from, to, currency, typ, top)
 	if err != nil {
 		writeErr(c, err)
 		return
@@ -82,14 +95,41 @@ func (h *Handler) timeseries(c *gin.Context) {
 	if !ok {
 		return
 	}
+	svc, ok := h.service(c)
+	if !ok {
+		return
+	}
 
 	from := c.Query("from")
 	to := c.Query("to")
 	currency := c.Query("currency")
 	bucket := c.Query("bucket")
+	weekStart := c.Query("week_start")
 	typ := c.Query("type")
 
-	resp, err := h.svc.Timeseries(c.Request.Context(), workspaceID, from, to, currency, bucket, typ)
+	resp, err := svc.Timeseries(c.Request.Context(), workspaceID, workspaces.GetLocation(c), from, to, currency, bucket, weekStart, typ)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h *Handler) recurring(c *gin.Context) {
+	workspaceID, ok := mustWorkspaceUUID(c)
+	if !ok {
+		return
+	}
+	svc, ok := h.service(c)
+	if !ok {
+		return
+	}
+
+	from := c.Query("from")
+	to := c.Query("to")
+	currency := c.Query("currency")
+
+	resp, err := svc.Recurring(c.Request.Context(), workspaceID, workspaces.GetLocation(c), from, to, currency)
 	if err != nil {
 		writeErr(c, err)
 		return
@@ -97,6 +137,85 @@ func (h *Handler) timeseries(c *gin.Context) {
 	c.JSON(200, resp)
 }
 
+func (h *Handler) anomalies(c *gin.Context) {
+	workspaceID, ok := mustWorkspaceUUID(c)
+	if !ok {
+		return
+	}
+	svc, ok := h.service(c)
+	if !ok {
+		return
+	}
+
+	from := c.Query("from")
+	to := c.Query("to")
+	currency := c.Query("currency")
+
+	windowDays := 0
+	if v := c.Query("window_days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeErr(c, ErrInvalidWindow)
+			return
+		}
+		windowDays = n
+	}
+
+	threshold := 0.0
+	if v := c.Query("threshold"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			writeErr(c, ErrInvalidThreshold)
+			return
+		}
+		threshold = f
+	}
+
+	resp, err := svc.Anomalies(c.Request.Context(), workspaceID, workspaces.GetLocation(c), from, to, currency, windowDays, threshold)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h *Handler) netWorth(c *gin.Context) {
+	workspaceID, ok := mustWorkspaceUUID(c)
+	if !ok {
+		return
+	}
+
+	from := c.Query("from")
+	to := c.Query("to")
+	bucket := c.Query("bucket")
+	weekStart := c.Query("week_start")
+
+	resp, err := h.svc.NetWorth(c.Request.Context(), workspaceID, workspaces.GetLocation(c), from, to, bucket, weekStart)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+// service returns the analytics service, scoped to the saved view named by
+// the view_id query parameter when there is one.
+func (h *Handler) service(c *gin.Context) (*Service, bool) {
+	view, ok := transactions.ResolveView(c, h.views)
+	if !ok {
+		return nil, false
+	}
+	if view == nil {
+		return h.svc, true
+	}
+	scope, ferr := transactions.ParseListFilter(func(k string) string { return view[k] }, workspaces.GetLocation(c))
+	if ferr != nil {
+		httpx.Unprocessable(c, "saved view has an invalid filter", map[string]string{ferr.Field: ferr.Hint})
+		return nil, false
+	}
+	return h.svc.Scoped(scope), true
+}
+
 func mustWorkspaceUUID(c *gin.Context) (uuid.UUID, bool) {
 	v, ok := c.Get(workspaces.CtxWorkspaceIDKey)
 	if !ok {
@@ -121,9 +240,15 @@ func writeErr(c *gin.Context, err error) {
 	case errors.Is(err, ErrInvalidType):
 		httpx.BadRequest(c, "invalid type", map[string]string{"type": "income|expense"})
 	case errors.Is(err, ErrInvalidBucket):
-		httpx.BadRequest(c, "invalid bucket", map[string]string{"bucket": "day|week|month"})
+		httpx.BadRequest(c, "invalid bucket", map[string]string{"bucket": "day|week|month|quarter|year"})
+	case errors.Is(err, ErrInvalidWeekStart):
+		httpx.BadRequest(c, "invalid week start", map[string]string{"week_start": "optional weekday, e.g. monday|sunday|saturday"})
 	case errors.Is(err, ErrInvalidTop):
 		httpx.BadRequest(c, "invalid top", map[string]string{"top": "optional int, 1..100"})
+	case errors.Is(err, ErrInvalidWindow):
+		httpx.BadRequest(c, "invalid window", map[string]string{"window_days": "optional int, 28..730"})
+	case errors.Is(err, ErrInvalidThreshold):
+		httpx.BadRequest(c, "invalid threshold", map[string]string{"threshold": "optional number, 1..10"})
 	default:
 		httpx.Internal(c)
 	}
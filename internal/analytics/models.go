@@ -16,11 +16,15 @@ const (
 type Bucket string
 
 const (
-	BucketDay   Bucket = "day"
-	BucketWeek  Bucket = "week"
-	BucketMonth Bucket = "month"
+	BucketDay     Bucket = "day"
+	BucketWeek    Bucket = "week"
+	BucketMonth   Bucket = "month"
+	BucketQuarter Bucket = "quarter"
+	BucketYear    Bucket = "year"
 )
 
+const DefaultWeekStart = time.Monday
+
 type Summary struct {
 	IncomeTotal  int64
 	ExpenseTotal int64
@@ -38,3 +42,32 @@ type TimeseriesRow struct {
 	PeriodStart time.Time
 	Total       int64
 }
+
+type ExpenseRow struct {
+	ID           uuid.UUID
+	CategoryID   *uuid.UUID
+	CategoryName string
+	Note         string
+	AmountMinor  int64
+	OccurredAt   time.Time
+}
+
+type CashFlowRow struct {
+	Day      string
+	Currency string
+	Net      int64
+}
+
+type ValuationRow struct {
+	AccountID   uuid.UUID
+	Liability   bool
+	Currency    string
+	ValuedOn    string
+	AmountMinor int64
+}
+
+type RateRow struct {
+	Currency string
+	RateDate string
+	Rate     float64
+}
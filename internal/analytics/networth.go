@@ -0,0 +1,88 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type netWorthPoint struct {
+	Cash        int64
+	Assets      int64
+	Liabilities int64
+}
+
+// buildNetWorth values the workspace at each inclusive end date (YYYY-MM-DD,
+// ascending). Cash is the running transaction balance; manual accounts use
+// their latest valuation on or before the end date. Everything is converted
+// into base with the latest known rate. Amounts without a rate yet are left
+// out of that point; currencies with no rate anywhere in the range are
+// returned in missing.
+func buildNetWorth(base string, ends []string, flows []CashFlowRow, vals []ValuationRow, rates []RateRow) ([]netWorthPoint, []string) {
+	cash := map[string]int64{}
+	latestVal := map[uuid.UUID]ValuationRow{}
+	latestRate := map[string]float64{}
+	missing := map[string]struct{}{}
+
+	convert := func(amount int64, currency string) (float64, bool) {
+		if currency == base {
+			return float64(amount), true
+		}
+		rate, ok := latestRate[currency]
+		if !ok {
+			if amount != 0 {
+				missing[currency] = struct{}{}
+			}
+			return 0, false
+		}
+		return float64(amount) * rate, true
+	}
+
+	var fi, vi, ri int
+	out := make([]netWorthPoint, 0, len(ends))
+	for _, end := range ends {
+		for ; fi < len(flows) && flows[fi].Day <= end; fi++ {
+			cash[flows[fi].Currency] += flows[fi].Net
+		}
+		for ; vi < len(vals) && vals[vi].ValuedOn <= end; vi++ {
+			latestVal[vals[vi].AccountID] = vals[vi]
+		}
+		for ; ri < len(rates) && rates[ri].RateDate <= end; ri++ {
+			latestRate[rates[ri].Currency] = rates[ri].Rate
+		}
+
+		var c, a, l float64
+		for cur, amount := range cash {
+			if v, ok := convert(amount, cur); ok {
+				c += v
+			}
+		}
+		for _, v := range latestVal {
+			conv, ok := convert(v.AmountMinor, v.Currency)
+			if !ok {
+				continue
+			}
+			if v.Liability {
+				l += conv
+			} else {
+				a += conv
+			}
+		}
+
+		out = append(out, netWorthPoint{
+			Cash:        int64(math.Round(c)),
+			Assets:      int64(math.Round(a)),
+			Liabilities: int64(math.Round(l)),
+		})
+	}
+
+	codes := make([]string, 0, len(missing))
+	for cur := range missing {
+		if _, ok := latestRate[cur]; !ok {
+			codes = append(codes, cur)
+		}
+	}
+	sort.Strings(codes)
+	return out, codes
+}
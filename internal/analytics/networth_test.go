@@ -0,0 +1,57 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildNetWorth(t *testing.T) {
+	house := uuid.New()
+	loan := uuid.New()
+
+	flows := []CashFlowRow{
+		{Day: "2024-12-20", Currency: "UAH", Net: 10000},
+		{Day: "2025-01-10", Currency: "USD", Net: 100},
+		{Day: "2025-02-03", Currency: "UAH", Net: -2500},
+		{Day: "2025-02-04", Currency: "EUR", Net: 50},
+	}
+	vals := []ValuationRow{
+		{AccountID: house, Currency: "UAH", ValuedOn: "2025-01-01", AmountMinor: 500000},
+		{AccountID: loan, Liability: true, Currency: "UAH", ValuedOn: "2025-01-15", AmountMinor: 200000},
+		{AccountID: house, Currency: "UAH", ValuedOn: "2025-02-01", AmountMinor: 510000},
+	}
+	rates := []RateRow{
+		{Currency: "USD", RateDate: "2025-01-31", Rate: 40},
+	}
+
+	got, missing := buildNetWorth("UAH", []string{"2024-12-31", "2025-01-31", "2025-02-28"}, flows, vals, rates)
+
+	want := []netWorthPoint{
+		{Cash: 10000, Assets: 0, Liabilities: 0},
+		{Cash: 14000, Assets: 500000, Liabilities: 200000},
+		{Cash: 11500, Assets: 510000, Liabilities: 200000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("points = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(missing, []string{"EUR"}) {
+		t.Fatalf("missing = %v, want [EUR]", missing)
+	}
+}
+
+func TestBuildNetWorth_RateLaterInRange(t *testing.T) {
+	flows := []CashFlowRow{{Day: "2025-01-10", Currency: "USD", Net: 100}}
+	rates := []RateRow{{Currency: "USD", RateDate: "2025-01-20", Rate: 40}}
+
+	got, missing := buildNetWorth("UAH", []string{"2025-01-15", "2025-01-31"}, flows, nil, rates)
+
+	want := []netWorthPoint{{Cash: 0}, {Cash: 4000}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("points = %+v, want %+v", got, want)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("missing = %v, want none: USD has a rate later in the range", missing)
+	}
+}
@@ -3,20 +3,24 @@ package analytics
 import (
 	"strings"
 	"time"
+
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
 )
 
 const dateLayout = "2006-01-02"
 
-func parseDateRange(fromStr, toStr string) (time.Time, time.Time, error) {
+// parseDateRange interprets YYYY-MM-DD bounds as calendar days in loc and
+// returns [from, to+1day) as instants.
+func parseDateRange(fromStr, toStr string, loc *time.Location) (time.Time, time.Time, error) {
 	if fromStr == "" || toStr == "" {
 		return time.Time{}, time.Time{}, ErrInvalidDateRange
 	}
 
-	from, err := time.ParseInLocation(dateLayout, fromStr, time.UTC)
+	from, err := time.ParseInLocation(dateLayout, fromStr, loc)
 	if err != nil {
 		return time.Time{}, time.Time{}, ErrInvalidDateRange
 	}
-	to, err := time.ParseInLocation(dateLayout, toStr, time.UTC)
+	to, err := time.ParseInLocation(dateLayout, toStr, loc)
 	if err != nil {
 		return time.Time{}, time.Time{}, ErrInvalidDateRange
 	}
@@ -28,6 +32,32 @@ func parseDateRange(fromStr, toStr string) (time.Time, time.Time, error) {
 	return from, toExclusive, nil
 }
 
+func parseOptionalDateRange(fromStr, toStr string, now time.Time, defaultDays int, loc *time.Location) (time.Time, time.Time, error) {
+	if fromStr != "" && toStr != "" {
+		return parseDateRange(fromStr, toStr, loc)
+	}
+
+	to := tzx.StartOfDay(now, loc)
+	if toStr != "" {
+		t, err := time.ParseInLocation(dateLayout, toStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDateRange
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -defaultDays)
+	if fromStr != "" {
+		f, err := time.ParseInLocation(dateLayout, fromStr, loc)
+		if err != nil || to.Before(f) {
+			return time.Time{}, time.Time{}, ErrInvalidDateRange
+		}
+		from = f
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
+
 func parseType(s string) (TxType, error) {
 	switch s {
 	case string(TypeIncome):
@@ -47,11 +77,35 @@ func parseBucket(s string) (Bucket, error) {
 		return BucketWeek, nil
 	case string(BucketMonth):
 		return BucketMonth, nil
+	case string(BucketQuarter):
+		return BucketQuarter, nil
+	case string(BucketYear):
+		return BucketYear, nil
 	default:
 		return "", ErrInvalidBucket
 	}
 }
 
+func parseWeekStart(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return DefaultWeekStart, nil
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, ErrInvalidWeekStart
+}
+
+// weekShiftDays is how many days to add before Postgres' Monday-based
+// date_trunc('week') (and subtract after) so weeks start on weekStart.
+func weekShiftDays(weekStart time.Weekday) int {
+	return (8 - int(weekStart)) % 7
+}
+
 func parseCurrency(s string) (string, error) {
 	s = strings.ToUpper(strings.TrimSpace(s))
 	if len(s) != 3 {
@@ -60,22 +114,30 @@ func parseCurrency(s string) (string, error) {
 	return s, nil
 }
 
+// formatDate prints the calendar day of t in its own location; callers convert
+// instants into the workspace location first.
 func formatDate(t time.Time) string {
-	return t.In(time.UTC).Format(dateLayout)
+	return t.Format(dateLayout)
 }
 
-func truncateToBucket(t time.Time, b Bucket) time.Time {
+// truncateToBucket maps t to the start of its bucket as a UTC-midnight date,
+// using the calendar fields of t's own location.
+func truncateToBucket(t time.Time, b Bucket, weekStart time.Weekday) time.Time {
 	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 
 	switch b {
 	case BucketDay:
 		return t
 	case BucketWeek:
-		wd := int(t.Weekday())
-		delta := (wd + 6) % 7
+		delta := (int(t.Weekday()) - int(weekStart) + 7) % 7
 		return t.AddDate(0, 0, -delta)
 	case BucketMonth:
 		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case BucketQuarter:
+		q := (int(t.Month()) - 1) / 3
+		return time.Date(t.Year(), time.Month(q*3+1), 1, 0, 0, 0, 0, time.UTC)
+	case BucketYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
 	default:
 		return t
 	}
@@ -89,6 +151,10 @@ func addBucket(t time.Time, b Bucket) time.Time {
 		return t.AddDate(0, 0, 7)
 	case BucketMonth:
 		return t.AddDate(0, 1, 0)
+	case BucketQuarter:
+		return t.AddDate(0, 3, 0)
+	case BucketYear:
+		return t.AddDate(1, 0, 0)
 	default:
 		return t
 	}
@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateRange_InLocation(t *testing.T) {
+	kyiv, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+
+	from, toExcl, err := parseDateRange("2025-03-01", "2025-03-31", kyiv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantFrom := time.Date(2025, 2, 28, 22, 0, 0, 0, time.UTC)
+	if !from.Equal(wantFrom) {
+		t.Fatalf("from = %s, want %s", from.UTC(), wantFrom)
+	}
+	// DST starts on 2025-03-30 in Kyiv, so the end bound is UTC+3.
+	wantTo := time.Date(2025, 3, 31, 21, 0, 0, 0, time.UTC)
+	if !toExcl.Equal(wantTo) {
+		t.Fatalf("to = %s, want %s", toExcl.UTC(), wantTo)
+	}
+
+	late := time.Date(2025, 3, 31, 20, 30, 0, 0, time.UTC).In(kyiv)
+	if got := formatDate(truncateToBucket(late, BucketMonth, DefaultWeekStart)); got != "2025-03-01" {
+		t.Fatalf("23:30 Kyiv on Mar 31 bucketed into %s", got)
+	}
+	if got := formatDate(late); got != "2025-03-31" {
+		t.Fatalf("formatDate = %s, want 2025-03-31", got)
+	}
+}
+
+func TestTruncateToBucket(t *testing.T) {
+	wed := time.Date(2025, 5, 14, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		bucket    Bucket
+		weekStart time.Weekday
+		want      string
+	}{
+		{BucketWeek, time.Monday, "2025-05-12"},
+		{BucketWeek, time.Sunday, "2025-05-11"},
+		{BucketWeek, time.Saturday, "2025-05-10"},
+		{BucketQuarter, time.Monday, "2025-04-01"},
+		{BucketYear, time.Monday, "2025-01-01"},
+	}
+	for _, tc := range cases {
+		if got := formatDate(truncateToBucket(wed, tc.bucket, tc.weekStart)); got != tc.want {
+			t.Fatalf("truncate(%s, %s) = %s, want %s", tc.bucket, tc.weekStart, got, tc.want)
+		}
+	}
+
+	sat := time.Date(2025, 5, 17, 0, 0, 0, 0, time.UTC)
+	if got := formatDate(truncateToBucket(sat, BucketWeek, time.Saturday)); got != "2025-05-17" {
+		t.Fatalf("saturday week should start on itself, got %s", got)
+	}
+}
+
+func TestWeekShiftMatchesGoTruncation(t *testing.T) {
+	// Mirror the SQL: date_trunc('week', d + shift) - shift, where
+	// date_trunc('week') is Monday-based.
+	for ws := time.Sunday; ws <= time.Saturday; ws++ {
+		shift := weekShiftDays(ws)
+		for i := 0; i < 14; i++ {
+			d := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i)
+			shifted := d.AddDate(0, 0, shift)
+			sql := truncateToBucket(shifted, BucketWeek, time.Monday).AddDate(0, 0, -shift)
+			if got := truncateToBucket(d, BucketWeek, ws); !got.Equal(sql) {
+				t.Fatalf("week_start=%s day=%s: go=%s sql=%s", ws, formatDate(d), formatDate(got), formatDate(sql))
+			}
+		}
+	}
+}
+
+func TestParseWeekStart(t *testing.T) {
+	for in, want := range map[string]time.Weekday{"": time.Monday, "Sunday": time.Sunday, "sat": time.Saturday} {
+		got, err := parseWeekStart(in)
+		if err != nil || got != want {
+			t.Fatalf("parseWeekStart(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseWeekStart("funday"); err == nil {
+		t.Fatalf("expected error for unknown weekday")
+	}
+}
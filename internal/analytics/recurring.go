@@ -0,0 +1,227 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+type Cadence string
+
+const (
+	CadenceWeekly  Cadence = "weekly"
+	CadenceMonthly Cadence = "monthly"
+	CadenceYearly  Cadence = "yearly"
+)
+
+const (
+	recurringDefaultLookbackDays = 730
+	recurringAmountTolerance     = 0.2
+	recurringMinRegularShare     = 0.75
+	recurringPriceIncreaseMin    = 0.01
+)
+
+type cadenceSpec struct {
+	cadence        Cadence
+	minDays        float64
+	maxDays        float64
+	minOccurrences int
+	perYear        float64
+}
+
+var cadenceSpecs = []cadenceSpec{
+	{cadence: CadenceWeekly, minDays: 6, maxDays: 8, minOccurrences: 4, perYear: 52},
+	{cadence: CadenceMonthly, minDays: 26, maxDays: 35, minOccurrences: 3, perYear: 12},
+	{cadence: CadenceYearly, minDays: 350, maxDays: 380, minOccurrences: 2, perYear: 1},
+}
+
+type recurringSeries struct {
+	Name             string
+	CategoryID       *uuid.UUID
+	Cadence          Cadence
+	Occurrences      int
+	AverageAmount    int64
+	LastAmount       int64
+	PreviousAmount   int64
+	FirstDate        time.Time
+	LastDate         time.Time
+	NextExpectedDate time.Time
+	AnnualizedCost   int64
+	PriceIncreased   bool
+	Active           bool
+}
+
+// normalizeNote reduces a free-form note to a merchant-like key: lowercase
+// letters only, so "Netflix 03/2025" and "NETFLIX.COM" collapse together.
+func normalizeNote(note string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(note) {
+		switch {
+		case unicode.IsLetter(r):
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteRune(r)
+			space = false
+		default:
+			space = true
+		}
+	}
+	key := sb.String()
+	key = strings.TrimSuffix(key, " com")
+	return strings.TrimSpace(key)
+}
+
+func detectRecurring(rows []ExpenseRow, asOf time.Time) []recurringSeries {
+	groups := map[string][]ExpenseRow{}
+	for _, r := range rows {
+		key := normalizeNote(r.Note)
+		if key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	var out []recurringSeries
+	for _, g := range groups {
+		for _, cluster := range clusterByAmount(g) {
+			if s, ok := analyzeSeries(cluster, asOf); ok {
+				out = append(out, s)
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].AnnualizedCost != out[j].AnnualizedCost {
+			return out[i].AnnualizedCost > out[j].AnnualizedCost
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func clusterByAmount(rows []ExpenseRow) [][]ExpenseRow {
+	sorted := make([]ExpenseRow, len(rows))
+	copy(sorted, rows)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].AmountMinor < sorted[j].AmountMinor })
+
+	var clusters [][]ExpenseRow
+	var cur []ExpenseRow
+	for _, r := range sorted {
+		if len(cur) > 0 {
+			prev := cur[len(cur)-1].AmountMinor
+			if float64(r.AmountMinor-prev) > float64(prev)*recurringAmountTolerance {
+				clusters = append(clusters, cur)
+				cur = nil
+			}
+		}
+		cur = append(cur, r)
+	}
+	if len(cur) > 0 {
+		clusters = append(clusters, cur)
+	}
+
+	for _, c := range clusters {
+		sort.Slice(c, func(i, j int) bool { return c[i].OccurredAt.Before(c[j].OccurredAt) })
+	}
+	return clusters
+}
+
+func analyzeSeries(rows []ExpenseRow, asOf time.Time) (recurringSeries, bool) {
+	if len(rows) < 2 {
+		return recurringSeries{}, false
+	}
+
+	intervals := make([]float64, 0, len(rows)-1)
+	for i := 1; i < len(rows); i++ {
+		d := rows[i].OccurredAt.Sub(rows[i-1].OccurredAt).Hours() / 24
+		intervals = append(intervals, d)
+	}
+	med := median(intervals)
+
+	var spec *cadenceSpec
+	for i := range cadenceSpecs {
+		if med >= cadenceSpecs[i].minDays && med <= cadenceSpecs[i].maxDays {
+			spec = &cadenceSpecs[i]
+			break
+		}
+	}
+	if spec == nil || len(rows) < spec.minOccurrences {
+		return recurringSeries{}, false
+	}
+
+	regular := 0
+	for _, d := range intervals {
+		if d >= spec.minDays && d <= spec.maxDays {
+			regular++
+		}
+	}
+	if float64(regular)/float64(len(intervals)) < recurringMinRegularShare {
+		return recurringSeries{}, false
+	}
+
+	var sum int64
+	for _, r := range rows {
+		sum += r.AmountMinor
+	}
+	avg := int64(math.Round(float64(sum) / float64(len(rows))))
+
+	first := rows[0]
+	last := rows[len(rows)-1]
+	prev := rows[len(rows)-2]
+
+	next := nextExpected(last.OccurredAt, spec.cadence)
+	grace := time.Duration(spec.maxDays*24) * time.Hour / 2
+
+	return recurringSeries{
+		Name:             displayName(last.Note),
+		CategoryID:       last.CategoryID,
+		Cadence:          spec.cadence,
+		Occurrences:      len(rows),
+		AverageAmount:    avg,
+		LastAmount:       last.AmountMinor,
+		PreviousAmount:   prev.AmountMinor,
+		FirstDate:        first.OccurredAt,
+		LastDate:         last.OccurredAt,
+		NextExpectedDate: next,
+		AnnualizedCost:   int64(math.Round(float64(last.AmountMinor) * spec.perYear)),
+		PriceIncreased:   float64(last.AmountMinor) > float64(prev.AmountMinor)*(1+recurringPriceIncreaseMin),
+		Active:           !asOf.After(next.Add(grace)),
+	}, true
+}
+
+func nextExpected(last time.Time, c Cadence) time.Time {
+	switch c {
+	case CadenceWeekly:
+		return last.AddDate(0, 0, 7)
+	case CadenceMonthly:
+		return last.AddDate(0, 1, 0)
+	case CadenceYearly:
+		return last.AddDate(1, 0, 0)
+	default:
+		return last
+	}
+}
+
+func displayName(note string) string {
+	return strings.Join(strings.Fields(note), " ")
+}
+
+func median(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	s := make([]float64, len(xs))
+	copy(s, xs)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
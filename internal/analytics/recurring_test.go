@@ -0,0 +1,91 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestNormalizeNote(t *testing.T) {
+	cases := map[string]string{
+		"Netflix 03/2025":    "netflix",
+		"NETFLIX.COM":        "netflix",
+		"  Spotify   Family": "spotify family",
+		"12345":              "",
+	}
+	for in, want := range cases {
+		if got := normalizeNote(in); got != want {
+			t.Fatalf("normalizeNote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDetectRecurring_MonthlyWithPriceIncrease(t *testing.T) {
+	rows := []ExpenseRow{
+		{Note: "Netflix", AmountMinor: 999, OccurredAt: day(2025, 1, 5)},
+		{Note: "NETFLIX.COM", AmountMinor: 999, OccurredAt: day(2025, 2, 5)},
+		{Note: "Netflix 03", AmountMinor: 999, OccurredAt: day(2025, 3, 5)},
+		{Note: "Netflix", AmountMinor: 1099, OccurredAt: day(2025, 4, 5)},
+		{Note: "Coffee", AmountMinor: 450, OccurredAt: day(2025, 2, 11)},
+		{Note: "Coffee", AmountMinor: 450, OccurredAt: day(2025, 3, 29)},
+	}
+
+	got := detectRecurring(rows, day(2025, 4, 20))
+	if len(got) != 1 {
+		t.Fatalf("expected 1 series, got %d: %+v", len(got), got)
+	}
+
+	s := got[0]
+	if s.Cadence != CadenceMonthly {
+		t.Fatalf("cadence = %s, want monthly", s.Cadence)
+	}
+	if s.Occurrences != 4 {
+		t.Fatalf("occurrences = %d, want 4", s.Occurrences)
+	}
+	if !s.PriceIncreased || s.PreviousAmount != 999 || s.LastAmount != 1099 {
+		t.Fatalf("expected price increase 999 -> 1099, got %+v", s)
+	}
+	if s.AnnualizedCost != 1099*12 {
+		t.Fatalf("annualized = %d, want %d", s.AnnualizedCost, 1099*12)
+	}
+	if !s.NextExpectedDate.Equal(day(2025, 5, 5)) {
+		t.Fatalf("next expected = %s", s.NextExpectedDate)
+	}
+	if !s.Active {
+		t.Fatalf("expected series to be active")
+	}
+}
+
+func TestDetectRecurring_SplitsByAmountAndMarksInactive(t *testing.T) {
+	var rows []ExpenseRow
+	for i := 0; i < 5; i++ {
+		rows = append(rows,
+			ExpenseRow{Note: "Gym", AmountMinor: 50000, OccurredAt: day(2024, 1, 1).AddDate(0, 0, 7*i)},
+			ExpenseRow{Note: "Gym", AmountMinor: 300000, OccurredAt: day(2024, 1, 3).AddDate(0, i, 0)},
+		)
+	}
+
+	got := detectRecurring(rows, day(2025, 1, 1))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 series, got %d: %+v", len(got), got)
+	}
+
+	cadences := map[Cadence]recurringSeries{}
+	for _, s := range got {
+		cadences[s.Cadence] = s
+	}
+	if _, ok := cadences[CadenceWeekly]; !ok {
+		t.Fatalf("expected weekly series, got %+v", got)
+	}
+	if _, ok := cadences[CadenceMonthly]; !ok {
+		t.Fatalf("expected monthly series, got %+v", got)
+	}
+	for _, s := range got {
+		if s.Active {
+			t.Fatalf("series %s should be inactive long after last charge", s.Cadence)
+		}
+	}
+}
@@ -3,37 +3,77 @@ package analytics
 import (
 	"context"
 	"github.com/jackc/pgx/v5"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
 )
 
 type Repository interface {
 	Summary(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string) (Summary, error)
 	ByCategory(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string, typ TxType, top int) ([]CategoryTotalRow, int64, error)
-	Timeseries(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string, bucket Bucket, typ TxType) ([]TimeseriesRow, error)
+	Timeseries(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string, bucket Bucket, weekStart time.Weekday, typ TxType, loc *time.Location) ([]TimeseriesRow, error)
+	Expenses(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string) ([]ExpenseRow, error)
+	DefaultCurrency(ctx context.Context, workspaceID uuid.UUID) (string, error)
+	CashFlows(ctx context.Context, workspaceID uuid.UUID, toExclusive string, loc *time.Location) ([]CashFlowRow, error)
+	Valuations(ctx context.Context, workspaceID uuid.UUID, toExclusive string) ([]ValuationRow, error)
+	ExchangeRates(ctx context.Context, workspaceID uuid.UUID, toExclusive string) ([]RateRow, error)
+	Scoped(scope transactions.ListFilter) Repository
 }
 
 type Repo struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *transactions.ListFilter
 }
 
 func NewRepo(db *pgxpool.Pool) *Repo { return &Repo{db: db} }
 
+// Scoped returns a repo whose transaction totals only count transactions
+// matching scope, e.g. a saved view. Balances for net worth are unaffected.
+func (r *Repo) Scoped(scope transactions.ListFilter) Repository {
+	return &Repo{db: r.db, scope: &scope}
+}
+
+// scopeMarker marks where a query takes the scope restriction. Being a SQL
+// comment, it is harmless when the repo is unscoped.
+const scopeMarker = "  --scope\n"
+
+// scopedLines restricts q, a query over transaction_lines aliased t whose
+// workspace id is $1, to the lines of the transactions in the repo's scope.
+// Lines must also match the scope's category filter themselves, so a split
+// transaction only contributes the lines the scope is about.
+func (r *Repo) scopedLines(q string, args ...any) (string, []any) {
+	if r.scope == nil {
+		return q, args
+	}
+	sub, args := transactions.ScopeQuery(*r.scope, args)
+	cond := "  AND t.transaction_id IN (" + sub + ")\n"
+	lineCond, args := transactions.LineCategoryCondition(*r.scope, "t.category_id", args)
+	if lineCond != "" {
+		cond += "  AND " + lineCond + "\n"
+	}
+	return strings.Replace(q, scopeMarker, cond, 1), args
+}
+
 func (r *Repo) Summary(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string) (Summary, error) {
 	const q = `
 SELECT
 	COALESCE(SUM(CASE WHEN t.type = 'income'  THEN t.amount_minor ELSE 0 END), 0) AS income_total,
 	COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount_minor ELSE 0 END), 0) AS expense_total
-FROM transactions t
+FROM transaction_lines t
 WHERE t.workspace_id = $1
+  AND t.deleted_at IS NULL
   AND t.currency     = $2
   AND t.occurred_at >= $3
-  AND t.occurred_at <  $4;
+  AND t.occurred_at <  $4
+  --scope
 `
+	query, args := r.scopedLines(q, workspaceID, currency, fromInclusive, toExclusive)
 	var income, expense int64
-	if err := r.db.QueryRow(ctx, q, workspaceID, currency, fromInclusive, toExclusive).Scan(&income, &expense); err != nil {
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&income, &expense); err != nil {
 		return Summary{}, err
 	}
 	return Summary{
@@ -43,18 +83,23 @@ WHERE t.workspace_id = $1
 	}, nil
 }
 
+// ByCategory totals by category, counting split transactions under each of
+// their line items' categories.
 func (r *Repo) ByCategory(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string, typ TxType, top int) ([]CategoryTotalRow, int64, error) {
 	const totalQ = `
 SELECT COALESCE(SUM(t.amount_minor), 0) AS total
-FROM transactions t
+FROM transaction_lines t
 WHERE t.workspace_id = $1
+  AND t.deleted_at IS NULL
   AND t.currency     = $2
   AND t.occurred_at >= $3
   AND t.occurred_at <  $4
-  AND t.type         = $5;
+  AND t.type         = $5
+  --scope
 `
+	query, args := r.scopedLines(totalQ, workspaceID, currency, fromInclusive, toExclusive, string(typ))
 	var grandTotal int64
-	if err := r.db.QueryRow(ctx, totalQ, workspaceID, currency, fromInclusive, toExclusive, string(typ)).Scan(&grandTotal); err != nil {
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&grandTotal); err != nil {
 		return nil, 0, err
 	}
 
@@ -63,17 +108,19 @@ SELECT
     t.category_id,
     COALESCE(c.name, 'Uncategorized') AS name,
     COALESCE(SUM(t.amount_minor), 0) AS total,
-    COUNT(*) AS cnt
-FROM transactions t
+    COUNT(DISTINCT t.transaction_id) AS cnt
+FROM transaction_lines t
 LEFT JOIN categories c
   ON c.id = t.category_id AND c.workspace_id = t.workspace_id
 WHERE t.workspace_id = $1
+  AND t.deleted_at IS NULL
   AND t.currency     = $2
   AND t.occurred_at >= $3
   AND t.occurred_at <  $4
   AND t.type         = $5
+  --scope
 GROUP BY t.category_id, name
-ORDER BY total DESC, name ASC;
+ORDER BY total DESC, name ASC
 `
 
 	const qWithLimit = `
@@ -81,18 +128,20 @@ SELECT
     t.category_id,
     COALESCE(c.name, 'Uncategorized') AS name,
     COALESCE(SUM(t.amount_minor), 0) AS total,
-    COUNT(*) AS cnt
-FROM transactions t
+    COUNT(DISTINCT t.transaction_id) AS cnt
+FROM transaction_lines t
 LEFT JOIN categories c
   ON c.id = t.category_id AND c.workspace_id = t.workspace_id
 WHERE t.workspace_id = $1
+  AND t.deleted_at IS NULL
   AND t.currency     = $2
   AND t.occurred_at >= $3
   AND t.occurred_at <  $4
   AND t.type         = $5
+  --scope
 GROUP BY t.category_id, name
 ORDER BY total DESC, name ASC
-LIMIT $6;
+LIMIT $6
 `
 
 	var (
@@ -101,10 +150,11 @@ LIMIT $6;
 	)
 
 	if top > 0 {
-		rows, err = r.db.Query(ctx, qWithLimit, workspaceID, currency, fromInclusive, toExclusive, string(typ), top)
+		query, args = r.scopedLines(qWithLimit, workspaceID, currency, fromInclusive, toExclusive, string(typ), top)
 	} else {
-		rows, err = r.db.Query(ctx, qNoLimit, workspaceID, currency, fromInclusive, toExclusive, string(typ))
+		query, args = r.scopedLines(qNoLimit, workspaceID, currency, fromInclusive, toExclusive, string(typ))
 	}
+	rows, err = r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -127,21 +177,30 @@ LIMIT $6;
 	return out, grandTotal, nil
 }
 
-func (r *Repo) Timeseries(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string, bucket Bucket, typ TxType) ([]TimeseriesRow, error) {
+func (r *Repo) Timeseries(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string, bucket Bucket, weekStart time.Weekday, typ TxType, loc *time.Location) ([]TimeseriesRow, error) {
 	const q = `
 SELECT
-	date_trunc($5, t.occurred_at)::date AS period_start,
+	(date_trunc($5, (t.occurred_at AT TIME ZONE $7) + make_interval(days => $8))
+		- make_interval(days => $8))::date AS period_start,
 	COALESCE(SUM(t.amount_minor), 0) AS total
-FROM transactions t
+FROM transaction_lines t
 WHERE t.workspace_id = $1
+  AND t.deleted_at IS NULL
   AND t.currency     = $2
   AND t.occurred_at >= $3
   AND t.occurred_at <  $4
   AND t.type         = $6
+  --scope
 GROUP BY period_start
-ORDER BY period_start ASC;
+ORDER BY period_start ASC
 `
-	rows, err := r.db.Query(ctx, q, workspaceID, currency, fromInclusive, toExclusive, string(bucket), string(typ))
+	shift := 0
+	if bucket == BucketWeek {
+		shift = weekShiftDays(weekStart)
+	}
+
+	query, args := r.scopedLines(q, workspaceID, currency, fromInclusive, toExclusive, string(bucket), string(typ), loc.String(), shift)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -160,3 +219,145 @@ ORDER BY period_start ASC;
 	}
 	return out, nil
 }
+
+// Expenses returns one row per expense line, so split transactions show up
+// under each line's category. Lines without a note of their own carry the
+// transaction's note.
+func (r *Repo) Expenses(ctx context.Context, workspaceID uuid.UUID, fromInclusive, toExclusive time.Time, currency string) ([]ExpenseRow, error) {
+	const q = `
+SELECT t.transaction_id, t.category_id, COALESCE(c.name, 'Uncategorized') AS name,
+	COALESCE(t.note, p.note, '') AS note, t.amount_minor, t.occurred_at
+FROM transaction_lines t
+JOIN transactions p ON p.id = t.transaction_id
+LEFT JOIN categories c
+  ON c.id = t.category_id AND c.workspace_id = t.workspace_id
+WHERE t.workspace_id = $1
+  AND t.deleted_at IS NULL
+  AND t.currency     = $2
+  AND t.occurred_at >= $3
+  AND t.occurred_at <  $4
+  AND t.type         = 'expense'
+  --scope
+ORDER BY t.occurred_at ASC, t.transaction_id ASC
+`
+	query, args := r.scopedLines(q, workspaceID, currency, fromInclusive, toExclusive)
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ExpenseRow
+	for rows.Next() {
+		var row ExpenseRow
+		if err := rows.Scan(&row.ID, &row.CategoryID, &row.CategoryName, &row.Note, &row.AmountMinor, &row.OccurredAt); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repo) DefaultCurrency(ctx context.Context, workspaceID uuid.UUID) (string, error) {
+	const q = `SELECT default_currency FROM workspaces WHERE id = $1`
+	var cur string
+	if err := r.db.QueryRow(ctx, q, workspaceID).Scan(&cur); err != nil {
+		return "", err
+	}
+	return cur, nil
+}
+
+// CashFlows returns net income minus expense per local day and currency for
+// every transaction before toExclusive (YYYY-MM-DD).
+func (r *Repo) CashFlows(ctx context.Context, workspaceID uuid.UUID, toExclusive string, loc *time.Location) ([]CashFlowRow, error) {
+	const q = `
+SELECT
+	to_char((t.occurred_at AT TIME ZONE $3)::date, 'YYYY-MM-DD') AS day,
+	t.currency,
+	COALESCE(SUM(CASE WHEN t.type = 'income' THEN t.amount_minor ELSE -t.amount_minor END), 0) AS net
+FROM transactions t
+WHERE t.workspace_id = $1
+  AND t.deleted_at IS NULL
+  AND (t.occurred_at AT TIME ZONE $3)::date < $2::date
+GROUP BY day, t.currency
+ORDER BY day ASC, t.currency ASC;
+`
+	rows, err := r.db.Query(ctx, q, workspaceID, toExclusive, loc.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CashFlowRow
+	for rows.Next() {
+		var row CashFlowRow
+		if err := rows.Scan(&row.Day, &row.Currency, &row.Net); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repo) Valuations(ctx context.Context, workspaceID uuid.UUID, toExclusive string) ([]ValuationRow, error) {
+	const q = `
+SELECT a.id, a.kind = 'liability', a.currency, to_char(v.valued_on, 'YYYY-MM-DD'), v.amount_minor
+FROM manual_account_valuations v
+JOIN manual_accounts a ON a.id = v.account_id
+WHERE a.workspace_id = $1
+  AND v.valued_on < $2::date
+ORDER BY v.valued_on ASC, a.id ASC;
+`
+	rows, err := r.db.Query(ctx, q, workspaceID, toExclusive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ValuationRow
+	for rows.Next() {
+		var row ValuationRow
+		if err := rows.Scan(&row.AccountID, &row.Liability, &row.Currency, &row.ValuedOn, &row.AmountMinor); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repo) ExchangeRates(ctx context.Context, workspaceID uuid.UUID, toExclusive string) ([]RateRow, error) {
+	const q = `
+SELECT currency, to_char(rate_date, 'YYYY-MM-DD'), rate::float8
+FROM exchange_rates
+WHERE workspace_id = $1
+  AND rate_date < $2::date
+ORDER BY rate_date ASC, currency ASC;
+`
+	rows, err := r.db.Query(ctx, q, workspaceID, toExclusive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RateRow
+	for rows.Next() {
+		var row RateRow
+		if err := rows.Scan(&row.Currency, &row.RateDate, &row.Rate); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package analytics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+)
+
+func TestScopedLines(t *testing.T) {
+	const q = "SELECT 1 FROM transaction_lines t\nWHERE t.workspace_id = $1\n" + scopeMarker
+
+	unscoped := &Repo{}
+	if got, args := unscoped.scopedLines(q, "ws"); got != q || len(args) != 1 {
+		t.Errorf("unscoped query changed: %q %v", got, args)
+	}
+
+	food := "5f1c3a52-8f0e-4a4c-9a55-3b1f6f0f2a11"
+	tests := []struct {
+		name     string
+		scope    transactions.ListFilter
+		wantLine string
+		wantArgs int
+	}{
+		{name: "no category filter", scope: transactions.ListFilter{}, wantArgs: 1},
+		{name: "categories", scope: transactions.ListFilter{CategoryIDs: []string{food}}, wantLine: "AND t.category_id = ANY($3::uuid[])", wantArgs: 3},
+		{name: "uncategorized", scope: transactions.ListFilter{Uncategorized: true}, wantLine: "AND t.category_id IS NULL", wantArgs: 1},
+		{
+			name:     "categories and uncategorized",
+			scope:    transactions.ListFilter{CategoryIDs: []string{food}, Uncategorized: true},
+			wantLine: "AND (t.category_id = ANY($3::uuid[]) OR t.category_id IS NULL)",
+			wantArgs: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := (&Repo{}).Scoped(tt.scope).(*Repo)
+			got, args := r.scopedLines(q, "ws")
+			if !strings.Contains(got, "AND t.transaction_id IN (SELECT id FROM transactions") {
+				t.Errorf("missing transaction scope:\n%s", got)
+			}
+			if tt.wantLine != "" && !strings.Contains(got, tt.wantLine) {
+				t.Errorf("missing line condition %q:\n%s", tt.wantLine, got)
+			}
+			if tt.wantLine == "" && strings.Contains(got, "AND t.category_id") {
+				t.Errorf("unexpected line condition:\n%s", got)
+			}
+			if len(args) != tt.wantArgs {
+				t.Errorf("got %d args, want %d: %v", len(args), tt.wantArgs, args)
+			}
+			if len(tt.scope.CategoryIDs) > 0 && !reflect.DeepEqual(args[len(args)-1], tt.scope.CategoryIDs) {
+				t.Errorf("last arg = %v, want line categories", args[len(args)-1])
+			}
+		})
+	}
+}
@@ -2,8 +2,13 @@ package analytics
 
 import (
 	"context"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
 )
 
 type Service struct {
@@ -12,8 +17,14 @@ type Service struct {
 
 func NewService(repo Repository) *Service { return &Service{repo: repo} }
 
-func (s *Service) Summary(ctx context.Context, workspaceID uuid.UUID, fromStr, toStr, currencyStr string) (SummaryResponse, error) {
-	from, toExcl, err := parseDateRange(fromStr, toStr)
+// Scoped returns a service whose reports only count transactions matching
+// scope, on top of their own date range, currency and type.
+func (s *Service) Scoped(scope transactions.ListFilter) *Service {
+	return &Service{repo: s.repo.Scoped(scope)}
+}
+
+func (s *Service) Summary(ctx context.Context, workspaceID uuid.UUID, loc *time.Location, fromStr, toStr, currencyStr string) (SummaryResponse, error) {
+	from, toExcl, err := parseDateRange(fromStr, toStr, loc)
 	if err != nil {
 		return SummaryResponse{}, err
 	}
@@ -39,9 +50,9 @@ func (s *Service) Summary(ctx context.Context, workspaceID uuid.UUID, fromStr, t
 	}, nil
 }
 
-func (s *Service) ByCategory(ctx context.Context, workspaceID uuid.UUID, fromStr, toStr, currencyStr, typeStr string,
+func (s *Service) ByCategory(ctx context.Context, workspaceID uuid.UUID, loc *time.Location, fromStr, toStr, currencyStr, typeStr string,
 	top int) (ByCategoryResponse, error) {
-	from, toExcl, err := parseDateRange(fromStr, toStr)
+	from, toExcl, err := parseDateRange(fromStr, toStr, loc)
 	if err != nil {
 		return ByCategoryResponse{}, err
 	}
@@ -96,8 +107,8 @@ func (s *Service) ByCategory(ctx context.Context, workspaceID uuid.UUID, fromStr
 	}, nil
 }
 
-func (s *Service) Timeseries(ctx context.Context, workspaceID uuid.UUID, fromStr, toStr, currencyStr, bucketStr, typeStr string) (TimeseriesResponse, error) {
-	from, toExcl, err := parseDateRange(fromStr, toStr)
+func (s *Service) Timeseries(ctx context.Context, workspaceID uuid.UUID, loc *time.Location, fromStr, toStr, currencyStr, bucketStr, weekStartStr, typeStr string) (TimeseriesResponse, error) {
+	from, toExcl, err := parseDateRange(fromStr, toStr, loc)
 	if err != nil {
 		return TimeseriesResponse{}, err
 	}
@@ -109,24 +120,28 @@ func (s *Service) Timeseries(ctx context.Context, workspaceID uuid.UUID, fromStr
 	if err != nil {
 		return TimeseriesResponse{}, err
 	}
+	weekStart, err := parseWeekStart(weekStartStr)
+	if err != nil {
+		return TimeseriesResponse{}, err
+	}
 	typ, err := parseType(typeStr)
 	if err != nil {
 		return TimeseriesResponse{}, err
 	}
 
-	rows, err := s.repo.Timeseries(ctx, workspaceID, from, toExcl, currency, bucket, typ)
+	rows, err := s.repo.Timeseries(ctx, workspaceID, from, toExcl, currency, bucket, weekStart, typ, loc)
 	if err != nil {
 		return TimeseriesResponse{}, err
 	}
 
 	m := map[string]int64{}
 	for _, r := range rows {
-		k := formatDate(truncateToBucket(r.PeriodStart, bucket))
+		k := formatDate(truncateToBucket(r.PeriodStart, bucket, weekStart))
 		m[k] = r.Total
 	}
 
-	start := truncateToBucket(from, bucket)
-	endIncl := truncateToBucket(toExcl.AddDate(0, 0, -1), bucket)
+	start := truncateToBucket(from, bucket, weekStart)
+	endIncl := truncateToBucket(toExcl.AddDate(0, 0, -1), bucket, weekStart)
 
 	points := make([]TimeseriesPoint, 0)
 	for cur := start; !cur.After(endIncl); cur = addBucket(cur, bucket) {
@@ -139,12 +154,247 @@ func (s *Service) Timeseries(ctx context.Context, workspaceID uuid.UUID, fromStr
 
 	toIncl := toExcl.AddDate(0, 0, -1)
 
-	return TimeseriesResponse{
+	resp := TimeseriesResponse{
 		From:     formatDate(from),
 		To:       formatDate(toIncl),
 		Currency: currency,
 		Bucket:   string(bucket),
 		Type:     string(typ),
 		Points:   points,
+	}
+	if bucket == BucketWeek {
+		resp.WeekStart = strings.ToLower(weekStart.String())
+	}
+	return resp, nil
+}
+
+func (s *Service) NetWorth(ctx context.Context, workspaceID uuid.UUID, loc *time.Location, fromStr, toStr, bucketStr, weekStartStr string) (NetWorthResponse, error) {
+	from, toExcl, err := parseDateRange(fromStr, toStr, loc)
+	if err != nil {
+		return NetWorthResponse{}, err
+	}
+	bucket, err := parseBucket(bucketStr)
+	if err != nil {
+		return NetWorthResponse{}, err
+	}
+	weekStart, err := parseWeekStart(weekStartStr)
+	if err != nil {
+		return NetWorthResponse{}, err
+	}
+
+	base, err := s.repo.DefaultCurrency(ctx, workspaceID)
+	if err != nil {
+		return NetWorthResponse{}, err
+	}
+	toExclStr := formatDate(toExcl)
+	flows, err := s.repo.CashFlows(ctx, workspaceID, toExclStr, loc)
+	if err != nil {
+		return NetWorthResponse{}, err
+	}
+	vals, err := s.repo.Valuations(ctx, workspaceID, toExclStr)
+	if err != nil {
+		return NetWorthResponse{}, err
+	}
+	rates, err := s.repo.ExchangeRates(ctx, workspaceID, toExclStr)
+	if err != nil {
+		return NetWorthResponse{}, err
+	}
+
+	toIncl := toExcl.AddDate(0, 0, -1)
+	start := truncateToBucket(from, bucket, weekStart)
+	endIncl := truncateToBucket(toIncl, bucket, weekStart)
+
+	var periods, ends []string
+	for cur := start; !cur.After(endIncl); cur = addBucket(cur, bucket) {
+		end := addBucket(cur, bucket).AddDate(0, 0, -1)
+		if end.After(toIncl) {
+			end = toIncl
+		}
+		periods = append(periods, formatDate(cur))
+		ends = append(ends, formatDate(end))
+	}
+
+	values, missing := buildNetWorth(base, ends, flows, vals, rates)
+
+	points := make([]NetWorthPoint, 0, len(values))
+	for i, v := range values {
+		points = append(points, NetWorthPoint{
+			Period:      periods[i],
+			PeriodEnd:   ends[i],
+			Cash:        v.Cash,
+			Assets:      v.Assets,
+			Liabilities: v.Liabilities,
+			NetWorth:    v.Cash + v.Assets - v.Liabilities,
+		})
+	}
+
+	resp := NetWorthResponse{
+		From:         formatDate(from),
+		To:           formatDate(toIncl),
+		Currency:     base,
+		Bucket:       string(bucket),
+		MissingRates: missing,
+		Points:       points,
+	}
+	if bucket == BucketWeek {
+		resp.WeekStart = strings.ToLower(weekStart.String())
+	}
+	return resp, nil
+}
+
+func uuidPtrString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	v := id.String()
+	return &v
+}
+
+func (s *Service) Recurring(ctx context.Context, workspaceID uuid.UUID, loc *time.Location, fromStr, toStr, currencyStr string) (RecurringResponse, error) {
+	now := time.Now().In(loc)
+	from, toExcl, err := parseOptionalDateRange(fromStr, toStr, now, recurringDefaultLookbackDays, loc)
+	if err != nil {
+		return RecurringResponse{}, err
+	}
+	currency, err := parseCurrency(currencyStr)
+	if err != nil {
+		return RecurringResponse{}, err
+	}
+
+	rows, err := s.repo.Expenses(ctx, workspaceID, from, toExcl, currency)
+	if err != nil {
+		return RecurringResponse{}, err
+	}
+	inLocation(rows, loc)
+
+	asOf := toExcl
+	if now.Before(asOf) {
+		asOf = now
+	}
+
+	series := detectRecurring(rows, asOf)
+
+	var total int64
+	items := make([]RecurringItem, 0, len(series))
+	for _, sr := range series {
+		if sr.Active {
+			total += sr.AnnualizedCost
+		}
+
+		items = append(items, RecurringItem{
+			Name:             sr.Name,
+			CategoryID:       uuidPtrString(sr.CategoryID),
+			Cadence:          string(sr.Cadence),
+			Occurrences:      sr.Occurrences,
+			AverageAmount:    sr.AverageAmount,
+			LastAmount:       sr.LastAmount,
+			PreviousAmount:   sr.PreviousAmount,
+			FirstDate:        formatDate(sr.FirstDate),
+			LastDate:         formatDate(sr.LastDate),
+			NextExpectedDate: formatDate(sr.NextExpectedDate),
+			AnnualizedCost:   sr.AnnualizedCost,
+			PriceIncreased:   sr.PriceIncreased,
+			Active:           sr.Active,
+		})
+	}
+
+	return RecurringResponse{
+		From:                formatDate(from),
+		To:                  formatDate(toExcl.AddDate(0, 0, -1)),
+		Currency:            currency,
+		TotalAnnualizedCost: total,
+		Items:               items,
 	}, nil
 }
+
+func (s *Service) Anomalies(ctx context.Context, workspaceID uuid.UUID, loc *time.Location, fromStr, toStr, currencyStr string,
+	windowDays int, threshold float64) (AnomaliesResponse, error) {
+	from, toExcl, err := parseOptionalDateRange(fromStr, toStr, time.Now().In(loc), anomalyDefaultReviewDays-1, loc)
+	if err != nil {
+		return AnomaliesResponse{}, err
+	}
+	currency, err := parseCurrency(currencyStr)
+	if err != nil {
+		return AnomaliesResponse{}, err
+	}
+	if windowDays == 0 {
+		windowDays = anomalyDefaultWindowDays
+	}
+	if windowDays < anomalyMinWindowDays || windowDays > anomalyMaxWindowDays {
+		return AnomaliesResponse{}, ErrInvalidWindow
+	}
+	if threshold == 0 {
+		threshold = anomalyDefaultThreshold
+	}
+	if threshold < 1 || threshold > 10 {
+		return AnomaliesResponse{}, ErrInvalidThreshold
+	}
+
+	baselineFrom := from.AddDate(0, 0, -windowDays)
+	rows, err := s.repo.Expenses(ctx, workspaceID, baselineFrom, toExcl, currency)
+	if err != nil {
+		return AnomaliesResponse{}, err
+	}
+	inLocation(rows, loc)
+
+	rep := detectAnomalies(rows, baselineFrom, from, toExcl, threshold)
+
+	outliers := make([]AmountOutlierItem, 0, len(rep.Outliers))
+	for _, o := range rep.Outliers {
+		outliers = append(outliers, AmountOutlierItem{
+			TransactionID: o.Row.ID.String(),
+			CategoryID:    uuidPtrString(o.Row.CategoryID),
+			CategoryName:  o.Row.CategoryName,
+			Note:          o.Row.Note,
+			Amount:        o.Row.AmountMinor,
+			OccurredAt:    o.Row.OccurredAt.Format(time.RFC3339),
+			Median:        o.Median,
+			Score:         roundScore(o.Score),
+		})
+	}
+
+	merchants := make([]NewMerchantItem, 0, len(rep.Merchants))
+	for _, m := range rep.Merchants {
+		merchants = append(merchants, NewMerchantItem{
+			Name:          displayName(m.First.Note),
+			CategoryID:    uuidPtrString(m.First.CategoryID),
+			TransactionID: m.First.ID.String(),
+			FirstSeen:     formatDate(m.First.OccurredAt),
+			Count:         m.Count,
+			Total:         m.Total,
+		})
+	}
+
+	spikes := make([]CategorySpikeItem, 0, len(rep.Spikes))
+	for _, sp := range rep.Spikes {
+		spikes = append(spikes, CategorySpikeItem{
+			CategoryID:     uuidPtrString(sp.CategoryID),
+			CategoryName:   sp.CategoryName,
+			WeekStart:      formatDate(sp.WeekStart),
+			Total:          sp.Total,
+			BaselineMedian: sp.BaselineMedian,
+			Score:          roundScore(sp.Score),
+		})
+	}
+
+	return AnomaliesResponse{
+		From:           formatDate(from),
+		To:             formatDate(toExcl.AddDate(0, 0, -1)),
+		Currency:       currency,
+		WindowDays:     windowDays,
+		Threshold:      threshold,
+		AmountOutliers: outliers,
+		NewMerchants:   merchants,
+		CategorySpikes: spikes,
+	}, nil
+}
+
+func inLocation(rows []ExpenseRow, loc *time.Location) {
+	for i := range rows {
+		rows[i].OccurredAt = rows[i].OccurredAt.In(loc)
+	}
+}
+
+func roundScore(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,13 @@
+package attachments
+
+import "errors"
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrAttachmentNotFound  = errors.New("attachment not found")
+	ErrEmptyFile           = errors.New("empty file")
+	ErrFileTooLarge        = errors.New("file too large")
+	ErrUnsupportedType     = errors.New("unsupported content type")
+	ErrQuotaExceeded       = errors.New("workspace attachment quota exceeded")
+	ErrObjectNotFound      = errors.New("stored object not found")
+)
@@ -0,0 +1,224 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/httpx"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+// multipartOverhead is allowed on top of the file size for form boundaries
+// and headers.
+const multipartOverhead = 1 << 20
+
+// TransactionGetter loads the transaction attachments belong to, with its
+// type and split lines, so writes follow the same rules as editing it.
+type TransactionGetter interface {
+	GetByID(ctx context.Context, workspaceID, txID string) (transactions.Transaction, error)
+}
+
+type Handler struct {
+	svc    *Service
+	txs    TransactionGetter
+	authMW gin.HandlerFunc
+	wsRepo workspaces.RoleProvider
+}
+
+func NewHandler(svc *Service, txs TransactionGetter, authMW gin.HandlerFunc, wsRepo workspaces.RoleProvider) *Handler {
+	return &Handler{svc: svc, txs: txs, authMW: authMW, wsRepo: wsRepo}
+}
+
+func (h *Handler) RegisterRoutes(r gin.IRouter) {
+	g := r.Group("/workspaces/:id/transactions/:txId/attachments")
+	g.Use(h.authMW)
+
+	canRead := workspaces.RequirePermission(h.wsRepo, workspaces.PermTxRead)
+	canWrite := workspaces.RequirePermission(h.wsRepo, workspaces.PermTxWriteOwn)
+
+	g.GET("", canRead, h.list)
+	g.POST("", canWrite, h.upload)
+	g.GET("/:attachmentId", canRead, h.download)
+	g.DELETE("/:attachmentId", canWrite, h.delete)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	wsID, txID, ok := pathIDs(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := h.transaction(c, wsID, txID); !ok {
+		return
+	}
+	items, err := h.svc.List(c.Request.Context(), wsID, txID)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	usage, err := h.svc.Usage(c.Request.Context(), wsID)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "usage": usage})
+}
+
+func (h *Handler) upload(c *gin.Context) {
+	wsID, txID, ok := pathIDs(c)
+	if !ok || !h.authorizeWrite(c, wsID, txID) {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.svc.MaxFileBytes()+multipartOverhead)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeErr(c, ErrFileTooLarge)
+			return
+		}
+		httpx.BadRequest(c, "invalid multipart form", map[string]string{"file": "required"})
+		return
+	}
+	if fh.Size > h.svc.MaxFileBytes() {
+		writeErr(c, ErrFileTooLarge)
+		return
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		httpx.BadRequest(c, "invalid multipart form", map[string]string{"file": "required"})
+		return
+	}
+	defer f.Close()
+
+	out, err := h.svc.Upload(c.Request.Context(), c.GetString(auth.CtxUserIDKey), wsID, txID, fh.Filename, f)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"attachment": out})
+}
+
+func (h *Handler) download(c *gin.Context) {
+	wsID, txID, ok := pathIDs(c)
+	if !ok {
+		return
+	}
+	attID, ok := uuidParam(c, "attachmentId")
+	if !ok {
+		return
+	}
+
+	a, rc, err := h.svc.Open(c.Request.Context(), wsID, txID, attID)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	defer rc.Close()
+
+	c.DataFromReader(http.StatusOK, a.SizeBytes, a.ContentType, rc, ContentHeaders(a))
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	wsID, txID, ok := pathIDs(c)
+	if !ok || !h.authorizeWrite(c, wsID, txID) {
+		return
+	}
+	attID, ok := uuidParam(c, "attachmentId")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), wsID, txID, attID); err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// transaction loads a live transaction of the workspace, writing the error
+// response when that fails.
+func (h *Handler) transaction(c *gin.Context, wsID, txID string) (transactions.Transaction, bool) {
+	tx, err := h.txs.GetByID(c.Request.Context(), wsID, txID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrTransactionNotFound
+	}
+	if err != nil {
+		writeErr(c, err)
+		return transactions.Transaction{}, false
+	}
+	return tx, true
+}
+
+// authorizeWrite applies the same rules as editing the transaction itself,
+// including the role's transaction types and the categories of split lines.
+func (h *Handler) authorizeWrite(c *gin.Context, wsID, txID string) bool {
+	tx, ok := h.transaction(c, wsID, txID)
+	if !ok {
+		return false
+	}
+	access, ok := workspaces.GetAccess(c)
+	if ok && (!access.CanWriteTx(c.GetString(auth.CtxUserIDKey), tx.UserID) || !transactions.CanUseCategories(access, tx.Type, tx.CategoryID, tx.Splits)) {
+		httpx.Error(c, http.StatusForbidden, "your role does not allow changing this transaction", nil)
+		return false
+	}
+	return true
+}
+
+// ContentHeaders returns the response headers for serving a as a download.
+func ContentHeaders(a Attachment) map[string]string {
+	return map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	}
+}
+
+func pathIDs(c *gin.Context) (string, string, bool) {
+	wsID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return "", "", false
+	}
+	txID, ok := uuidParam(c, "txId")
+	return wsID, txID, ok
+}
+
+func uuidParam(c *gin.Context, name string) (string, bool) {
+	v := c.Param(name)
+	if _, err := uuid.Parse(v); err != nil {
+		httpx.BadRequest(c, "invalid "+name, map[string]string{name: "must be uuid"})
+		return "", false
+	}
+	return v, true
+}
+
+func writeErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrTransactionNotFound):
+		httpx.Error(c, http.StatusNotFound, "transaction not found", nil)
+	case errors.Is(err, ErrAttachmentNotFound), errors.Is(err, ErrObjectNotFound):
+		httpx.Error(c, http.StatusNotFound, "attachment not found", nil)
+	case errors.Is(err, ErrEmptyFile):
+		httpx.Unprocessable(c, "empty file", map[string]string{"file": "must not be empty"})
+	case errors.Is(err, ErrFileTooLarge):
+		httpx.Error(c, http.StatusRequestEntityTooLarge, "file too large", nil)
+	case errors.Is(err, ErrUnsupportedType):
+		httpx.Error(c, http.StatusUnsupportedMediaType, "unsupported file type", map[string]string{"file": "pdf, jpeg, png, webp or gif"})
+	case errors.Is(err, ErrQuotaExceeded):
+		httpx.Conflict(c, "workspace attachment quota exceeded")
+	default:
+		httpx.Internal(c)
+		log.Printf("attachments: %v", err)
+	}
+}
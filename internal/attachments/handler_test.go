@@ -0,0 +1,135 @@
+package attachments
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+const (
+	testTxID         = "11111111-1111-1111-1111-111111111111"
+	testAttachmentID = "22222222-2222-2222-2222-222222222222"
+)
+
+type stubTransactions struct {
+	tx  transactions.Transaction
+	err error
+}
+
+func (s stubTransactions) GetByID(context.Context, string, string) (transactions.Transaction, error) {
+	return s.tx, s.err
+}
+
+func attachmentRequest(method string, access workspaces.Access) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", nil)
+	c.Params = gin.Params{{Key: "txId", Value: testTxID}, {Key: "attachmentId", Value: testAttachmentID}}
+	c.Set(auth.CtxUserIDKey, "u1")
+	c.Set(workspaces.CtxWorkspaceIDKey, "ws1")
+	c.Set(workspaces.CtxAccessKey, access)
+	return c, w
+}
+
+func TestAuthorizeWrite(t *testing.T) {
+	food, rent := "food", "rent"
+	writer := []workspaces.Permission{workspaces.PermTxRead, workspaces.PermTxWrite}
+	expensesOnly := workspaces.Access{Role: workspaces.RoleMember, Permissions: writer, TxTypes: []string{"expense"}}
+	foodOnly := workspaces.Access{Role: workspaces.RoleMember, Permissions: writer, CategoryIDs: []string{food}}
+
+	income := transactions.Transaction{UserID: "u2", Type: transactions.TypeIncome, CategoryID: &food}
+	expense := transactions.Transaction{UserID: "u2", Type: transactions.TypeExpense, CategoryID: &food}
+	split := transactions.Transaction{UserID: "u2", Type: transactions.TypeExpense, CategoryID: &food,
+		Splits: []transactions.Split{{CategoryID: &food}, {CategoryID: &rent}}}
+	foodSplit := transactions.Transaction{UserID: "u2", Type: transactions.TypeExpense,
+		Splits: []transactions.Split{{CategoryID: &food}, {CategoryID: &food}}}
+
+	tests := []struct {
+		name   string
+		access workspaces.Access
+		tx     transactions.Transaction
+		want   bool
+	}{
+		{name: "type-limited role on income", access: expensesOnly, tx: income, want: false},
+		{name: "type-limited role on expense", access: expensesOnly, tx: expense, want: true},
+		{name: "category-limited role on split with another line", access: foodOnly, tx: split, want: false},
+		{name: "category-limited role on split with allowed lines", access: foodOnly, tx: foodSplit, want: true},
+		{name: "own-writes role on another member's transaction", access: workspaces.Access{Role: workspaces.RoleMember, Permissions: []workspaces.Permission{workspaces.PermTxWriteOwn}}, tx: expense, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{txs: stubTransactions{tx: tt.tx}}
+			c, w := attachmentRequest(http.MethodPost, tt.access)
+
+			if got := h.authorizeWrite(c, "ws1", testTxID); got != tt.want {
+				t.Fatalf("authorizeWrite = %v, want %v", got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+// The endpoints stop before reaching the service (nil here) when the role
+// may not change the transaction.
+func TestWritesRefusedForRestrictedRoles(t *testing.T) {
+	food, rent := "food", "rent"
+	writer := []workspaces.Permission{workspaces.PermTxRead, workspaces.PermTxWrite}
+	cases := map[string]struct {
+		access workspaces.Access
+		tx     transactions.Transaction
+	}{
+		"type": {
+			access: workspaces.Access{Role: workspaces.RoleMember, Permissions: writer, TxTypes: []string{"expense"}},
+			tx:     transactions.Transaction{UserID: "u1", Type: transactions.TypeIncome, CategoryID: &food},
+		},
+		"split category": {
+			access: workspaces.Access{Role: workspaces.RoleMember, Permissions: writer, CategoryIDs: []string{food}},
+			tx: transactions.Transaction{UserID: "u1", Type: transactions.TypeExpense, CategoryID: &food,
+				Splits: []transactions.Split{{CategoryID: &food}, {CategoryID: &rent}}},
+		},
+	}
+	endpoints := map[string]struct {
+		method string
+		call   func(h *Handler, c *gin.Context)
+	}{
+		"upload": {http.MethodPost, (*Handler).upload},
+		"delete": {http.MethodDelete, (*Handler).delete},
+	}
+	for name, tc := range cases {
+		for ep, e := range endpoints {
+			t.Run(name+"/"+ep, func(t *testing.T) {
+				h := &Handler{txs: stubTransactions{tx: tc.tx}}
+				c, w := attachmentRequest(e.method, tc.access)
+
+				e.call(h, c)
+
+				if w.Code != http.StatusForbidden {
+					t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorizeWrite_MissingTransaction(t *testing.T) {
+	h := &Handler{txs: stubTransactions{err: pgx.ErrNoRows}}
+	c, w := attachmentRequest(http.MethodPost, workspaces.NewPresetAccess(workspaces.RoleOwner))
+
+	if h.authorizeWrite(c, "ws1", testTxID) {
+		t.Fatal("authorizeWrite allowed a missing transaction")
+	}
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
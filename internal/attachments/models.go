@@ -0,0 +1,32 @@
+package attachments
+
+import "time"
+
+type Attachment struct {
+	ID            string    `json:"id"`
+	WorkspaceID   string    `json:"workspace_id"`
+	TransactionID string    `json:"transaction_id"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	SizeBytes     int64     `json:"size_bytes"`
+	UploadedBy    *string   `json:"uploaded_by"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	storageKey string
+}
+
+// Usage reports how much of its attachment quota a workspace has used.
+type Usage struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+// allowedContentTypes lists what receipts and invoices may be stored as. The
+// type is sniffed from the file contents, not taken from the client.
+var allowedContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"image/gif":       true,
+}
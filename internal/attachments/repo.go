@@ -0,0 +1,177 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepo(pool *pgxpool.Pool) *Repo { return &Repo{pool: pool} }
+
+const attachmentColumns = `a.id::text, a.workspace_id::text, a.transaction_id::text, a.file_name, a.content_type, a.size_bytes,
+	a.uploaded_by::text, a.created_at, a.storage_key`
+
+func scanAttachment(row pgx.Row) (Attachment, error) {
+	var out Attachment
+	err := row.Scan(&out.ID, &out.WorkspaceID, &out.TransactionID, &out.FileName, &out.ContentType, &out.SizeBytes,
+		&out.UploadedBy, &out.CreatedAt, &out.storageKey)
+	return out, err
+}
+
+// Create inserts a within the workspace quota and calls store before
+// committing, so a row only becomes visible once its object is stored.
+func (r *Repo) Create(ctx context.Context, a Attachment, quotaBytes int64, store func() error) (Attachment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Attachment{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Serialise uploads per workspace so concurrent requests cannot overshoot
+	// the quota.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('attachments:' || $1))`, a.WorkspaceID); err != nil {
+		return Attachment{}, err
+	}
+
+	var used int64
+	if err := tx.QueryRow(ctx, `
+SELECT COALESCE(SUM(size_bytes), 0)::bigint FROM transaction_attachments WHERE workspace_id = $1::uuid
+`, a.WorkspaceID).Scan(&used); err != nil {
+		return Attachment{}, err
+	}
+	if used+a.SizeBytes > quotaBytes {
+		return Attachment{}, ErrQuotaExceeded
+	}
+
+	out, err := scanAttachment(tx.QueryRow(ctx, `
+WITH ins AS (
+	INSERT INTO transaction_attachments (id, workspace_id, transaction_id, file_name, content_type, size_bytes, storage_key, uploaded_by)
+	SELECT $1::uuid, t.workspace_id, t.id, $4, $5, $6, $7, $8::uuid
+	FROM transactions t
+	WHERE t.workspace_id = $2::uuid AND t.id = $3::uuid AND t.deleted_at IS NULL
+	RETURNING *
+)
+SELECT `+attachmentColumns+` FROM ins a
+`, a.ID, a.WorkspaceID, a.TransactionID, a.FileName, a.ContentType, a.SizeBytes, a.storageKey, a.UploadedBy))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Attachment{}, ErrTransactionNotFound
+		}
+		return Attachment{}, err
+	}
+
+	if err := store(); err != nil {
+		return Attachment{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Attachment{}, err
+	}
+	return out, nil
+}
+
+func (r *Repo) List(ctx context.Context, workspaceID, txID string) ([]Attachment, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT `+attachmentColumns+`
+FROM transaction_attachments a
+JOIN transactions t ON t.id = a.transaction_id
+WHERE a.workspace_id = $1::uuid AND a.transaction_id = $2::uuid AND t.deleted_at IS NULL
+ORDER BY a.created_at ASC, a.id ASC
+`, workspaceID, txID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) Get(ctx context.Context, workspaceID, txID, attachmentID string) (Attachment, error) {
+	a, err := scanAttachment(r.pool.QueryRow(ctx, `
+SELECT `+attachmentColumns+`
+FROM transaction_attachments a
+JOIN transactions t ON t.id = a.transaction_id
+WHERE a.workspace_id = $1::uuid AND a.transaction_id = $2::uuid AND a.id = $3::uuid AND t.deleted_at IS NULL
+`, workspaceID, txID, attachmentID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Attachment{}, ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+// Delete removes the row and returns its storage key. The deletion trigger
+// queues the key; callers remove the object and then call Dequeue.
+func (r *Repo) Delete(ctx context.Context, workspaceID, txID, attachmentID string) (string, error) {
+	var key string
+	err := r.pool.QueryRow(ctx, `
+DELETE FROM transaction_attachments a
+USING transactions t
+WHERE t.id = a.transaction_id AND t.deleted_at IS NULL
+  AND a.workspace_id = $1::uuid AND a.transaction_id = $2::uuid AND a.id = $3::uuid
+RETURNING a.storage_key
+`, workspaceID, txID, attachmentID).Scan(&key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrAttachmentNotFound
+	}
+	return key, err
+}
+
+func (r *Repo) Usage(ctx context.Context, workspaceID string) (int64, error) {
+	var used int64
+	err := r.pool.QueryRow(ctx, `
+SELECT COALESCE(SUM(size_bytes), 0)::bigint FROM transaction_attachments WHERE workspace_id = $1::uuid
+`, workspaceID).Scan(&used)
+	return used, err
+}
+
+// PendingDeletions returns storage keys queued before queuedBefore, oldest
+// first.
+func (r *Repo) PendingDeletions(ctx context.Context, queuedBefore time.Time, limit int) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT storage_key FROM attachment_deletions
+WHERE queued_at < $1
+ORDER BY queued_at ASC
+LIMIT $2
+`, queuedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		out = append(out, key)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) Dequeue(ctx context.Context, storageKey string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM attachment_deletions WHERE storage_key = $1`, storageKey)
+	return err
+}
+
+// Requeue moves a key whose object could not be removed to the back of the
+// queue as of at, so the next purge retries it.
+func (r *Repo) Requeue(ctx context.Context, storageKey string, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE attachment_deletions SET queued_at = $2 WHERE storage_key = $1`, storageKey, at)
+	return err
+}
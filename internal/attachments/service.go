@@ -0,0 +1,184 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxFileNameLen = 255
+	purgeBatchSize = 100
+)
+
+type Service struct {
+	repo         *Repo
+	store        Storage
+	maxFileBytes int64
+	quotaBytes   int64
+}
+
+func NewService(repo *Repo, store Storage, maxFileBytes, quotaBytes int64) *Service {
+	return &Service{repo: repo, store: store, maxFileBytes: maxFileBytes, quotaBytes: quotaBytes}
+}
+
+func (s *Service) MaxFileBytes() int64 { return s.maxFileBytes }
+
+// Upload validates and stores one file for a transaction. The body is read
+// up to the size limit so the content type can be sniffed and the quota
+// checked before anything is written.
+func (s *Service) Upload(ctx context.Context, actorID, workspaceID, txID, fileName string, body io.Reader) (Attachment, error) {
+	data, contentType, err := readUpload(body, s.maxFileBytes)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	id := uuid.NewString()
+	a := Attachment{
+		ID:            id,
+		WorkspaceID:   workspaceID,
+		TransactionID: txID,
+		FileName:      sanitizeFileName(fileName),
+		ContentType:   contentType,
+		SizeBytes:     int64(len(data)),
+		storageKey:    "workspaces/" + workspaceID + "/transactions/" + txID + "/" + id,
+	}
+	if actorID != "" {
+		a.UploadedBy = &actorID
+	}
+
+	stored := false
+	out, err := s.repo.Create(ctx, a, s.quotaBytes, func() error {
+		if err := s.store.Put(ctx, a.storageKey, bytes.NewReader(data), a.SizeBytes, a.ContentType); err != nil {
+			return err
+		}
+		stored = true
+		return nil
+	})
+	if err != nil {
+		if stored {
+			_ = s.store.Delete(context.WithoutCancel(ctx), a.storageKey)
+		}
+		return Attachment{}, err
+	}
+	return out, nil
+}
+
+func (s *Service) List(ctx context.Context, workspaceID, txID string) ([]Attachment, error) {
+	return s.repo.List(ctx, workspaceID, txID)
+}
+
+// Open returns the attachment metadata and a reader for its contents; the
+// caller must close the reader.
+func (s *Service) Open(ctx context.Context, workspaceID, txID, attachmentID string) (Attachment, io.ReadCloser, error) {
+	a, err := s.repo.Get(ctx, workspaceID, txID, attachmentID)
+	if err != nil {
+		return Attachment{}, nil, err
+	}
+	rc, err := s.store.Get(ctx, a.storageKey)
+	if err != nil {
+		return Attachment{}, nil, err
+	}
+	return a, rc, nil
+}
+
+// Delete removes an attachment. If the stored object cannot be removed right
+// away it stays queued and the purge job retries later.
+func (s *Service) Delete(ctx context.Context, workspaceID, txID, attachmentID string) error {
+	key, err := s.repo.Delete(ctx, workspaceID, txID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Delete(ctx, key); err != nil {
+		return nil
+	}
+	return s.repo.Dequeue(ctx, key)
+}
+
+func (s *Service) Usage(ctx context.Context, workspaceID string) (Usage, error) {
+	used, err := s.repo.Usage(ctx, workspaceID)
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{UsedBytes: used, QuotaBytes: s.quotaBytes}, nil
+}
+
+// PurgeDeleted removes stored objects whose rows are gone, e.g. after a
+// transaction or workspace was purged. Objects the store fails to remove are
+// logged and requeued for the next run instead of blocking the rest.
+func (s *Service) PurgeDeleted(ctx context.Context) (int64, error) {
+	started := time.Now()
+	var n int64
+	for {
+		keys, err := s.repo.PendingDeletions(ctx, started, purgeBatchSize)
+		if err != nil {
+			return n, err
+		}
+
+		for _, key := range keys {
+			if err := s.store.Delete(ctx, key); err != nil {
+				log.Printf("attachments: purge %s: %v", key, err)
+				if err := s.repo.Requeue(ctx, key, started); err != nil {
+					return n, err
+				}
+				continue
+			}
+			if err := s.repo.Dequeue(ctx, key); err != nil {
+				return n, err
+			}
+			n++
+		}
+
+		if len(keys) < purgeBatchSize {
+			return n, nil
+		}
+	}
+}
+
+// readUpload reads at most maxBytes of body and returns it with its sniffed
+// content type, rejecting empty, oversized and unsupported files.
+func readUpload(body io.Reader, maxBytes int64) ([]byte, string, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) == 0 {
+		return nil, "", ErrEmptyFile
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", ErrFileTooLarge
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !allowedContentTypes[contentType] {
+		return nil, "", ErrUnsupportedType
+	}
+	return data, contentType, nil
+}
+
+func sanitizeFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "attachment"
+	}
+	if r := []rune(name); len(r) > maxFileNameLen {
+		name = string(r[:maxFileNameLen])
+	}
+	return name
+}
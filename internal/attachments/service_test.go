@@ -0,0 +1,105 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadUpload(t *testing.T) {
+	const limit = 64
+	tests := []struct {
+		name     string
+		body     string
+		wantType string
+		want     error
+	}{
+		{name: "pdf", body: "%PDF-1.4 receipt", wantType: "application/pdf"},
+		{name: "png", body: "\x89PNG\r\n\x1a\n" + "\x00\x00\x00\rIHDR", wantType: "image/png"},
+		{name: "jpeg", body: "\xff\xd8\xff\xe0\x00\x10JFIF", wantType: "image/jpeg"},
+		{name: "gif", body: "GIF89a\x01\x00\x01\x00", wantType: "image/gif"},
+		{name: "webp", body: "RIFF\x24\x00\x00\x00WEBPVP8 ", wantType: "image/webp"},
+		{name: "exactly the limit", body: "%PDF-" + strings.Repeat("x", limit-5), wantType: "application/pdf"},
+		{name: "empty", body: "", want: ErrEmptyFile},
+		{name: "one byte over the limit", body: "%PDF-" + strings.Repeat("x", limit-4), want: ErrFileTooLarge},
+		{name: "far over the limit", body: strings.Repeat("x", 10*limit), want: ErrFileTooLarge},
+		{name: "html", body: "<html><script>alert(1)</script></html>", want: ErrUnsupportedType},
+		{name: "svg", body: `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`, want: ErrUnsupportedType},
+		{name: "plain text", body: "just some notes", want: ErrUnsupportedType},
+		{name: "zip", body: "PK\x03\x04\x14\x00\x00\x00", want: ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, contentType, err := readUpload(strings.NewReader(tt.body), limit)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if contentType != tt.wantType {
+				t.Errorf("content type = %q, want %q", contentType, tt.wantType)
+			}
+			if !bytes.Equal(data, []byte(tt.body)) {
+				t.Errorf("data = %q, want the whole body", data)
+			}
+		})
+	}
+}
+
+func TestUpload_RejectsBeforeStoring(t *testing.T) {
+	// No repo or store: a rejected upload must not reach either.
+	s := &Service{maxFileBytes: 16}
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{name: "empty", body: "", want: ErrEmptyFile},
+		{name: "too large", body: "%PDF-" + strings.Repeat("x", 12), want: ErrFileTooLarge},
+		{name: "unsupported", body: "<html></html>", want: ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Upload(context.Background(), "u1", "ws", "tx", "receipt.pdf", strings.NewReader(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Upload err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "receipt.pdf", want: "receipt.pdf"},
+		{in: "  Квитанція 03.jpg  ", want: "Квитанція 03.jpg"},
+		{in: "../../x", want: "x"},
+		{in: "../../../etc/passwd", want: "passwd"},
+		{in: `..\..\windows\evil.exe`, want: "evil.exe"},
+		{in: `C:\Users\me\scan.png`, want: "scan.png"},
+		{in: "/abs/path/scan.png", want: "scan.png"},
+		{in: "..", want: "attachment"},
+		{in: "a/..", want: "attachment"},
+		{in: ".\x00.", want: "attachment"},
+		{in: "/", want: "attachment"},
+		{in: "", want: "attachment"},
+		{in: "   ", want: "attachment"},
+		{in: "dir/", want: "dir"},
+		{in: "rec\x00eipt\r\n.pdf", want: "receipt.pdf"},
+		{in: "bell\a\ttab\x7f.pdf", want: "belltab.pdf"},
+		{in: "\x1b[31mred\x1b[0m.pdf", want: "[31mred[0m.pdf"},
+		{in: `say "hi".pdf`, want: "say hi.pdf"},
+		{in: "\x00\x01\x02", want: "attachment"},
+		{in: strings.Repeat("я", maxFileNameLen+10), want: strings.Repeat("я", maxFileNameLen)},
+	}
+	for _, tt := range tests {
+		if got := sanitizeFileName(tt.in); got != tt.want {
+			t.Errorf("sanitizeFileName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func roundTrip(t *testing.T, s Storage) {
+	t.Helper()
+	ctx := context.Background()
+	key := "workspaces/ws/transactions/tx/receipt"
+	body := []byte("%PDF-1.4 receipt")
+
+	if err := s.Put(ctx, key, bytes.NewReader(body), int64(len(body)), "application/pdf"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	rc, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	_ = rc.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Fatalf("got %q, want %q", got, body)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("second delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("get after delete: got %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	roundTrip(t, NewLocalStorage(t.TempDir()))
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	s := NewLocalStorage(t.TempDir())
+	err := s.Put(context.Background(), "../outside", strings.NewReader("x"), 1, "")
+	if err == nil {
+		t.Fatal("expected error for key outside root")
+	}
+}
+
+// fakeS3 is a minimal in-memory stand-in for an S3-compatible server.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-east-1/s3/aws4_request") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/receipts/") {
+		http.Error(w, "no such bucket", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		b, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = b
+	case http.MethodGet:
+		b, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(b)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s, err := NewS3Storage(S3Config{
+		Endpoint:        srv.URL,
+		Bucket:          "receipts",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		ForcePathStyle:  true,
+	})
+	if err != nil {
+		t.Fatalf("new s3 storage: %v", err)
+	}
+	roundTrip(t, s)
+}
+
+func TestNewS3Storage_InvalidEndpoint(t *testing.T) {
+	if _, err := NewS3Storage(S3Config{Endpoint: "localhost:9000", Bucket: "b"}); err == nil {
+		t.Fatal("expected error for endpoint without scheme")
+	}
+}
@@ -0,0 +1,10 @@
+package audit
+
+import "errors"
+
+var (
+	ErrInvalidEntityType = errors.New("invalid entity type")
+	ErrInvalidAction     = errors.New("invalid action")
+	ErrInvalidActor      = errors.New("invalid actor")
+	ErrInvalidDateRange  = errors.New("invalid date range")
+)
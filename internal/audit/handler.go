@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/httpx"
+)
+
+// Handler serves the audit log. The workspace guard is injected because the
+// workspaces package records audit events and cannot be imported from here.
+type Handler struct {
+	svc      *Service
+	authMW   gin.HandlerFunc
+	guard    gin.HandlerFunc
+	location func(*gin.Context) *time.Location
+}
+
+func NewHandler(svc *Service, authMW, guard gin.HandlerFunc, location func(*gin.Context) *time.Location) *Handler {
+	return &Handler{svc: svc, authMW: authMW, guard: guard, location: location}
+}
+
+func (h *Handler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/workspaces/:id/audit", h.authMW, h.guard, h.list)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	limit, err := parseOptionalInt(c.Query("limit"))
+	if err != nil {
+		httpx.BadRequest(c, "invalid limit", map[string]string{"limit": "optional int, 1..200"})
+		return
+	}
+	offset, err := parseOptionalInt(c.Query("offset"))
+	if err != nil {
+		httpx.BadRequest(c, "invalid offset", map[string]string{"offset": "optional int, >= 0"})
+		return
+	}
+
+	loc := time.UTC
+	if h.location != nil {
+		loc = h.location(c)
+	}
+
+	res, err := h.svc.List(c.Request.Context(), c.Param("id"), Query{
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		Action:     c.Query("action"),
+		ActorID:    c.Query("actor_id"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+		Limit:      limit,
+		Offset:     offset,
+	}, loc)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func parseOptionalInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func writeErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidEntityType):
+		httpx.BadRequest(c, "invalid entity type", map[string]string{"entity_type": "transaction|category|budget|member"})
+	case errors.Is(err, ErrInvalidAction):
+		httpx.BadRequest(c, "invalid action", map[string]string{"action": "create|update|delete|restore"})
+	case errors.Is(err, ErrInvalidActor):
+		httpx.BadRequest(c, "invalid actor", map[string]string{"actor_id": "must be uuid"})
+	case errors.Is(err, ErrInvalidDateRange):
+		httpx.BadRequest(c, "invalid date range", map[string]string{"from": "YYYY-MM-DD", "to": "YYYY-MM-DD"})
+	default:
+		httpx.Internal(c)
+	}
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EntityType string
+
+const (
+	EntityTransaction EntityType = "transaction"
+	EntityCategory    EntityType = "category"
+	EntityBudget      EntityType = "budget"
+	EntityMember      EntityType = "member"
+)
+
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionRestore Action = "restore"
+)
+
+// Entry describes one change to be recorded. Before and After are marshalled
+// to JSON; nil means the entity did not exist on that side of the change.
+type Entry struct {
+	WorkspaceID string
+	ActorID     string
+	EntityType  EntityType
+	EntityID    string
+	Action      Action
+	Before      any
+	After       any
+}
+
+type Event struct {
+	ID          int64           `json:"id"`
+	WorkspaceID string          `json:"workspace_id"`
+	ActorID     *string         `json:"actor_id"`
+	ActorEmail  *string         `json:"actor_email,omitempty"`
+	EntityType  EntityType      `json:"entity_type"`
+	EntityID    string          `json:"entity_id"`
+	Action      Action          `json:"action"`
+	Before      json.RawMessage `json:"before"`
+	After       json.RawMessage `json:"after"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type Filter struct {
+	EntityType *EntityType
+	EntityID   *string
+	Action     *Action
+	ActorID    *string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Execer is satisfied by both *pgxpool.Pool and pgx.Tx, so callers can record
+// an event inside the database transaction that makes the change.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Record appends e to the audit log using db.
+func Record(ctx context.Context, db Execer, e Entry) error {
+	before, err := marshalState(e.Before)
+	if err != nil {
+		return fmt.Errorf("audit before: %w", err)
+	}
+	after, err := marshalState(e.After)
+	if err != nil {
+		return fmt.Errorf("audit after: %w", err)
+	}
+
+	var actor *string
+	if e.ActorID != "" {
+		actor = &e.ActorID
+	}
+
+	_, err = db.Exec(ctx, `
+INSERT INTO audit_events (workspace_id, actor_id, entity_type, entity_id, action, before, after)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5, $6::jsonb, $7::jsonb)
+`, e.WorkspaceID, actor, string(e.EntityType), e.EntityID, string(e.Action), before, after)
+	if err != nil {
+		return fmt.Errorf("audit record: %w", err)
+	}
+	return nil
+}
+
+func marshalState(v any) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(b)
+	return &s, nil
+}
+
+type Repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepo(pool *pgxpool.Pool) *Repo { return &Repo{pool: pool} }
+
+func (r *Repo) List(ctx context.Context, workspaceID string, f Filter) ([]Event, error) {
+	args := []any{workspaceID}
+	argN := 2
+
+	var sb strings.Builder
+	sb.WriteString(`
+SELECT e.id, e.workspace_id::text, e.actor_id::text, u.email, e.entity_type, e.entity_id, e.action,
+	e.before, e.after, e.created_at
+FROM audit_events e
+LEFT JOIN users u ON u.id = e.actor_id
+WHERE e.workspace_id = $1::uuid
+`)
+	if f.EntityType != nil {
+		sb.WriteString(fmt.Sprintf("AND e.entity_type = $%d\n", argN))
+		args = append(args, string(*f.EntityType))
+		argN++
+	}
+	if f.EntityID != nil {
+		sb.WriteString(fmt.Sprintf("AND e.entity_id = $%d\n", argN))
+		args = append(args, *f.EntityID)
+		argN++
+	}
+	if f.Action != nil {
+		sb.WriteString(fmt.Sprintf("AND e.action = $%d\n", argN))
+		args = append(args, string(*f.Action))
+		argN++
+	}
+	if f.ActorID != nil {
+		sb.WriteString(fmt.Sprintf("AND e.actor_id = $%d::uuid\n", argN))
+		args = append(args, *f.ActorID)
+		argN++
+	}
+	if f.From != nil {
+		sb.WriteString(fmt.Sprintf("AND e.created_at >= $%d\n", argN))
+		args = append(args, *f.From)
+		argN++
+	}
+	if f.To != nil {
+		sb.WriteString(fmt.Sprintf("AND e.created_at < $%d\n", argN))
+		args = append(args, *f.To)
+		argN++
+	}
+
+	sb.WriteString("ORDER BY e.created_at DESC, e.id DESC\n")
+	sb.WriteString(fmt.Sprintf("LIMIT $%d OFFSET $%d;\n", argN, argN+1))
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := r.pool.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Event{}
+	for rows.Next() {
+		var e Event
+		var entityType, action string
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.WorkspaceID, &e.ActorID, &e.ActorEmail, &entityType, &e.EntityID, &action,
+			&before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.EntityType = EntityType(entityType)
+		e.Action = Action(action)
+		if before != nil {
+			e.Before = before
+		}
+		if after != nil {
+			e.After = after
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// captureExec records the arguments of the last Exec call.
+type captureExec struct {
+	args []any
+}
+
+func (c *captureExec) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	c.args = args
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func derefString(t *testing.T, v any) *string {
+	t.Helper()
+	s, ok := v.(*string)
+	if !ok {
+		t.Fatalf("arg %T is not *string", v)
+	}
+	return s
+}
+
+func TestRecord_Payload(t *testing.T) {
+	db := &captureExec{}
+	err := Record(context.Background(), db, Entry{
+		WorkspaceID: "ws",
+		ActorID:     "actor",
+		EntityType:  EntityCategory,
+		EntityID:    "cat",
+		Action:      ActionUpdate,
+		Before:      map[string]string{"name": "Food"},
+		After: struct {
+			Name     string `json:"name"`
+			Archived bool   `json:"archived"`
+		}{Name: "Groceries", Archived: true},
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if len(db.args) != 7 {
+		t.Fatalf("got %d args, want 7", len(db.args))
+	}
+	if db.args[0] != "ws" || db.args[2] != "category" || db.args[3] != "cat" || db.args[4] != "update" {
+		t.Errorf("identity args = %v", db.args[:5])
+	}
+	if actor := derefString(t, db.args[1]); actor == nil || *actor != "actor" {
+		t.Errorf("actor = %v", actor)
+	}
+	if before := derefString(t, db.args[5]); before == nil || *before != `{"name":"Food"}` {
+		t.Errorf("before = %v", before)
+	}
+	if after := derefString(t, db.args[6]); after == nil || *after != `{"name":"Groceries","archived":true}` {
+		t.Errorf("after = %v", after)
+	}
+}
+
+func TestRecord_MissingSidesAndActor(t *testing.T) {
+	db := &captureExec{}
+	if err := Record(context.Background(), db, Entry{
+		WorkspaceID: "ws",
+		EntityType:  EntityTransaction,
+		EntityID:    "tx",
+		Action:      ActionCreate,
+		After:       map[string]int{"amount_minor": 100},
+	}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if actor := derefString(t, db.args[1]); actor != nil {
+		t.Errorf("empty actor should be NULL, got %q", *actor)
+	}
+	if before := derefString(t, db.args[5]); before != nil {
+		t.Errorf("nil before should be NULL, got %q", *before)
+	}
+	if after := derefString(t, db.args[6]); after == nil || *after != `{"amount_minor":100}` {
+		t.Errorf("after = %v", after)
+	}
+}
+
+func TestRecord_MarshalError(t *testing.T) {
+	db := &captureExec{}
+	err := Record(context.Background(), db, Entry{Before: make(chan int)})
+	if err == nil || !strings.HasPrefix(err.Error(), "audit before:") {
+		t.Fatalf("err = %v, want audit before error", err)
+	}
+	if db.args != nil {
+		t.Error("nothing should be written when a side cannot be marshalled")
+	}
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type Service struct {
+	repo *Repo
+}
+
+func NewService(repo *Repo) *Service { return &Service{repo: repo} }
+
+type ListResult struct {
+	Items   []Event `json:"items"`
+	HasNext bool    `json:"has_next"`
+	Limit   int     `json:"limit"`
+	Offset  int     `json:"offset"`
+}
+
+// Query holds raw filter values as they arrive from a request.
+type Query struct {
+	EntityType string
+	EntityID   string
+	Action     string
+	ActorID    string
+	From       string
+	To         string
+	Limit      int
+	Offset     int
+}
+
+func (s *Service) List(ctx context.Context, workspaceID string, q Query, loc *time.Location) (ListResult, error) {
+	f, err := buildFilter(q, loc)
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	pageSize := f.Limit
+	f.Limit = pageSize + 1
+
+	items, err := s.repo.List(ctx, workspaceID, f)
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	hasNext := false
+	if len(items) > pageSize {
+		hasNext = true
+		items = items[:pageSize]
+	}
+	return ListResult{Items: items, HasNext: hasNext, Limit: pageSize, Offset: f.Offset}, nil
+}
+
+func buildFilter(q Query, loc *time.Location) (Filter, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var f Filter
+
+	if v := strings.ToLower(strings.TrimSpace(q.EntityType)); v != "" {
+		et := EntityType(v)
+		switch et {
+		case EntityTransaction, EntityCategory, EntityBudget, EntityMember:
+		default:
+			return Filter{}, ErrInvalidEntityType
+		}
+		f.EntityType = &et
+	}
+	if v := strings.TrimSpace(q.EntityID); v != "" {
+		f.EntityID = &v
+	}
+	if v := strings.ToLower(strings.TrimSpace(q.Action)); v != "" {
+		a := Action(v)
+		switch a {
+		case ActionCreate, ActionUpdate, ActionDelete, ActionRestore:
+		default:
+			return Filter{}, ErrInvalidAction
+		}
+		f.Action = &a
+	}
+	if v := strings.TrimSpace(q.ActorID); v != "" {
+		if _, err := uuid.Parse(v); err != nil {
+			return Filter{}, ErrInvalidActor
+		}
+		f.ActorID = &v
+	}
+
+	if v := strings.TrimSpace(q.From); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, loc)
+		if err != nil {
+			return Filter{}, ErrInvalidDateRange
+		}
+		f.From = &t
+	}
+	if v := strings.TrimSpace(q.To); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, loc)
+		if err != nil {
+			return Filter{}, ErrInvalidDateRange
+		}
+		// "to" is inclusive of the whole day.
+		t = t.AddDate(0, 0, 1)
+		f.To = &t
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return Filter{}, ErrInvalidDateRange
+	}
+
+	f.Limit = q.Limit
+	if f.Limit <= 0 {
+		f.Limit = defaultPageSize
+	}
+	if f.Limit > maxPageSize {
+		f.Limit = maxPageSize
+	}
+	f.Offset = q.Offset
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return f, nil
+}
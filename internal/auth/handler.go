@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/skelbigo/FinanceTracker/internal/httpx"
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
 	"log"
 	"net/http"
 )
@@ -25,6 +26,7 @@ func (h *Handler) RegisterRoutes(r gin.IRouter) {
 	g.POST("/refresh", h.refresh)
 	g.POST("/logout", h.logout)
 	g.GET("/me", h.mw, h.me)
+	g.PATCH("/me", h.mw, h.updateMe)
 }
 
 func bindJSON[T any](c *gin.Context, dst *T) bool {
@@ -134,3 +136,33 @@ func (h *Handler) me(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"user": dto})
 }
+
+func (h *Handler) updateMe(c *gin.Context) {
+	v, ok := c.Get(CtxUserIDKey)
+	userID, ok := v.(string)
+	if !ok || userID == "" {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	var req UpdateMeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	dto, err := h.svc.UpdateMe(c.Request.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, tzx.ErrInvalidTimezone):
+			httpx.Unprocessable(c, "invalid timezone", map[string]string{"timezone": "IANA name like Europe/Kyiv, or empty to clear"})
+		case errors.Is(err, pgx.ErrNoRows):
+			httpx.Unauthorized(c, "invalid token")
+		default:
+			log.Printf("auth.updateMe: %v", err)
+			httpx.Internal(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": dto})
+}
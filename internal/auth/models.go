@@ -9,9 +9,10 @@ type RegisterRequest struct {
 }
 
 type UserDTO struct {
-	ID    string  `json:"id"`
-	Email string  `json:"email"`
-	Name  *string `json:"name,omitempty"`
+	ID       string  `json:"id"`
+	Email    string  `json:"email"`
+	Name     *string `json:"name,omitempty"`
+	Timezone *string `json:"timezone,omitempty"`
 }
 
 type RegisterResponse struct {
@@ -24,6 +25,7 @@ type User struct {
 	Email        string
 	PasswordHash string
 	Name         *string
+	Timezone     *string
 	CreatedAt    time.Time
 }
 
@@ -51,3 +53,7 @@ type RefreshResponse = TokenPair
 type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
+
+type UpdateMeRequest struct {
+	Timezone *string `json:"timezone"`
+}
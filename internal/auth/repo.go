@@ -23,11 +23,11 @@ func (r *Repo) CreateUser(ctx context.Context, email, passwordHash string, name
 	const q = `
 INSERT INTO users (email, password_hash, name)
 VALUES ($1, $2, $3)
-RETURNING id::text, email, password_hash, name, created_at
+RETURNING id::text, email, password_hash, name, timezone, created_at
 `
 	var u User
 	err := r.db.QueryRow(ctx, q, email, passwordHash, name).
-		Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.CreatedAt)
+		Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.Timezone, &u.CreatedAt)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -80,12 +80,12 @@ RETURNING user_id::text
 
 func (r *Repo) GetUserByEmail(ctx context.Context, email string) (User, error) {
 	const q = `
-SELECT id::text, email, password_hash, name, created_at
+SELECT id::text, email, password_hash, name, timezone, created_at
 FROM users
 WHERE email = $1
 `
 	var u User
-	err := r.db.QueryRow(ctx, q, email).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.CreatedAt)
+	err := r.db.QueryRow(ctx, q, email).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.Timezone, &u.CreatedAt)
 	if err != nil {
 		return User{}, err
 	}
@@ -126,12 +126,12 @@ RETURNING user_id::text
 
 func (r *Repo) GetUserByID(ctx context.Context, userID string) (User, error) {
 	const q = `
-SELECT id::text, email, password_hash, name, created_at
+SELECT id::text, email, password_hash, name, timezone, created_at
 FROM users
 WHERE id = $1::uuid
 `
 	var u User
-	err := r.db.QueryRow(ctx, q, userID).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.CreatedAt)
+	err := r.db.QueryRow(ctx, q, userID).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.Timezone, &u.CreatedAt)
 	if err != nil {
 		return User{}, err
 	}
@@ -154,6 +154,22 @@ WHERE id = $1::uuid
 	return nil
 }
 
+func (r *Repo) UpdateUserTimezone(ctx context.Context, userID string, timezone *string) error {
+	const q = `
+UPDATE users
+SET timezone = $2
+WHERE id = $1::uuid
+`
+	cmd, err := r.db.Exec(ctx, q, userID, timezone)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
 func (r *Repo) RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (string, bool, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
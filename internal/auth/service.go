@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/jackc/pgx/v5"
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
 	"strings"
 	"time"
 )
@@ -248,6 +249,25 @@ func (s *Service) Me(ctx context.Context, userID string) (UserDTO, error) {
 		return UserDTO{}, err
 	}
 
-	dto := UserDTO{ID: u.ID, Email: u.Email, Name: u.Name}
+	dto := UserDTO{ID: u.ID, Email: u.Email, Name: u.Name, Timezone: u.Timezone}
 	return dto, nil
 }
+
+// UpdateMe applies profile changes; an empty timezone clears the override so
+// the workspace timezone applies again.
+func (s *Service) UpdateMe(ctx context.Context, userID string, req UpdateMeRequest) (UserDTO, error) {
+	if req.Timezone != nil {
+		var tz *string
+		if strings.TrimSpace(*req.Timezone) != "" {
+			v, err := tzx.Normalize(*req.Timezone)
+			if err != nil {
+				return UserDTO{}, err
+			}
+			tz = &v
+		}
+		if err := s.repo.UpdateUserTimezone(ctx, userID, tz); err != nil {
+			return UserDTO{}, err
+		}
+	}
+	return s.Me(ctx, userID)
+}
@@ -47,3 +47,16 @@ WHERE id = $1 AND workspace_id = $2;
 	}
 	return typ, nil
 }
+
+func (r *CategoryLookupRepo) IsArchived(ctx context.Context, workspaceID, categoryID uuid.UUID) (bool, error) {
+	const q = `
+SELECT archived_at IS NOT NULL
+FROM categories
+WHERE id = $1 AND workspace_id = $2;
+`
+	var archived bool
+	if err := r.db.QueryRow(ctx, q, categoryID, workspaceID).Scan(&archived); err != nil {
+		return false, err
+	}
+	return archived, nil
+}
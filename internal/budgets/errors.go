@@ -8,4 +8,5 @@ var (
 	ErrInvalidAmount      = errors.New("invalid amount")
 	ErrCategoryNotFound   = errors.New("category not found in workspace")
 	ErrCategoryNotExpense = errors.New("category is not expense")
+	ErrCategoryArchived   = errors.New("category is archived")
 )
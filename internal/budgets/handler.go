@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/skelbigo/FinanceTracker/internal/auth"
 	"github.com/skelbigo/FinanceTracker/internal/httpx"
 	"github.com/skelbigo/FinanceTracker/internal/workspaces"
 )
@@ -24,11 +25,11 @@ func (h *Handler) RegisterRoutes(r gin.IRouter) {
 	g.Use(h.mw)
 	wsg := g.Group("/:id")
 	wsg.GET("/budgets",
-		workspaces.RequireWorkspaceRole(h.ws, workspaces.RoleViewer),
+		workspaces.RequirePermission(h.ws, workspaces.PermTxRead),
 		h.listBudgetsByMonth,
 	)
 	wsg.PUT("/budgets",
-		workspaces.RequireWorkspaceRole(h.ws, workspaces.RoleMember),
+		workspaces.RequirePermission(h.ws, workspaces.PermBudgetsWrite),
 		h.upsertBudget,
 	)
 }
@@ -45,7 +46,7 @@ func (h *Handler) upsertBudget(c *gin.Context) {
 		return
 	}
 
-	res, err := h.svc.UpsertBudget(c.Request.Context(), workspaceID, req)
+	res, err := h.svc.UpsertBudget(c.Request.Context(), c.GetString(auth.CtxUserIDKey), workspaceID, req)
 	if err != nil {
 		respondErr(c, err)
 		return
@@ -65,7 +66,7 @@ func (h *Handler) listBudgetsByMonth(c *gin.Context) {
 		return
 	}
 
-	items, err := h.svc.GetBudgetsForMonth(c.Request.Context(), workspaceID, year, month)
+	items, err := h.svc.GetBudgetsForMonth(c.Request.Context(), workspaceID, year, month, workspaces.GetLocation(c))
 	if err != nil {
 		respondErr(c, err)
 		return
@@ -92,6 +93,10 @@ func respondErr(c *gin.Context, err error) {
 		httpx.Error(c, http.StatusUnprocessableEntity, "category is not expense", nil)
 		return
 
+	case errors.Is(err, ErrCategoryArchived):
+		httpx.Error(c, http.StatusUnprocessableEntity, "category is archived", nil)
+		return
+
 	default:
 		httpx.Internal(c)
 		return
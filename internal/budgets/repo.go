@@ -2,11 +2,14 @@ package budgets
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/skelbigo/FinanceTracker/internal/audit"
 )
 
 type Repo struct {
@@ -17,7 +20,31 @@ func NewRepo(db *pgxpool.Pool) *Repo {
 	return &Repo{db: db}
 }
 
-func (r *Repo) Upsert(ctx context.Context, workspaceID uuid.UUID, req UpsertBudgetRequest) (Budget, error) {
+func (r *Repo) Upsert(ctx context.Context, actorID string, workspaceID uuid.UUID, req UpsertBudgetRequest) (Budget, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return Budget{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const selectQ = `
+SELECT id, workspace_id, category_id, year, month, amount, created_at, updated_at
+FROM budgets
+WHERE workspace_id = $1 AND category_id = $2 AND year = $3 AND month = $4
+FOR UPDATE;
+`
+	var before *Budget
+	var prev Budget
+	err = tx.QueryRow(ctx, selectQ, workspaceID, req.CategoryID, req.Year, req.Month).
+		Scan(&prev.ID, &prev.WorkspaceID, &prev.CategoryID, &prev.Year, &prev.Month, &prev.Amount, &prev.CreatedAt, &prev.UpdatedAt)
+	switch {
+	case err == nil:
+		before = &prev
+	case errors.Is(err, pgx.ErrNoRows):
+	default:
+		return Budget{}, err
+	}
+
 	const q = `
 INSERT INTO budgets (workspace_id, category_id, year, month, amount)
 VALUES ($1, $2, $3, $4, $5)
@@ -27,28 +54,48 @@ RETURNING id, workspace_id, category_id, year, month, amount, created_at, update
 `
 
 	var b Budget
-	err := r.db.QueryRow(ctx, q, workspaceID, req.CategoryID, req.Year, req.Month, req.Amount).
+	err = tx.QueryRow(ctx, q, workspaceID, req.CategoryID, req.Year, req.Month, req.Amount).
 		Scan(&b.ID, &b.WorkspaceID, &b.CategoryID, &b.Year, &b.Month, &b.Amount, &b.CreatedAt, &b.UpdatedAt)
 	if err != nil {
 		return Budget{}, err
 	}
 
+	entry := audit.Entry{
+		WorkspaceID: workspaceID.String(),
+		ActorID:     actorID,
+		EntityType:  audit.EntityBudget,
+		EntityID:    b.ID.String(),
+		Action:      audit.ActionCreate,
+		After:       b,
+	}
+	if before != nil {
+		entry.Action = audit.ActionUpdate
+		entry.Before = before
+	}
+	if err := audit.Record(ctx, tx, entry); err != nil {
+		return Budget{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Budget{}, err
+	}
 	return b, nil
 }
 
-func (r *Repo) ListWithStats(ctx context.Context, workspaceID uuid.UUID, year int, month int) ([]BudgetResponse, error) {
-	start, end := monthRangeUTC(year, month)
+func (r *Repo) ListWithStats(ctx context.Context, workspaceID uuid.UUID, year int, month int, loc *time.Location) ([]BudgetResponse, error) {
+	start, end := monthRange(year, month, loc)
 
 	const q = `
 SELECT b.id, b.workspace_id, b.category_id, b.year, b.month, b.amount, b.created_at, b.updated_at,
   COALESCE(SUM(t.amount_minor), 0)::bigint AS spent
 FROM budgets b
-LEFT JOIN transactions t
+LEFT JOIN transaction_lines t
   ON t.workspace_id = b.workspace_id
  AND t.category_id  = b.category_id
  AND t.occurred_at >= $2
  AND t.occurred_at <  $3
  AND t.type = 'expense'
+ AND t.deleted_at IS NULL
 WHERE b.workspace_id = $1
   AND b.year  = $4
   AND b.month = $5
@@ -82,10 +129,10 @@ ORDER BY b.category_id;
 	return out, nil
 }
 
-func monthRangeUTC(year int, month int) (time.Time, time.Time) {
-	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+// monthRange returns the budget month as [first day, first day of next month)
+// in the workspace location.
+func monthRange(year int, month int, loc *time.Location) (time.Time, time.Time) {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
 	end := start.AddDate(0, 1, 0)
 	return start, end
 }
-
-var _ = pgx.ErrNoRows
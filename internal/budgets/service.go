@@ -4,16 +4,18 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"time"
 )
 
 type BudgetRepo interface {
-	Upsert(ctx context.Context, workspaceID uuid.UUID, req UpsertBudgetRequest) (Budget, error)
-	ListWithStats(ctx context.Context, workspaceID uuid.UUID, year int, month int) ([]BudgetResponse, error)
+	Upsert(ctx context.Context, actorID string, workspaceID uuid.UUID, req UpsertBudgetRequest) (Budget, error)
+	ListWithStats(ctx context.Context, workspaceID uuid.UUID, year int, month int, loc *time.Location) ([]BudgetResponse, error)
 }
 
 type CategoryLookup interface {
 	ExistsInWorkspace(ctx context.Context, workspaceID, categoryID uuid.UUID) (bool, error)
 	GetType(ctx context.Context, workspaceID, categoryID uuid.UUID) (string, error) // "expense"/"income" або як у тебе
+	IsArchived(ctx context.Context, workspaceID, categoryID uuid.UUID) (bool, error)
 }
 
 type Service struct {
@@ -48,7 +50,7 @@ func validateYearMonthAmount(req UpsertBudgetRequest) error {
 	return nil
 }
 
-func (s *Service) UpsertBudget(ctx context.Context, workspaceID uuid.UUID, req UpsertBudgetRequest) (Budget, error) {
+func (s *Service) UpsertBudget(ctx context.Context, actorID string, workspaceID uuid.UUID, req UpsertBudgetRequest) (Budget, error) {
 	if err := validateYearMonthAmount(req); err != nil {
 		return Budget{}, err
 	}
@@ -61,6 +63,14 @@ func (s *Service) UpsertBudget(ctx context.Context, workspaceID uuid.UUID, req U
 		return Budget{}, ErrCategoryNotFound
 	}
 
+	archived, err := s.categories.IsArchived(ctx, workspaceID, req.CategoryID)
+	if err != nil {
+		return Budget{}, err
+	}
+	if archived {
+		return Budget{}, ErrCategoryArchived
+	}
+
 	if s.enforceExpense {
 		typ, err := s.categories.GetType(ctx, workspaceID, req.CategoryID)
 		if err != nil {
@@ -71,10 +81,10 @@ func (s *Service) UpsertBudget(ctx context.Context, workspaceID uuid.UUID, req U
 		}
 	}
 
-	return s.repo.Upsert(ctx, workspaceID, req)
+	return s.repo.Upsert(ctx, actorID, workspaceID, req)
 }
 
-func (s *Service) GetBudgetsForMonth(ctx context.Context, workspaceID uuid.UUID, year int, month int) ([]BudgetResponse, error) {
+func (s *Service) GetBudgetsForMonth(ctx context.Context, workspaceID uuid.UUID, year int, month int, loc *time.Location) ([]BudgetResponse, error) {
 	if year < minYear || year > maxYear {
 		return nil, fmt.Errorf("%w: %d (allowed %d..%d)", ErrInvalidYear, year, minYear, maxYear)
 	}
@@ -82,5 +92,5 @@ func (s *Service) GetBudgetsForMonth(ctx context.Context, workspaceID uuid.UUID,
 		return nil, fmt.Errorf("%w: %d (allowed 1..12)", ErrInvalidMonth, month)
 	}
 
-	return s.repo.ListWithStats(ctx, workspaceID, year, month)
+	return s.repo.ListWithStats(ctx, workspaceID, year, month, loc)
 }
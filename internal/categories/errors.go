@@ -3,6 +3,8 @@ package categories
 import "errors"
 
 var (
-	ErrCategoryExists = errors.New("category already exists")
-	ErrInvalidType    = errors.New("invalid category type")
+	ErrCategoryExists   = errors.New("category already exists")
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrInvalidType      = errors.New("invalid category type")
+	ErrInvalidName      = errors.New("invalid category name")
 )
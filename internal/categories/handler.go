@@ -3,6 +3,7 @@ package categories
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/skelbigo/FinanceTracker/internal/auth"
 	"github.com/skelbigo/FinanceTracker/internal/httpx"
 	"github.com/skelbigo/FinanceTracker/internal/workspaces"
 	"net/http"
@@ -24,8 +25,11 @@ func (h *Handler) RegisterRoutes(r gin.IRouter) {
 	g.Use(h.mw)
 
 	wsg := g.Group("/:id")
-	wsg.POST("/categories", workspaces.RequireWorkspaceRole(h.ws, workspaces.RoleMember), h.create)
-	wsg.GET("/categories", workspaces.RequireWorkspaceRole(h.ws, workspaces.RoleViewer), h.list)
+	wsg.POST("/categories", workspaces.RequirePermission(h.ws, workspaces.PermCategoriesWrite), h.create)
+	// Members who may only record their own transactions still need the
+	// list to pick a category.
+	wsg.GET("/categories", workspaces.RequireAnyPermission(h.ws, workspaces.PermTxRead, workspaces.PermTxWriteOwn), h.list)
+	wsg.PATCH("/categories/:categoryId", workspaces.RequirePermission(h.ws, workspaces.PermCategoriesWrite), h.update)
 }
 
 type CreateCategoryReq struct {
@@ -51,11 +55,13 @@ func (h *Handler) create(c *gin.Context) {
 		httpx.Unprocessable(c, "invalid category type", map[string]string{"type": "income|expense"})
 		return
 	}
-	cat, err := h.svc.Create(c.Request.Context(), workspaceID, req.Name, t)
+	cat, err := h.svc.Create(c.Request.Context(), c.GetString(auth.CtxUserIDKey), workspaceID, req.Name, t)
 	if err != nil {
 		switch {
 		case errors.Is(err, ErrInvalidType):
 			httpx.Unprocessable(c, "invalid category type", map[string]string{"type": "income|expense"})
+		case errors.Is(err, ErrInvalidName):
+			httpx.Unprocessable(c, "invalid category name", map[string]string{"name": "required"})
 		case errors.Is(err, ErrCategoryExists):
 			httpx.Conflict(c, "category already exists")
 		default:
@@ -74,10 +80,58 @@ func (h *Handler) list(c *gin.Context) {
 		return
 	}
 
-	items, err := h.svc.List(c.Request.Context(), workspaceID)
+	var (
+		items []Category
+		err   error
+	)
+	if c.Query("include_archived") == "false" {
+		items, err = h.svc.ListActive(c.Request.Context(), workspaceID)
+	} else {
+		items, err = h.svc.List(c.Request.Context(), workspaceID)
+	}
 	if err != nil {
 		httpx.Internal(c)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"items": items})
 }
+
+type UpdateCategoryReq struct {
+	Name     *string `json:"name"`
+	Archived *bool   `json:"archived"`
+}
+
+func (h *Handler) update(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	var req UpdateCategoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+	if req.Name == nil && req.Archived == nil {
+		httpx.Unprocessable(c, "nothing to update", map[string]string{"name": "optional", "archived": "optional"})
+		return
+	}
+
+	cat, err := h.svc.Update(c.Request.Context(), c.GetString(auth.CtxUserIDKey), workspaceID, c.Param("categoryId"), req.Name, req.Archived)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidName):
+			httpx.Unprocessable(c, "invalid category name", map[string]string{"name": "required"})
+		case errors.Is(err, ErrCategoryExists):
+			httpx.Conflict(c, "category already exists")
+		case errors.Is(err, ErrCategoryNotFound):
+			httpx.Error(c, http.StatusNotFound, "category not found", nil)
+		default:
+			httpx.Internal(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category": cat})
+}
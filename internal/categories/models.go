@@ -10,9 +10,18 @@ const (
 )
 
 type Category struct {
-	ID          string    `json:"id"`
-	WorkspaceID string    `json:"workspace_id"`
-	Name        string    `json:"name"`
-	Type        Type      `json:"type"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspace_id"`
+	Name        string     `json:"name"`
+	Type        Type       `json:"type"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (c Category) Archived() bool { return c.ArchivedAt != nil }
+
+type CategoryUsage struct {
+	Category
+	TransactionCount int64 `json:"transaction_count"`
+	BudgetCount      int64 `json:"budget_count"`
 }
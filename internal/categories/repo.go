@@ -3,9 +3,12 @@ package categories
 import (
 	"context"
 	"errors"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"strings"
+
+	"github.com/skelbigo/FinanceTracker/internal/audit"
 )
 
 type Repo struct {
@@ -16,41 +19,61 @@ func NewRepo(pool *pgxpool.Pool) *Repo {
 	return &Repo{pool: pool}
 }
 
-func (r *Repo) CreateCategory(ctx context.Context, workspaceID, name string, t Type) (Category, error) {
+const categoryColumns = `id::text, workspace_id::text, name, type, archived_at, created_at`
+
+func scanCategory(row pgx.Row) (Category, error) {
+	var c Category
+	var t string
+	if err := row.Scan(&c.ID, &c.WorkspaceID, &c.Name, &t, &c.ArchivedAt, &c.CreatedAt); err != nil {
+		return Category{}, err
+	}
+	c.Type = Type(t)
+	return c, nil
+}
+
+func (r *Repo) CreateCategory(ctx context.Context, actorID, workspaceID, name string, t Type) (Category, error) {
 	name = strings.TrimSpace(name)
 
-	const q = `
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Category{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	c, err := scanCategory(tx.QueryRow(ctx, `
 INSERT INTO categories (workspace_id, name, type)
 VALUES ($1::uuid, $2, $3)
-RETURNING id::text, workspace_id::text, name, type, created_at
-`
-	var c Category
-	err := r.pool.QueryRow(ctx, q, workspaceID, name, string(t)).
-		Scan(&c.ID, &c.WorkspaceID, &c.Name, &c.Type, &c.CreatedAt)
-
+RETURNING `+categoryColumns, workspaceID, name, string(t)))
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			switch pgErr.Code {
-			case "23505":
-				return Category{}, ErrCategoryExists
-			case "23514":
-				return Category{}, ErrInvalidType
-			}
-		}
+		return Category{}, mapWriteErr(err)
+	}
+
+	if err := audit.Record(ctx, tx, audit.Entry{
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		EntityType:  audit.EntityCategory,
+		EntityID:    c.ID,
+		Action:      audit.ActionCreate,
+		After:       c,
+	}); err != nil {
+		return Category{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return Category{}, err
 	}
 	return c, nil
 }
 
-func (r *Repo) ListCategories(ctx context.Context, workspaceID string) ([]Category, error) {
+func (r *Repo) ListCategories(ctx context.Context, workspaceID string, includeArchived bool) ([]Category, error) {
 	const q = `
-SELECT id::text, workspace_id::text, name, type, created_at
+SELECT id::text, workspace_id::text, name, type, archived_at, created_at
 FROM categories
 WHERE workspace_id = $1::uuid
+  AND ($2 OR archived_at IS NULL)
 ORDER BY created_at ASC
 `
-	rows, err := r.pool.Query(ctx, q, workspaceID)
+	rows, err := r.pool.Query(ctx, q, workspaceID, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +83,7 @@ ORDER BY created_at ASC
 	for rows.Next() {
 		var c Category
 		var t string
-		if err := rows.Scan(&c.ID, &c.WorkspaceID, &c.Name, &t, &c.CreatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.WorkspaceID, &c.Name, &t, &c.ArchivedAt, &c.CreatedAt); err != nil {
 			return nil, err
 		}
 		c.Type = Type(t)
@@ -68,3 +91,129 @@ ORDER BY created_at ASC
 	}
 	return out, rows.Err()
 }
+
+// ListUsage returns every category (archived included) with the number of
+// transactions and budgets pointing at it. An empty categoryID lists all.
+func (r *Repo) ListUsage(ctx context.Context, workspaceID, categoryID string) ([]CategoryUsage, error) {
+	const q = `
+SELECT c.id::text, c.workspace_id::text, c.name, c.type, c.archived_at, c.created_at,
+	(SELECT COUNT(DISTINCT l.transaction_id) FROM transaction_lines l
+		WHERE l.workspace_id = c.workspace_id AND l.category_id = c.id AND l.deleted_at IS NULL) AS tx_count,
+	(SELECT COUNT(*) FROM budgets b WHERE b.workspace_id = c.workspace_id AND b.category_id = c.id) AS budget_count
+FROM categories c
+WHERE c.workspace_id = $1::uuid
+  AND ($2 = '' OR c.id::text = $2)
+ORDER BY c.archived_at IS NOT NULL, lower(c.name) ASC
+`
+	rows, err := r.pool.Query(ctx, q, workspaceID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CategoryUsage
+	for rows.Next() {
+		var u CategoryUsage
+		var t string
+		if err := rows.Scan(&u.ID, &u.WorkspaceID, &u.Name, &t, &u.ArchivedAt, &u.CreatedAt,
+			&u.TransactionCount, &u.BudgetCount); err != nil {
+			return nil, err
+		}
+		u.Type = Type(t)
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// UpdateCategory applies a rename and an archive change together; nil
+// fields keep their current value. A rename also refreshes the search index
+// of every transaction in the category (see migration 024), so its cost grows
+// with the category's history.
+func (r *Repo) UpdateCategory(ctx context.Context, actorID, workspaceID, categoryID string, name *string, archived *bool) (Category, error) {
+	return r.updateCategory(ctx, actorID, workspaceID, categoryID, `
+UPDATE categories
+SET name = COALESCE($3, name),
+    archived_at = CASE
+        WHEN $4::boolean IS NULL THEN archived_at
+        WHEN $4::boolean THEN COALESCE(archived_at, now())
+        ELSE NULL
+    END
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+RETURNING `+categoryColumns, name, archived)
+}
+
+// RenameCategory changes a category's name; like UpdateCategory, it
+// re-indexes the category's transactions for search in the same statement.
+func (r *Repo) RenameCategory(ctx context.Context, actorID, workspaceID, categoryID, name string) (Category, error) {
+	return r.updateCategory(ctx, actorID, workspaceID, categoryID, `
+UPDATE categories
+SET name = $3
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+RETURNING `+categoryColumns, name)
+}
+
+func (r *Repo) SetArchived(ctx context.Context, actorID, workspaceID, categoryID string, archived bool) (Category, error) {
+	return r.updateCategory(ctx, actorID, workspaceID, categoryID, `
+UPDATE categories
+SET archived_at = CASE WHEN $3 THEN COALESCE(archived_at, now()) ELSE NULL END
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+RETURNING `+categoryColumns, archived)
+}
+
+// updateCategory runs an UPDATE taking (workspace id, category id, args...)
+// and records the before/after state in the same transaction.
+func (r *Repo) updateCategory(ctx context.Context, actorID, workspaceID, categoryID, q string, args ...any) (Category, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Category{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := scanCategory(tx.QueryRow(ctx, `
+SELECT `+categoryColumns+`
+FROM categories
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+FOR UPDATE
+`, workspaceID, categoryID))
+	if err != nil {
+		return Category{}, mapWriteErr(err)
+	}
+
+	c, err := scanCategory(tx.QueryRow(ctx, q, append([]any{workspaceID, categoryID}, args...)...))
+	if err != nil {
+		return Category{}, mapWriteErr(err)
+	}
+
+	if err := audit.Record(ctx, tx, audit.Entry{
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		EntityType:  audit.EntityCategory,
+		EntityID:    c.ID,
+		Action:      audit.ActionUpdate,
+		Before:      before,
+		After:       c,
+	}); err != nil {
+		return Category{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Category{}, err
+	}
+	return c, nil
+}
+
+func mapWriteErr(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrCategoryNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrCategoryExists
+		case "23514":
+			return ErrInvalidType
+		}
+	}
+	return err
+}
@@ -3,6 +3,8 @@ package categories
 import (
 	"context"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 type Service struct{ repo *Repo }
@@ -11,15 +13,74 @@ func NewService(repo *Repo) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) Create(ctx context.Context, workspaceID, name string, t Type) (Category, error) {
+func (s *Service) Create(ctx context.Context, actorID, workspaceID, name string, t Type) (Category, error) {
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return Category{}, ErrInvalidType
+		return Category{}, ErrInvalidName
 	}
 
-	return s.repo.CreateCategory(ctx, workspaceID, name, t)
+	return s.repo.CreateCategory(ctx, actorID, workspaceID, name, t)
 }
 
+// List returns all categories, archived ones included, so existing
+// transactions and budgets can still resolve their names.
 func (s *Service) List(ctx context.Context, workspaceID string) ([]Category, error) {
-	return s.repo.ListCategories(ctx, workspaceID)
+	return s.repo.ListCategories(ctx, workspaceID, true)
+}
+
+// ListActive returns categories that can be picked for new records.
+func (s *Service) ListActive(ctx context.Context, workspaceID string) ([]Category, error) {
+	return s.repo.ListCategories(ctx, workspaceID, false)
+}
+
+func (s *Service) ListUsage(ctx context.Context, workspaceID string) ([]CategoryUsage, error) {
+	return s.repo.ListUsage(ctx, workspaceID, "")
+}
+
+func (s *Service) GetUsage(ctx context.Context, workspaceID, categoryID string) (CategoryUsage, error) {
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return CategoryUsage{}, ErrCategoryNotFound
+	}
+	items, err := s.repo.ListUsage(ctx, workspaceID, categoryID)
+	if err != nil {
+		return CategoryUsage{}, err
+	}
+	if len(items) == 0 {
+		return CategoryUsage{}, ErrCategoryNotFound
+	}
+	return items[0], nil
+}
+
+func (s *Service) Rename(ctx context.Context, actorID, workspaceID, categoryID, name string) (Category, error) {
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return Category{}, ErrCategoryNotFound
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Category{}, ErrInvalidName
+	}
+	return s.repo.RenameCategory(ctx, actorID, workspaceID, categoryID, name)
+}
+
+// Update renames and/or archives a category in one step; nil fields are left
+// as they are.
+func (s *Service) Update(ctx context.Context, actorID, workspaceID, categoryID string, name *string, archived *bool) (Category, error) {
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return Category{}, ErrCategoryNotFound
+	}
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" {
+			return Category{}, ErrInvalidName
+		}
+		name = &trimmed
+	}
+	return s.repo.UpdateCategory(ctx, actorID, workspaceID, categoryID, name, archived)
+}
+
+func (s *Service) SetArchived(ctx context.Context, actorID, workspaceID, categoryID string, archived bool) (Category, error) {
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return Category{}, ErrCategoryNotFound
+	}
+	return s.repo.SetArchived(ctx, actorID, workspaceID, categoryID, archived)
 }
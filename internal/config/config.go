@@ -27,6 +27,21 @@ const (
 
 	defaultBudgetsEnforceExpenseCategories = "true"
 
+	defaultAppBaseURL     = "http://localhost:8080"
+	defaultEmailFrom      = "no-reply@financetracker.local"
+	defaultSMTPPort       = "587"
+	defaultSMTPTLS        = "true"
+	defaultInviteTTLHours = "168"
+
+	defaultTrashRetentionDays = "30"
+
+	defaultAttachmentsStorage   = "local"
+	defaultAttachmentsDir       = "data/attachments"
+	defaultAttachmentsMaxFileMB = "10"
+	defaultAttachmentsQuotaMB   = "500"
+	defaultS3Region             = "us-east-1"
+	defaultS3ForcePathStyle     = "true"
+
 	maxPort = 65535
 )
 
@@ -42,9 +57,26 @@ func (c Config) CSRFTTL() time.Duration {
 	return time.Duration(c.CSRFTTLMinutes) * time.Minute
 }
 
+func (c Config) InviteTTL() time.Duration {
+	return time.Duration(c.InviteTTLHours) * time.Hour
+}
+
+func (c Config) TrashRetention() time.Duration {
+	return time.Duration(c.TrashRetentionDays) * 24 * time.Hour
+}
+
+func (c Config) AttachmentsMaxFileBytes() int64 {
+	return int64(c.AttachmentsMaxFileMB) << 20
+}
+
+func (c Config) AttachmentsQuotaBytes() int64 {
+	return int64(c.AttachmentsQuotaMB) << 20
+}
+
 type Config struct {
-	AppEnv  string
-	AppPort int
+	AppEnv     string
+	AppPort    int
+	AppBaseURL string
 
 	CookieDomain string
 	CookieSecure bool
@@ -70,6 +102,30 @@ type Config struct {
 	RefreshTTLDays      int
 
 	BudgetsEnforceExpenseCategories bool
+
+	EmailEnabled bool
+	EmailFrom    string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+	SMTPTLS      bool
+
+	InviteTTLHours int
+
+	TrashRetentionDays int
+
+	AttachmentsStorage   string
+	AttachmentsDir       string
+	AttachmentsMaxFileMB int
+	AttachmentsQuotaMB   int
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3ForcePathStyle  bool
 }
 
 func Load() (Config, error) {
@@ -81,6 +137,11 @@ func Load() (Config, error) {
 
 	cfg.AppPort = mustInt(getDefault("APP_PORT", defaultAppPort), "APP_PORT", &errs)
 
+	cfg.AppBaseURL = strings.TrimRight(getDefault("APP_BASE_URL", defaultAppBaseURL), "/")
+	if u, err := url.Parse(cfg.AppBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid APP_BASE_URL=%q (expected http(s)://host[:port])", cfg.AppBaseURL))
+	}
+
 	cfg.CookieDomain = strings.TrimSpace(os.Getenv("COOKIE_DOMAIN"))
 
 	cookieSecureRaw := strings.TrimSpace(os.Getenv("COOKIE_SECURE"))
@@ -169,6 +230,52 @@ func Load() (Config, error) {
 		&errs,
 	)
 
+	cfg.EmailEnabled = mustBool(getDefault("EMAIL_ENABLED", "false"), "EMAIL_ENABLED", &errs)
+	cfg.EmailFrom = getDefault("EMAIL_FROM", defaultEmailFrom)
+	if cfg.EmailEnabled {
+		cfg.SMTPHost = mustString("SMTP_HOST", &errs)
+		cfg.SMTPPort = mustInt(getDefault("SMTP_PORT", defaultSMTPPort), "SMTP_PORT", &errs)
+		cfg.SMTPUser = strings.TrimSpace(os.Getenv("SMTP_USER"))
+		cfg.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+		cfg.SMTPTLS = mustBool(getDefault("SMTP_TLS", defaultSMTPTLS), "SMTP_TLS", &errs)
+		if cfg.SMTPPort <= 0 || cfg.SMTPPort > maxPort {
+			errs = append(errs, fmt.Errorf("SMTP_PORT out of range: %d", cfg.SMTPPort))
+		}
+	}
+
+	cfg.InviteTTLHours = mustInt(getDefault("INVITE_TTL_HOURS", defaultInviteTTLHours), "INVITE_TTL_HOURS", &errs)
+	if cfg.InviteTTLHours <= 0 || cfg.InviteTTLHours > 30*24 {
+		errs = append(errs, fmt.Errorf("INVITE_TTL_HOURS out of range: %d", cfg.InviteTTLHours))
+	}
+
+	cfg.TrashRetentionDays = mustInt(getDefault("TRASH_RETENTION_DAYS", defaultTrashRetentionDays), "TRASH_RETENTION_DAYS", &errs)
+	if cfg.TrashRetentionDays <= 0 || cfg.TrashRetentionDays > 365 {
+		errs = append(errs, fmt.Errorf("TRASH_RETENTION_DAYS out of range: %d", cfg.TrashRetentionDays))
+	}
+
+	cfg.AttachmentsStorage = getDefault("ATTACHMENTS_STORAGE", defaultAttachmentsStorage)
+	validateOneOf("ATTACHMENTS_STORAGE", cfg.AttachmentsStorage, []string{"local", "s3"}, &errs)
+	cfg.AttachmentsDir = getDefault("ATTACHMENTS_DIR", defaultAttachmentsDir)
+	cfg.AttachmentsMaxFileMB = mustInt(getDefault("ATTACHMENTS_MAX_FILE_MB", defaultAttachmentsMaxFileMB), "ATTACHMENTS_MAX_FILE_MB", &errs)
+	if cfg.AttachmentsMaxFileMB <= 0 || cfg.AttachmentsMaxFileMB > 100 {
+		errs = append(errs, fmt.Errorf("ATTACHMENTS_MAX_FILE_MB out of range: %d", cfg.AttachmentsMaxFileMB))
+	}
+	cfg.AttachmentsQuotaMB = mustInt(getDefault("ATTACHMENTS_WORKSPACE_QUOTA_MB", defaultAttachmentsQuotaMB), "ATTACHMENTS_WORKSPACE_QUOTA_MB", &errs)
+	if cfg.AttachmentsQuotaMB < cfg.AttachmentsMaxFileMB || cfg.AttachmentsQuotaMB > 1<<20 {
+		errs = append(errs, fmt.Errorf("ATTACHMENTS_WORKSPACE_QUOTA_MB out of range: %d", cfg.AttachmentsQuotaMB))
+	}
+	if cfg.AttachmentsStorage == "s3" {
+		cfg.S3Endpoint = mustString("S3_ENDPOINT", &errs)
+		if u, err := url.Parse(cfg.S3Endpoint); cfg.S3Endpoint != "" && (err != nil || u.Scheme == "" || u.Host == "") {
+			errs = append(errs, fmt.Errorf("S3_ENDPOINT must be an absolute URL, got %q", cfg.S3Endpoint))
+		}
+		cfg.S3Region = getDefault("S3_REGION", defaultS3Region)
+		cfg.S3Bucket = mustString("S3_BUCKET", &errs)
+		cfg.S3AccessKeyID = mustString("S3_ACCESS_KEY_ID", &errs)
+		cfg.S3SecretAccessKey = mustString("S3_SECRET_ACCESS_KEY", &errs)
+		cfg.S3ForcePathStyle = mustBool(getDefault("S3_FORCE_PATH_STYLE", defaultS3ForcePathStyle), "S3_FORCE_PATH_STYLE", &errs)
+	}
+
 	if cfg.JWTAccessTTLMinutes <= 0 || cfg.JWTAccessTTLMinutes > 24*60 {
 		errs = append(errs, fmt.Errorf("JWT_ACCESS_TTL_MINUTES out of range: %d", cfg.JWTAccessTTLMinutes))
 	}
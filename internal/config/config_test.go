@@ -22,6 +22,13 @@ func unsetConfigEnv(t *testing.T) {
 		"COOKIE_SECURE",
 		"CSRF_SECRET",
 		"CSRF_TTL_MINUTES",
+		"APP_BASE_URL",
+		"EMAIL_ENABLED", "EMAIL_FROM",
+		"SMTP_HOST", "SMTP_PORT", "SMTP_USER", "SMTP_PASSWORD", "SMTP_TLS",
+		"INVITE_TTL_HOURS",
+		"TRASH_RETENTION_DAYS",
+		"ATTACHMENTS_STORAGE", "ATTACHMENTS_DIR", "ATTACHMENTS_MAX_FILE_MB", "ATTACHMENTS_WORKSPACE_QUOTA_MB",
+		"S3_ENDPOINT", "S3_REGION", "S3_BUCKET", "S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "S3_FORCE_PATH_STYLE",
 	}
 	for _, k := range keys {
 		t.Setenv(k, "")
@@ -0,0 +1,53 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const purgeInterval = time.Hour
+
+// RunJobs starts periodic maintenance tasks; they stop when ctx is done.
+func (a *App) RunJobs(ctx context.Context, logger *log.Logger) {
+	go runPeriodic(ctx, purgeInterval, func(ctx context.Context) {
+		n, err := a.deps.WorkspacesSvc.PurgeDeleted(ctx)
+		if err != nil {
+			logger.Printf("purge deleted workspaces: %v", err)
+		} else if n > 0 {
+			logger.Printf("purged %d deleted workspaces", n)
+		}
+	})
+	go runPeriodic(ctx, purgeInterval, func(ctx context.Context) {
+		n, err := a.deps.TransactionsSvc.PurgeDeleted(ctx)
+		if err != nil {
+			logger.Printf("purge trashed transactions: %v", err)
+		} else if n > 0 {
+			logger.Printf("purged %d trashed transactions", n)
+		}
+	})
+	go runPeriodic(ctx, purgeInterval, func(ctx context.Context) {
+		n, err := a.deps.AttachmentsSvc.PurgeDeleted(ctx)
+		if err != nil {
+			logger.Printf("purge deleted attachment files: %v", err)
+		} else if n > 0 {
+			logger.Printf("purged %d deleted attachment files", n)
+		}
+	})
+}
+
+// runPeriodic runs job immediately and then on every tick until ctx is done.
+func runPeriodic(ctx context.Context, every time.Duration, job func(context.Context)) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+
+	for {
+		job(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package httpapi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunPeriodic_RunsImmediatelyAndStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan struct{}, 1)
+	done := make(chan struct{})
+
+	go func() {
+		runPeriodic(ctx, time.Hour, func(context.Context) { runs <- struct{}{} })
+		close(done)
+	}()
+
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("job did not run before the first tick")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runPeriodic did not stop after cancel")
+	}
+}
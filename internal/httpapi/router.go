@@ -3,8 +3,12 @@ package httpapi
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/skelbigo/FinanceTracker/internal/analytics"
+	"github.com/skelbigo/FinanceTracker/internal/attachments"
 	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/budgets"
 	"github.com/skelbigo/FinanceTracker/internal/categories"
+	"github.com/skelbigo/FinanceTracker/internal/savedviews"
 	"github.com/skelbigo/FinanceTracker/internal/transactions"
 	"github.com/skelbigo/FinanceTracker/internal/web"
 	"github.com/skelbigo/FinanceTracker/internal/workspaces"
@@ -40,15 +44,25 @@ type RouterDeps struct {
 	CSRFTTL    time.Duration
 
 	WorkspacesSvc   *workspaces.Service
+	InvitationsSvc  *workspaces.InvitationService
 	CategoriesSvc   *categories.Service
 	TransactionsSvc *transactions.Service
+	AttachmentsSvc  *attachments.Service
+	BudgetsSvc      *budgets.Service
+	AnalyticsSvc    *analytics.Service
+	SavedViewsSvc   *savedviews.Service
 
 	Auth         RoutesRegistrar
 	Workspaces   RoutesRegistrar
+	Invitations  RoutesRegistrar
 	Categories   RoutesRegistrar
 	Transactions RoutesRegistrar
+	SavedViews   RoutesRegistrar
+	Attachments  RoutesRegistrar
 	Budgets      RoutesRegistrar
 	Analytics    RoutesRegistrar
+	NetWorth     RoutesRegistrar
+	Audit        RoutesRegistrar
 }
 
 func SetupRouter(r *gin.Engine, deps RouterDeps) *gin.Engine {
@@ -62,10 +76,15 @@ func SetupRouter(r *gin.Engine, deps RouterDeps) *gin.Engine {
 	}{
 		{"Auth", deps.Auth},
 		{"Workspaces", deps.Workspaces},
+		{"Invitations", deps.Invitations},
 		{"Categories", deps.Categories},
 		{"Transactions", deps.Transactions},
+		{"SavedViews", deps.SavedViews},
+		{"Attachments", deps.Attachments},
 		{"Budgets", deps.Budgets},
 		{"Analytics", deps.Analytics},
+		{"NetWorth", deps.NetWorth},
+		{"Audit", deps.Audit},
 	}
 
 	for _, c := range checks {
@@ -84,8 +103,13 @@ func SetupRouter(r *gin.Engine, deps RouterDeps) *gin.Engine {
 
 		Auth:         deps.AuthSvc,
 		Workspaces:   deps.WorkspacesSvc,
+		Invitations:  deps.InvitationsSvc,
 		Categories:   deps.CategoriesSvc,
 		Transactions: deps.TransactionsSvc,
+		Attachments:  deps.AttachmentsSvc,
+		Budgets:      deps.BudgetsSvc,
+		Analytics:    deps.AnalyticsSvc,
+		SavedViews:   deps.SavedViewsSvc,
 		JWTM:         deps.JWTM,
 		CookieCfg:    deps.CookieCfg,
 		AccessTTL:    deps.AccessTTL,
@@ -98,10 +122,15 @@ func SetupRouter(r *gin.Engine, deps RouterDeps) *gin.Engine {
 
 	deps.Auth.RegisterRoutes(r)
 	deps.Workspaces.RegisterRoutes(r)
+	deps.Invitations.RegisterRoutes(r)
 	deps.Categories.RegisterRoutes(r)
 	deps.Transactions.RegisterRoutes(r)
+	deps.SavedViews.RegisterRoutes(r)
+	deps.Attachments.RegisterRoutes(r)
 	deps.Budgets.RegisterRoutes(r)
 	deps.Analytics.RegisterRoutes(r)
+	deps.NetWorth.RegisterRoutes(r)
+	deps.Audit.RegisterRoutes(r)
 
 	return r
 }
@@ -3,10 +3,15 @@ package httpapi
 import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/skelbigo/FinanceTracker/internal/analytics"
+	"github.com/skelbigo/FinanceTracker/internal/attachments"
+	"github.com/skelbigo/FinanceTracker/internal/audit"
 	"github.com/skelbigo/FinanceTracker/internal/auth"
 	"github.com/skelbigo/FinanceTracker/internal/budgets"
 	"github.com/skelbigo/FinanceTracker/internal/categories"
 	"github.com/skelbigo/FinanceTracker/internal/config"
+	"github.com/skelbigo/FinanceTracker/internal/mailer"
+	"github.com/skelbigo/FinanceTracker/internal/networth"
+	"github.com/skelbigo/FinanceTracker/internal/savedviews"
 	"github.com/skelbigo/FinanceTracker/internal/transactions"
 	"github.com/skelbigo/FinanceTracker/internal/web"
 	"github.com/skelbigo/FinanceTracker/internal/workspaces"
@@ -35,15 +40,55 @@ func BuildRouterDeps(cfg config.Config, pool *pgxpool.Pool, startedAt time.Time)
 	wsSvc := workspaces.NewService(wsRepo)
 	wsH := workspaces.NewHandler(wsSvc, authMW, wsRepo)
 
+	// invitations
+	var mail mailer.Mailer = mailer.NewLogMailer()
+	if cfg.EmailEnabled {
+		mail = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			User:     cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+			From:     cfg.EmailFrom,
+			TLS:      cfg.SMTPTLS,
+		})
+	}
+	invSvc := workspaces.NewInvitationService(wsRepo, mail, cfg.InviteTTL(), cfg.AppBaseURL, returnResetToken)
+	invH := workspaces.NewInvitationHandler(invSvc, authMW, wsRepo)
+
 	// categories
 	catRepo := categories.NewRepo(pool)
 	catSvc := categories.NewService(catRepo)
 	catH := categories.NewHandler(catSvc, authMW, wsRepo)
 
+	// saved views
+	viewRepo := savedviews.NewRepo(pool)
+	viewSvc := savedviews.NewService(viewRepo)
+	viewH := savedviews.NewHandler(viewSvc, authMW, wsRepo)
+
 	// transactions
 	txRepo := transactions.NewRepo(pool)
-	txSvc := transactions.NewService(txRepo)
-	txH := transactions.NewHandler(txSvc, authMW, wsRepo)
+	txSvc := transactions.NewService(txRepo, cfg.TrashRetention())
+	txH := transactions.NewHandler(txSvc, authMW, wsRepo, viewSvc)
+
+	// attachments
+	var store attachments.Storage = attachments.NewLocalStorage(cfg.AttachmentsDir)
+	if cfg.AttachmentsStorage == "s3" {
+		s3, err := attachments.NewS3Storage(attachments.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			ForcePathStyle:  cfg.S3ForcePathStyle,
+		})
+		if err != nil {
+			panic("httpapi: " + err.Error())
+		}
+		store = s3
+	}
+	attRepo := attachments.NewRepo(pool)
+	attSvc := attachments.NewService(attRepo, store, cfg.AttachmentsMaxFileBytes(), cfg.AttachmentsQuotaBytes())
+	attH := attachments.NewHandler(attSvc, txSvc, authMW, wsRepo)
 
 	// budgets
 	bRepo := budgets.NewRepo(pool)
@@ -54,7 +99,17 @@ func BuildRouterDeps(cfg config.Config, pool *pgxpool.Pool, startedAt time.Time)
 	// analytics
 	aRepo := analytics.NewRepo(pool)
 	aSvc := analytics.NewService(aRepo)
-	aH := analytics.NewHandler(aSvc, authMW, wsRepo)
+	aH := analytics.NewHandler(aSvc, authMW, wsRepo, viewSvc)
+
+	// net worth
+	nwRepo := networth.NewRepo(pool)
+	nwSvc := networth.NewService(nwRepo)
+	nwH := networth.NewHandler(nwSvc, authMW, wsRepo)
+
+	// audit log
+	auditRepo := audit.NewRepo(pool)
+	auditSvc := audit.NewService(auditRepo)
+	auditH := audit.NewHandler(auditSvc, authMW, workspaces.RequireWorkspaceRole(wsRepo, workspaces.RoleOwner), workspaces.GetLocation)
 
 	return RouterDeps{
 		Readiness: pool,
@@ -70,14 +125,24 @@ func BuildRouterDeps(cfg config.Config, pool *pgxpool.Pool, startedAt time.Time)
 		CSRFTTL:    cfg.CSRFTTL(),
 
 		WorkspacesSvc:   wsSvc,
+		InvitationsSvc:  invSvc,
 		CategoriesSvc:   catSvc,
 		TransactionsSvc: txSvc,
+		AttachmentsSvc:  attSvc,
+		BudgetsSvc:      bSvc,
+		AnalyticsSvc:    aSvc,
+		SavedViewsSvc:   viewSvc,
 
 		Auth:         authH,
 		Workspaces:   wsH,
+		Invitations:  invH,
 		Categories:   catH,
 		Transactions: txH,
+		SavedViews:   viewH,
+		Attachments:  attH,
 		Budgets:      bH,
 		Analytics:    aH,
+		NetWorth:     nwH,
+		Audit:        auditH,
 	}
 }
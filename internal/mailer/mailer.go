@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogMailer writes messages to the application log instead of delivering
+// them; it is used when EMAIL_ENABLED=false.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer { return &LogMailer{} }
+
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	log.Printf("mail: to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,14 @@
+package networth
+
+import "errors"
+
+var (
+	ErrAccountExists   = errors.New("account already exists")
+	ErrAccountNotFound = errors.New("account not found")
+	ErrInvalidName     = errors.New("invalid name")
+	ErrInvalidKind     = errors.New("invalid kind")
+	ErrInvalidCurrency = errors.New("invalid currency")
+	ErrInvalidDate     = errors.New("invalid date")
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrInvalidRate     = errors.New("invalid rate")
+)
@@ -0,0 +1,210 @@
+package networth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/skelbigo/FinanceTracker/internal/httpx"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type Handler struct {
+	svc *Service
+	mw  gin.HandlerFunc
+	ws  workspaces.RoleProvider
+}
+
+func NewHandler(svc *Service, authMW gin.HandlerFunc, ws workspaces.RoleProvider) *Handler {
+	return &Handler{svc: svc, mw: authMW, ws: ws}
+}
+
+func (h *Handler) RegisterRoutes(r gin.IRouter) {
+	g := r.Group("/workspaces")
+	g.Use(h.mw)
+
+	nw := g.Group("/:id/net-worth")
+	nw.GET("/accounts", workspaces.RequirePermission(h.ws, workspaces.PermTxRead), h.listAccounts)
+	nw.POST("/accounts", workspaces.RequirePermission(h.ws, workspaces.PermBudgetsWrite), h.createAccount)
+	nw.DELETE("/accounts/:accountId", workspaces.RequirePermission(h.ws, workspaces.PermBudgetsWrite), h.deleteAccount)
+	nw.GET("/accounts/:accountId/valuations", workspaces.RequirePermission(h.ws, workspaces.PermTxRead), h.listValuations)
+	nw.PUT("/accounts/:accountId/valuations", workspaces.RequirePermission(h.ws, workspaces.PermBudgetsWrite), h.upsertValuation)
+	nw.GET("/rates", workspaces.RequirePermission(h.ws, workspaces.PermTxRead), h.listRates)
+	nw.PUT("/rates", workspaces.RequirePermission(h.ws, workspaces.PermBudgetsWrite), h.upsertRate)
+}
+
+type createAccountReq struct {
+	Name     string `json:"name" binding:"required"`
+	Kind     string `json:"kind" binding:"required"`
+	Currency string `json:"currency" binding:"required"`
+}
+
+type upsertValuationReq struct {
+	ValuedOn    string `json:"valued_on" binding:"required"`
+	AmountMinor *int64 `json:"amount_minor" binding:"required"`
+}
+
+type upsertRateReq struct {
+	Currency string   `json:"currency" binding:"required"`
+	RateDate string   `json:"rate_date" binding:"required"`
+	Rate     *float64 `json:"rate" binding:"required"`
+}
+
+func (h *Handler) createAccount(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	var req createAccountReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	kind := Kind(strings.ToLower(strings.TrimSpace(req.Kind)))
+	acc, err := h.svc.CreateAccount(c.Request.Context(), workspaceID, req.Name, kind, req.Currency)
+	if err != nil {
+		respondErr(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"account": acc})
+}
+
+func (h *Handler) listAccounts(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	items, err := h.svc.ListAccounts(c.Request.Context(), workspaceID)
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) deleteAccount(c *gin.Context) {
+	workspaceID, accountID, ok := accountParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteAccount(c.Request.Context(), workspaceID, accountID); err != nil {
+		respondErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) listValuations(c *gin.Context) {
+	workspaceID, accountID, ok := accountParams(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListValuations(c.Request.Context(), workspaceID, accountID)
+	if err != nil {
+		respondErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) upsertValuation(c *gin.Context) {
+	workspaceID, accountID, ok := accountParams(c)
+	if !ok {
+		return
+	}
+
+	var req upsertValuationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	v, err := h.svc.UpsertValuation(c.Request.Context(), workspaceID, accountID, req.ValuedOn, *req.AmountMinor)
+	if err != nil {
+		respondErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"valuation": v})
+}
+
+func (h *Handler) listRates(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	items, err := h.svc.ListRates(c.Request.Context(), workspaceID)
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) upsertRate(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	var req upsertRateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	rate, err := h.svc.UpsertRate(c.Request.Context(), workspaceID, req.Currency, req.RateDate, *req.Rate)
+	if err != nil {
+		respondErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rate": rate})
+}
+
+func accountParams(c *gin.Context) (string, string, bool) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return "", "", false
+	}
+	accountID := c.Param("accountId")
+	if _, err := uuid.Parse(accountID); err != nil {
+		httpx.BadRequest(c, "invalid account id", map[string]string{"accountId": "must be uuid"})
+		return "", "", false
+	}
+	return workspaceID, accountID, true
+}
+
+func respondErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidName):
+		httpx.Unprocessable(c, "invalid name", map[string]string{"name": "required"})
+	case errors.Is(err, ErrInvalidKind):
+		httpx.Unprocessable(c, "invalid kind", map[string]string{"kind": "asset|liability"})
+	case errors.Is(err, ErrInvalidCurrency):
+		httpx.Unprocessable(c, "invalid currency", map[string]string{"currency": "must be 3-letter ISO code"})
+	case errors.Is(err, ErrInvalidDate):
+		httpx.Unprocessable(c, "invalid date", map[string]string{"date": "must be YYYY-MM-DD"})
+	case errors.Is(err, ErrInvalidAmount):
+		httpx.Unprocessable(c, "invalid amount", map[string]string{"amount_minor": "must be >= 0"})
+	case errors.Is(err, ErrInvalidRate):
+		httpx.Unprocessable(c, "invalid rate", map[string]string{"rate": "must be > 0"})
+	case errors.Is(err, ErrAccountExists):
+		httpx.Conflict(c, "account already exists")
+	case errors.Is(err, ErrAccountNotFound):
+		httpx.Error(c, http.StatusNotFound, "account not found", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
@@ -0,0 +1,37 @@
+package networth
+
+import "time"
+
+type Kind string
+
+const (
+	KindAsset     Kind = "asset"
+	KindLiability Kind = "liability"
+)
+
+type Account struct {
+	ID             string    `json:"id"`
+	WorkspaceID    string    `json:"workspace_id"`
+	Name           string    `json:"name"`
+	Kind           Kind      `json:"kind"`
+	Currency       string    `json:"currency"`
+	LatestAmount   *int64    `json:"latest_amount,omitempty"`
+	LatestValuedOn *string   `json:"latest_valued_on,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type Valuation struct {
+	ID          string    `json:"id"`
+	AccountID   string    `json:"account_id"`
+	ValuedOn    string    `json:"valued_on"`
+	AmountMinor int64     `json:"amount_minor"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ExchangeRate says how many units of the workspace default currency one unit
+// of Currency was worth on RateDate.
+type ExchangeRate struct {
+	Currency string  `json:"currency"`
+	RateDate string  `json:"rate_date"`
+	Rate     float64 `json:"rate"`
+}
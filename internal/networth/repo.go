@@ -0,0 +1,200 @@
+package networth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const dateLayout = "2006-01-02"
+
+type Repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepo(pool *pgxpool.Pool) *Repo {
+	return &Repo{pool: pool}
+}
+
+func (r *Repo) CreateAccount(ctx context.Context, workspaceID, name string, kind Kind, currency string) (Account, error) {
+	const q = `
+INSERT INTO manual_accounts (workspace_id, name, kind, currency)
+VALUES ($1::uuid, $2, $3, $4)
+RETURNING id::text, workspace_id::text, name, kind, currency, created_at
+`
+	var a Account
+	var k string
+	err := r.pool.QueryRow(ctx, q, workspaceID, name, string(kind), currency).
+		Scan(&a.ID, &a.WorkspaceID, &a.Name, &k, &a.Currency, &a.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return Account{}, ErrAccountExists
+			case "23514":
+				return Account{}, ErrInvalidKind
+			}
+		}
+		return Account{}, err
+	}
+	a.Kind = Kind(k)
+	return a, nil
+}
+
+func (r *Repo) ListAccounts(ctx context.Context, workspaceID string) ([]Account, error) {
+	const q = `
+SELECT a.id::text, a.workspace_id::text, a.name, a.kind, a.currency, a.created_at,
+	v.amount_minor, v.valued_on
+FROM manual_accounts a
+LEFT JOIN LATERAL (
+	SELECT amount_minor, valued_on
+	FROM manual_account_valuations
+	WHERE account_id = a.id
+	ORDER BY valued_on DESC
+	LIMIT 1
+) v ON true
+WHERE a.workspace_id = $1::uuid
+ORDER BY a.kind ASC, a.name ASC
+`
+	rows, err := r.pool.Query(ctx, q, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Account
+	for rows.Next() {
+		var a Account
+		var k string
+		var valuedOn *time.Time
+		if err := rows.Scan(&a.ID, &a.WorkspaceID, &a.Name, &k, &a.Currency, &a.CreatedAt,
+			&a.LatestAmount, &valuedOn); err != nil {
+			return nil, err
+		}
+		a.Kind = Kind(k)
+		if valuedOn != nil {
+			s := valuedOn.Format(dateLayout)
+			a.LatestValuedOn = &s
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) DeleteAccount(ctx context.Context, workspaceID, accountID string) (bool, error) {
+	const q = `
+DELETE FROM manual_accounts
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+`
+	ct, err := r.pool.Exec(ctx, q, workspaceID, accountID)
+	if err != nil {
+		return false, err
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+func (r *Repo) AccountExists(ctx context.Context, workspaceID, accountID string) (bool, error) {
+	const q = `SELECT 1 FROM manual_accounts WHERE workspace_id = $1::uuid AND id = $2::uuid`
+	var one int
+	err := r.pool.QueryRow(ctx, q, workspaceID, accountID).Scan(&one)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Repo) UpsertValuation(ctx context.Context, accountID string, valuedOn time.Time, amountMinor int64) (Valuation, error) {
+	const q = `
+INSERT INTO manual_account_valuations (account_id, valued_on, amount_minor)
+VALUES ($1::uuid, $2, $3)
+ON CONFLICT (account_id, valued_on)
+DO UPDATE SET amount_minor = EXCLUDED.amount_minor
+RETURNING id::text, account_id::text, valued_on, amount_minor, created_at
+`
+	var v Valuation
+	var on time.Time
+	err := r.pool.QueryRow(ctx, q, accountID, valuedOn, amountMinor).
+		Scan(&v.ID, &v.AccountID, &on, &v.AmountMinor, &v.CreatedAt)
+	if err != nil {
+		return Valuation{}, err
+	}
+	v.ValuedOn = on.Format(dateLayout)
+	return v, nil
+}
+
+func (r *Repo) ListValuations(ctx context.Context, accountID string) ([]Valuation, error) {
+	const q = `
+SELECT id::text, account_id::text, valued_on, amount_minor, created_at
+FROM manual_account_valuations
+WHERE account_id = $1::uuid
+ORDER BY valued_on DESC
+`
+	rows, err := r.pool.Query(ctx, q, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Valuation
+	for rows.Next() {
+		var v Valuation
+		var on time.Time
+		if err := rows.Scan(&v.ID, &v.AccountID, &on, &v.AmountMinor, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		v.ValuedOn = on.Format(dateLayout)
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) UpsertRate(ctx context.Context, workspaceID, currency string, rateDate time.Time, rate float64) (ExchangeRate, error) {
+	const q = `
+INSERT INTO exchange_rates (workspace_id, currency, rate_date, rate)
+VALUES ($1::uuid, $2, $3, $4)
+ON CONFLICT (workspace_id, currency, rate_date)
+DO UPDATE SET rate = EXCLUDED.rate
+RETURNING currency, rate_date, rate::float8
+`
+	var out ExchangeRate
+	var on time.Time
+	if err := r.pool.QueryRow(ctx, q, workspaceID, currency, rateDate, rate).Scan(&out.Currency, &on, &out.Rate); err != nil {
+		return ExchangeRate{}, err
+	}
+	out.RateDate = on.Format(dateLayout)
+	return out, nil
+}
+
+func (r *Repo) ListRates(ctx context.Context, workspaceID string) ([]ExchangeRate, error) {
+	const q = `
+SELECT currency, rate_date, rate::float8
+FROM exchange_rates
+WHERE workspace_id = $1::uuid
+ORDER BY currency ASC, rate_date DESC
+`
+	rows, err := r.pool.Query(ctx, q, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ExchangeRate
+	for rows.Next() {
+		var e ExchangeRate
+		var on time.Time
+		if err := rows.Scan(&e.Currency, &on, &e.Rate); err != nil {
+			return nil, err
+		}
+		e.RateDate = on.Format(dateLayout)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,115 @@
+package networth
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type Service struct {
+	repo *Repo
+}
+
+func NewService(repo *Repo) *Service {
+	return &Service{repo: repo}
+}
+
+func normalizeCurrency(s string) (string, error) {
+	cur := strings.ToUpper(strings.TrimSpace(s))
+	if !currencyRe.MatchString(cur) {
+		return "", ErrInvalidCurrency
+	}
+	return cur, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(dateLayout, strings.TrimSpace(s), time.UTC)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return t, nil
+}
+
+func (s *Service) CreateAccount(ctx context.Context, workspaceID, name string, kind Kind, currency string) (Account, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Account{}, ErrInvalidName
+	}
+	if kind != KindAsset && kind != KindLiability {
+		return Account{}, ErrInvalidKind
+	}
+	cur, err := normalizeCurrency(currency)
+	if err != nil {
+		return Account{}, err
+	}
+	return s.repo.CreateAccount(ctx, workspaceID, name, kind, cur)
+}
+
+func (s *Service) ListAccounts(ctx context.Context, workspaceID string) ([]Account, error) {
+	return s.repo.ListAccounts(ctx, workspaceID)
+}
+
+func (s *Service) DeleteAccount(ctx context.Context, workspaceID, accountID string) error {
+	ok, err := s.repo.DeleteAccount(ctx, workspaceID, accountID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
+func (s *Service) UpsertValuation(ctx context.Context, workspaceID, accountID, valuedOn string, amountMinor int64) (Valuation, error) {
+	on, err := parseDate(valuedOn)
+	if err != nil {
+		return Valuation{}, err
+	}
+	if amountMinor < 0 {
+		return Valuation{}, ErrInvalidAmount
+	}
+	if err := s.mustAccount(ctx, workspaceID, accountID); err != nil {
+		return Valuation{}, err
+	}
+	return s.repo.UpsertValuation(ctx, accountID, on, amountMinor)
+}
+
+func (s *Service) ListValuations(ctx context.Context, workspaceID, accountID string) ([]Valuation, error) {
+	if err := s.mustAccount(ctx, workspaceID, accountID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListValuations(ctx, accountID)
+}
+
+func (s *Service) UpsertRate(ctx context.Context, workspaceID, currency, rateDate string, rate float64) (ExchangeRate, error) {
+	cur, err := normalizeCurrency(currency)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	on, err := parseDate(rateDate)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	if rate <= 0 {
+		return ExchangeRate{}, ErrInvalidRate
+	}
+	return s.repo.UpsertRate(ctx, workspaceID, cur, on, rate)
+}
+
+func (s *Service) ListRates(ctx context.Context, workspaceID string) ([]ExchangeRate, error) {
+	return s.repo.ListRates(ctx, workspaceID)
+}
+
+func (s *Service) mustAccount(ctx context.Context, workspaceID, accountID string) error {
+	ok, err := s.repo.AccountExists(ctx, workspaceID, accountID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAccountNotFound
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package savedviews
+
+import (
+	"errors"
+
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+)
+
+var (
+	// ErrViewNotFound is shared with transactions so list, export and
+	// analytics requests can report a missing view_id without importing this
+	// package.
+	ErrViewNotFound = transactions.ErrViewNotFound
+	ErrViewExists   = errors.New("saved view name already in use")
+	ErrInvalidName  = errors.New("invalid saved view name")
+	ErrInvalidSort  = errors.New("invalid saved view sort")
+	ErrSharedDenied = errors.New("your role cannot manage shared views")
+)
@@ -0,0 +1,163 @@
+package savedviews
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/httpx"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type Handler struct {
+	svc *Service
+	mw  gin.HandlerFunc
+	ws  workspaces.RoleProvider
+}
+
+func NewHandler(svc *Service, authMW gin.HandlerFunc, ws workspaces.RoleProvider) *Handler {
+	return &Handler{svc: svc, mw: authMW, ws: ws}
+}
+
+// RegisterRoutes mounts the saved views API. Anyone who can read
+// transactions keeps personal views; shared views need tx:write.
+func (h *Handler) RegisterRoutes(r gin.IRouter) {
+	g := r.Group("/workspaces")
+	g.Use(h.mw)
+
+	wsg := g.Group("/:id")
+	wsg.Use(workspaces.RequirePermission(h.ws, workspaces.PermTxRead))
+	wsg.GET("/views", h.list)
+	wsg.POST("/views", h.create)
+	wsg.GET("/views/:viewId", h.get)
+	wsg.PUT("/views/:viewId", h.update)
+	wsg.DELETE("/views/:viewId", h.remove)
+}
+
+type ViewReq struct {
+	Name    string            `json:"name" binding:"required"`
+	Shared  bool              `json:"shared"`
+	Filters map[string]string `json:"filters"`
+	Sort    string            `json:"sort"`
+}
+
+func (r ViewReq) input() Input {
+	return Input{Name: r.Name, Shared: r.Shared, Filters: r.Filters, Sort: r.Sort}
+}
+
+func (h *Handler) list(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	items, err := h.svc.List(c.Request.Context(), workspaceID, c.GetString(auth.CtxUserIDKey))
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+	if items == nil {
+		items = []View{}
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) get(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	v, err := h.svc.Get(c.Request.Context(), workspaceID, c.GetString(auth.CtxUserIDKey), c.Param("viewId"))
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"view": v})
+}
+
+func (h *Handler) create(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	var req ViewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	v, err := h.svc.Create(c.Request.Context(), workspaceID, c.GetString(auth.CtxUserIDKey), req.input(), canShare(c))
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"view": v})
+}
+
+func (h *Handler) update(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	var req ViewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	v, err := h.svc.Update(c.Request.Context(), workspaceID, c.GetString(auth.CtxUserIDKey), c.Param("viewId"), req.input(), canShare(c))
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"view": v})
+}
+
+func (h *Handler) remove(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), workspaceID, c.GetString(auth.CtxUserIDKey), c.Param("viewId"), canShare(c)); err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func canShare(c *gin.Context) bool {
+	access, ok := workspaces.GetAccess(c)
+	return ok && access.Has(workspaces.PermTxWrite)
+}
+
+func writeErr(c *gin.Context, err error) {
+	var ferr *transactions.FilterError
+	switch {
+	case errors.As(err, &ferr):
+		httpx.Unprocessable(c, ferr.Msg, map[string]string{ferr.Field: ferr.Hint})
+	case errors.Is(err, ErrInvalidName):
+		httpx.Unprocessable(c, "invalid view name", map[string]string{"name": "required, at most 100 characters"})
+	case errors.Is(err, ErrInvalidSort):
+		httpx.Unprocessable(c, "invalid sort", map[string]string{"sort": "occurred_at_desc|occurred_at_asc|amount_desc|amount_asc|relevance"})
+	case errors.Is(err, ErrViewExists):
+		httpx.Conflict(c, "a view with this name already exists")
+	case errors.Is(err, ErrViewNotFound):
+		httpx.Error(c, http.StatusNotFound, "saved view not found", nil)
+	case errors.Is(err, ErrSharedDenied):
+		httpx.Error(c, http.StatusForbidden, "your role cannot manage shared views", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
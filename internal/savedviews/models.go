@@ -0,0 +1,42 @@
+package savedviews
+
+import "time"
+
+const maxNameLen = 100
+
+// View is a named set of transaction list filters. Personal views belong to
+// UserID; shared views have none and are visible to the whole workspace.
+type View struct {
+	ID          string            `json:"id"`
+	WorkspaceID string            `json:"workspace_id"`
+	UserID      *string           `json:"user_id"`
+	Shared      bool              `json:"shared"`
+	Name        string            `json:"name"`
+	Filters     map[string]string `json:"filters"`
+	Sort        string            `json:"sort"`
+	CreatedBy   *string           `json:"created_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Params returns the filters plus the sort under "sort", the shape list
+// requests take.
+func (v View) Params() map[string]string {
+	out := make(map[string]string, len(v.Filters)+1)
+	for k, val := range v.Filters {
+		out[k] = val
+	}
+	if v.Sort != "" {
+		out["sort"] = v.Sort
+	}
+	return out
+}
+
+// Input is what a view is created or replaced with. Filters use the
+// transactions list parameters (transactions.FilterKeys).
+type Input struct {
+	Name    string
+	Shared  bool
+	Filters map[string]string
+	Sort    string
+}
@@ -0,0 +1,138 @@
+package savedviews
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepo(pool *pgxpool.Pool) *Repo {
+	return &Repo{pool: pool}
+}
+
+const viewColumns = `id::text, workspace_id::text, user_id::text, name, filters, sort, created_by::text, created_at, updated_at`
+
+func scanView(row pgx.Row) (View, error) {
+	var v View
+	if err := row.Scan(&v.ID, &v.WorkspaceID, &v.UserID, &v.Name, &v.Filters, &v.Sort,
+		&v.CreatedBy, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		return View{}, err
+	}
+	v.Shared = v.UserID == nil
+	if v.Filters == nil {
+		v.Filters = map[string]string{}
+	}
+	return v, nil
+}
+
+// List returns the workspace's shared views and the user's own, shared
+// ones first.
+func (r *Repo) List(ctx context.Context, workspaceID, userID string) ([]View, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT `+viewColumns+`
+FROM saved_views
+WHERE workspace_id = $1::uuid
+  AND (user_id IS NULL OR user_id = $2::uuid)
+ORDER BY user_id IS NOT NULL, lower(name) ASC
+`, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []View
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// Get returns a view the user can see: a shared one or their own.
+func (r *Repo) Get(ctx context.Context, workspaceID, userID, viewID string) (View, error) {
+	v, err := scanView(r.pool.QueryRow(ctx, `
+SELECT `+viewColumns+`
+FROM saved_views
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+  AND (user_id IS NULL OR user_id = $3::uuid)
+`, workspaceID, viewID, userID))
+	if err != nil {
+		return View{}, mapWriteErr(err)
+	}
+	return v, nil
+}
+
+func (r *Repo) Create(ctx context.Context, workspaceID, userID string, in Input) (View, error) {
+	v, err := scanView(r.pool.QueryRow(ctx, `
+INSERT INTO saved_views (workspace_id, user_id, name, filters, sort, created_by)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5, $6::uuid)
+RETURNING `+viewColumns, workspaceID, owner(userID, in.Shared), in.Name, in.Filters, in.Sort, userID))
+	if err != nil {
+		return View{}, mapWriteErr(err)
+	}
+	return v, nil
+}
+
+// Update replaces a view the user can see, which may move it between
+// personal and shared.
+func (r *Repo) Update(ctx context.Context, workspaceID, userID, viewID string, in Input) (View, error) {
+	v, err := scanView(r.pool.QueryRow(ctx, `
+UPDATE saved_views
+SET user_id = $4::uuid, name = $5, filters = $6, sort = $7, updated_at = now()
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+  AND (user_id IS NULL OR user_id = $3::uuid)
+RETURNING `+viewColumns, workspaceID, viewID, userID, owner(userID, in.Shared), in.Name, in.Filters, in.Sort))
+	if err != nil {
+		return View{}, mapWriteErr(err)
+	}
+	return v, nil
+}
+
+func (r *Repo) Delete(ctx context.Context, workspaceID, userID, viewID string) error {
+	tag, err := r.pool.Exec(ctx, `
+DELETE FROM saved_views
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+  AND (user_id IS NULL OR user_id = $3::uuid)
+`, workspaceID, viewID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrViewNotFound
+	}
+	return nil
+}
+
+// owner is the user_id column value: NULL for shared views.
+func owner(userID string, shared bool) *string {
+	if shared {
+		return nil
+	}
+	return &userID
+}
+
+func mapWriteErr(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrViewNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrViewExists
+		case "23514":
+			return ErrInvalidName
+		}
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package savedviews
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+)
+
+type Service struct{ repo *Repo }
+
+func NewService(repo *Repo) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) List(ctx context.Context, workspaceID, userID string) ([]View, error) {
+	return s.repo.List(ctx, workspaceID, userID)
+}
+
+func (s *Service) Get(ctx context.Context, workspaceID, userID, viewID string) (View, error) {
+	if _, err := uuid.Parse(viewID); err != nil {
+		return View{}, ErrViewNotFound
+	}
+	return s.repo.Get(ctx, workspaceID, userID, viewID)
+}
+
+// ViewParams implements transactions.ViewResolver.
+func (s *Service) ViewParams(ctx context.Context, workspaceID, userID, viewID string) (map[string]string, error) {
+	v, err := s.Get(ctx, workspaceID, userID, viewID)
+	if err != nil {
+		return nil, err
+	}
+	return v.Params(), nil
+}
+
+// Create saves a view. canShare says whether the caller may manage shared
+// views.
+func (s *Service) Create(ctx context.Context, workspaceID, userID string, in Input, canShare bool) (View, error) {
+	in, err := normalizeInput(in)
+	if err != nil {
+		return View{}, err
+	}
+	if in.Shared && !canShare {
+		return View{}, ErrSharedDenied
+	}
+	return s.repo.Create(ctx, workspaceID, userID, in)
+}
+
+// Update replaces a view. Changing a shared view, or sharing a personal one,
+// needs canShare.
+func (s *Service) Update(ctx context.Context, workspaceID, userID, viewID string, in Input, canShare bool) (View, error) {
+	in, err := normalizeInput(in)
+	if err != nil {
+		return View{}, err
+	}
+	existing, err := s.Get(ctx, workspaceID, userID, viewID)
+	if err != nil {
+		return View{}, err
+	}
+	if (existing.Shared || in.Shared) && !canShare {
+		return View{}, ErrSharedDenied
+	}
+	return s.repo.Update(ctx, workspaceID, userID, viewID, in)
+}
+
+func (s *Service) Delete(ctx context.Context, workspaceID, userID, viewID string, canShare bool) error {
+	existing, err := s.Get(ctx, workspaceID, userID, viewID)
+	if err != nil {
+		return err
+	}
+	if existing.Shared && !canShare {
+		return ErrSharedDenied
+	}
+	return s.repo.Delete(ctx, workspaceID, userID, viewID)
+}
+
+// normalizeInput trims the name, drops empty and false filters, spells
+// "search" as "q" and checks the filters parse the way a list request would
+// parse them.
+// A *transactions.FilterError reports a bad filter.
+func normalizeInput(in Input) (Input, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" || utf8.RuneCountInString(in.Name) > maxNameLen {
+		return Input{}, ErrInvalidName
+	}
+
+	in.Sort = strings.TrimSpace(in.Sort)
+	if in.Sort != "" && transactions.NormalizeSort(in.Sort) != in.Sort {
+		return Input{}, ErrInvalidSort
+	}
+
+	filters := make(map[string]string, len(in.Filters))
+	for k, v := range in.Filters {
+		if k == "search" {
+			k = "q"
+		}
+		if !slices.Contains(transactions.FilterKeys, k) {
+			return Input{}, &transactions.FilterError{Msg: "unknown filter " + k, Field: k, Hint: "not a transactions list filter"}
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			filters[k] = v
+		}
+	}
+	f, ferr := transactions.ParseListFilter(func(k string) string { return filters[k] }, time.UTC)
+	if ferr != nil {
+		return Input{}, ferr
+	}
+	delete(filters, "uncategorized")
+	if f.Uncategorized {
+		filters["uncategorized"] = "true"
+	}
+	in.Filters = filters
+	return in, nil
+}
@@ -0,0 +1,67 @@
+package savedviews
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+)
+
+func TestNormalizeInput(t *testing.T) {
+	in, err := normalizeInput(Input{
+		Name: "  Groceries  ",
+		Filters: map[string]string{
+			"search":        "milk",
+			"type":          "expense",
+			"uncategorized": "false",
+			"currency":      " ",
+			"amount_min":    "1000",
+		},
+		Sort: "amount_desc",
+	})
+	if err != nil {
+		t.Fatalf("normalizeInput: %v", err)
+	}
+	if in.Name != "Groceries" {
+		t.Errorf("name = %q", in.Name)
+	}
+	want := map[string]string{"q": "milk", "type": "expense", "amount_min": "1000"}
+	if !reflect.DeepEqual(in.Filters, want) {
+		t.Errorf("filters = %v, want %v", in.Filters, want)
+	}
+
+	in, err = normalizeInput(Input{Name: "x", Filters: map[string]string{"uncategorized": "on"}})
+	if err != nil || in.Filters["uncategorized"] != "true" {
+		t.Errorf("uncategorized = %q, %v", in.Filters["uncategorized"], err)
+	}
+}
+
+func TestNormalizeInputRejects(t *testing.T) {
+	cases := []struct {
+		name string
+		in   Input
+		want error
+	}{
+		{"empty name", Input{Name: "  "}, ErrInvalidName},
+		{"long name", Input{Name: strings.Repeat("a", maxNameLen+1)}, ErrInvalidName},
+		{"bad sort", Input{Name: "x", Sort: "name"}, ErrInvalidSort},
+	}
+	for _, tc := range cases {
+		if _, err := normalizeInput(tc.in); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	for _, filters := range []map[string]string{
+		{"limit": "10"},
+		{"type": "transfer"},
+		{"amount_min": "5", "amount_max": "1"},
+	} {
+		var ferr *transactions.FilterError
+		if _, err := normalizeInput(Input{Name: "x", Filters: filters}); !errors.As(err, &ferr) {
+			t.Errorf("filters %v: err = %v, want FilterError", filters, err)
+		}
+	}
+}
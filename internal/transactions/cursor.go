@@ -0,0 +1,146 @@
+package transactions
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SortOccurredAtDesc = "occurred_at_desc"
+	SortOccurredAtAsc  = "occurred_at_asc"
+	SortAmountDesc     = "amount_desc"
+	SortAmountAsc      = "amount_asc"
+	// SortRelevance orders by search rank; without a search query it falls
+	// back to newest first.
+	SortRelevance = "relevance"
+)
+
+// NormalizeSort maps a sort parameter to one of the Sort constants, falling
+// back to newest first.
+func NormalizeSort(sort string) string {
+	switch s := strings.TrimSpace(sort); s {
+	case SortOccurredAtAsc, SortAmountDesc, SortAmountAsc, SortRelevance:
+		return s
+	default:
+		return SortOccurredAtDesc
+	}
+}
+
+// sortKey returns the column a sort orders by ("rank" being the search rank)
+// and whether it is descending. Ties are always broken by id in the same
+// direction.
+func sortKey(sort string, searching bool) (string, bool) {
+	switch NormalizeSort(sort) {
+	case SortRelevance:
+		if searching {
+			return "rank", true
+		}
+		return "occurred_at", true
+	case SortOccurredAtAsc:
+		return "occurred_at", false
+	case SortAmountDesc:
+		return "amount_minor", true
+	case SortAmountAsc:
+		return "amount_minor", false
+	default:
+		return "occurred_at", true
+	}
+}
+
+// listOrder returns the column a list query orders by and whether it runs
+// descending. A Before cursor flips the direction so the rows nearest to the
+// cursor come first.
+func listOrder(f ListFilter, searching bool) (string, bool) {
+	col, desc := sortKey(f.Sort, searching)
+	if f.Cursor != nil && f.Cursor.Before {
+		desc = !desc
+	}
+	return col, desc
+}
+
+// keysetCondition compares the (sort key, id) pair against the cursor values
+// bound at $argN and $argN+1, keeping the rows past the cursor in the given
+// direction.
+func keysetCondition(orderCol string, desc bool, argN int) string {
+	cmp := ">"
+	if desc {
+		cmp = "<"
+	}
+	return fmt.Sprintf("(%s, id) %s ($%d, $%d::uuid)", orderCol, cmp, argN, argN+1)
+}
+
+// keysetOrder orders by the sort key and then by id in the same direction,
+// matching keysetCondition.
+func keysetOrder(orderCol string, desc bool) string {
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s, id %s", orderCol, dir, dir)
+}
+
+// Cursor marks a position in a sorted transaction list: the sort key and id
+// of the row next to the page. Before pages towards the start of the list.
+type Cursor struct {
+	Sort        string    `json:"s"`
+	OccurredAt  time.Time `json:"t,omitempty"`
+	AmountMinor int64     `json:"a,omitempty"`
+	Rank        float64   `json:"r,omitempty"`
+	ID          string    `json:"id"`
+	Before      bool      `json:"b,omitempty"`
+}
+
+func cursorAt(t Transaction, f ListFilter, before bool) Cursor {
+	c := Cursor{Sort: NormalizeSort(f.Sort), ID: t.ID, Before: before}
+	switch col, _ := sortKey(f.Sort, f.tsQuery() != ""); col {
+	case "amount_minor":
+		c.AmountMinor = t.AmountMinor
+	case "rank":
+		if t.Search != nil {
+			c.Rank = t.Search.Rank
+		}
+	default:
+		c.OccurredAt = t.OccurredAt
+	}
+	return c
+}
+
+func (c Cursor) value(col string) any {
+	switch col {
+	case "amount_minor":
+		return c.AmountMinor
+	case "rank":
+		return c.Rank
+	default:
+		return c.OccurredAt
+	}
+}
+
+// Encode returns the cursor as an opaque URL-safe token.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a token made by Encode and checks that it was issued
+// for sort.
+func DecodeCursor(token, sort string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if _, err := NormalizeOptionalUUID(&c.ID); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if c.Sort != NormalizeSort(sort) {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
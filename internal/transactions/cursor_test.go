@@ -0,0 +1,142 @@
+package transactions
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	id1 = "00000000-0000-0000-0000-000000000001"
+	id2 = "00000000-0000-0000-0000-000000000002"
+	id3 = "00000000-0000-0000-0000-000000000003"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		sort string
+		in   Cursor
+	}{
+		{name: "date", sort: "", in: Cursor{Sort: SortOccurredAtDesc, OccurredAt: at, ID: id1}},
+		{name: "amount before", sort: SortAmountAsc, in: Cursor{Sort: SortAmountAsc, AmountMinor: -1250, ID: id2, Before: true}},
+		{name: "rank", sort: SortRelevance, in: Cursor{Sort: SortRelevance, Rank: 0.25, ID: id3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.in.Encode(), tt.sort)
+			if err != nil {
+				t.Fatalf("DecodeCursor: %v", err)
+			}
+			if !got.OccurredAt.Equal(tt.in.OccurredAt) {
+				t.Errorf("occurred_at = %v, want %v", got.OccurredAt, tt.in.OccurredAt)
+			}
+			got.OccurredAt = tt.in.OccurredAt
+			if got != tt.in {
+				t.Errorf("got %+v, want %+v", got, tt.in)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name  string
+		token string
+		sort  string
+	}{
+		{name: "not base64", token: "!!!", sort: ""},
+		{name: "not json", token: enc("nope"), sort: ""},
+		{name: "missing id", token: enc(`{"s":"occurred_at_desc"}`), sort: ""},
+		{name: "id not a uuid", token: enc(`{"s":"occurred_at_desc","id":"42"}`), sort: ""},
+		{name: "other sort", token: Cursor{Sort: SortAmountDesc, ID: id1}.Encode(), sort: SortAmountAsc},
+		{name: "issued for default sort", token: Cursor{Sort: SortOccurredAtDesc, ID: id1}.Encode(), sort: SortOccurredAtAsc},
+		{name: "unknown sort", token: Cursor{Sort: "bogus", ID: id1}.Encode(), sort: "bogus"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.token, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestListOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		f         ListFilter
+		searching bool
+		wantCol   string
+		wantDesc  bool
+	}{
+		{name: "default", f: ListFilter{}, wantCol: "occurred_at", wantDesc: true},
+		{name: "after cursor keeps direction", f: ListFilter{Sort: SortAmountAsc, Cursor: &Cursor{}}, wantCol: "amount_minor", wantDesc: false},
+		{name: "before cursor flips desc", f: ListFilter{Cursor: &Cursor{Before: true}}, wantCol: "occurred_at", wantDesc: false},
+		{name: "before cursor flips asc", f: ListFilter{Sort: SortAmountAsc, Cursor: &Cursor{Before: true}}, wantCol: "amount_minor", wantDesc: true},
+		{name: "relevance while searching", f: ListFilter{Sort: SortRelevance, Cursor: &Cursor{Before: true}}, searching: true, wantCol: "rank", wantDesc: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col, desc := listOrder(tt.f, tt.searching)
+			if col != tt.wantCol || desc != tt.wantDesc {
+				t.Errorf("listOrder = %q, %v; want %q, %v", col, desc, tt.wantCol, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	if got, want := keysetCondition("occurred_at", true, 4), "(occurred_at, id) < ($4, $5::uuid)"; got != want {
+		t.Errorf("desc condition = %q, want %q", got, want)
+	}
+	if got, want := keysetCondition("amount_minor", false, 2), "(amount_minor, id) > ($2, $3::uuid)"; got != want {
+		t.Errorf("asc condition = %q, want %q", got, want)
+	}
+	if got, want := keysetOrder("occurred_at", true), "occurred_at DESC, id DESC"; got != want {
+		t.Errorf("desc order = %q, want %q", got, want)
+	}
+	if got, want := keysetOrder("amount_minor", false), "amount_minor ASC, id ASC"; got != want {
+		t.Errorf("asc order = %q, want %q", got, want)
+	}
+}
+
+func TestBuildPageBefore(t *testing.T) {
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	// Newest-first list where id2 and id3 share a date, so the id breaks the
+	// tie. Paging back from a cursor, Repo.List returns the rows nearest to it
+	// first, i.e. ascending by (occurred_at, id), plus one extra row.
+	fetched := []Transaction{
+		{ID: id1, OccurredAt: day},
+		{ID: id2, OccurredAt: day.AddDate(0, 0, 1)},
+		{ID: id3, OccurredAt: day.AddDate(0, 0, 1)},
+	}
+	f := ListFilter{Cursor: &Cursor{Sort: SortOccurredAtDesc, Before: true}}
+
+	res := buildPage(fetched, f, 2, 0)
+
+	if len(res.Items) != 2 || res.Items[0].ID != id2 || res.Items[1].ID != id1 {
+		t.Fatalf("items = %+v, want [%s %s]", res.Items, id2, id1)
+	}
+	if !res.HasNext || !res.HasPrev {
+		t.Errorf("has_next = %v, has_prev = %v; want both", res.HasNext, res.HasPrev)
+	}
+	prev, err := DecodeCursor(res.PrevCursor, SortOccurredAtDesc)
+	if err != nil || prev.ID != id2 || !prev.Before || !prev.OccurredAt.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("prev cursor = %+v, %v", prev, err)
+	}
+	next, err := DecodeCursor(res.NextCursor, SortOccurredAtDesc)
+	if err != nil || next.ID != id1 || next.Before || !next.OccurredAt.Equal(day) {
+		t.Errorf("next cursor = %+v, %v", next, err)
+	}
+}
+
+func TestBuildPageFirstPage(t *testing.T) {
+	res := buildPage([]Transaction{{ID: id1}, {ID: id2}}, ListFilter{}, 2, 0)
+	if res.HasNext || res.HasPrev || res.NextCursor != "" || res.PrevCursor != "" {
+		t.Errorf("got %+v, want a single page without cursors", res)
+	}
+}
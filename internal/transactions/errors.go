@@ -3,8 +3,22 @@ package transactions
 import "errors"
 
 var (
-	ErrInvalidType     = errors.New("invalid type")
-	ErrInvalidCurrency = errors.New("invalid currency")
-	ErrInvalidAmount   = errors.New("invalid amount")
-	ErrInvalidRange    = errors.New("invalid date range")
+	ErrInvalidType      = errors.New("invalid type")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidRange     = errors.New("invalid date range")
+	ErrNotInTrash       = errors.New("transaction not in trash")
+	ErrInTrash          = errors.New("transaction is in the trash")
+	ErrVersionNotFound  = errors.New("transaction version not found")
+	ErrInvalidSplits    = errors.New("invalid splits")
+	ErrSplitCategory    = errors.New("split category not found in workspace")
+	ErrCategoryMissing  = errors.New("category not found in workspace")
+	ErrCategoryArchived = errors.New("category is archived")
+	ErrInvalidBulkOp    = errors.New("invalid bulk operation")
+	ErrBulkTooMany      = errors.New("too many transactions for one bulk operation")
+	ErrTxForbidden      = errors.New("your role does not allow changing this transaction")
+	ErrTxIsSplit        = errors.New("transaction is split across categories")
+	ErrTooManyTags      = errors.New("too many tags (max 10)")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrViewNotFound     = errors.New("saved view not found")
 )
@@ -0,0 +1,85 @@
+package transactions
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// exportPageSize is how many rows an export reads per query.
+const exportPageSize = 500
+
+var csvHeader = []string{"id", "date", "type", "amount", "currency", "category", "note", "tags", "created_by", "created_at"}
+
+// WriteCSV writes every transaction matching f, in f's sort order, as CSV.
+// Dates are shown in loc; split transactions list their line categories.
+func (s *Service) WriteCSV(ctx context.Context, workspaceID string, f ListFilter, loc *time.Location, w io.Writer) error {
+	names, err := s.repo.categoryNames(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	f.Offset = 0
+	f.Cursor = nil
+	f.Limit = exportPageSize
+	for {
+		items, err := s.repo.List(ctx, workspaceID, f)
+		if err != nil {
+			return err
+		}
+		for _, t := range items {
+			if err := cw.Write(csvRecord(t, names, loc)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		if len(items) < exportPageSize {
+			return nil
+		}
+		next := cursorAt(items[len(items)-1], f, false)
+		f.Cursor = &next
+	}
+}
+
+func csvRecord(t Transaction, names map[string]string, loc *time.Location) []string {
+	category := names[derefString(t.CategoryID)]
+	if len(t.Splits) > 0 {
+		parts := make([]string, 0, len(t.Splits))
+		for _, sp := range t.Splits {
+			parts = append(parts, fmt.Sprintf("%s %d.%02d", names[derefString(sp.CategoryID)], sp.AmountMinor/100, sp.AmountMinor%100))
+		}
+		category = strings.Join(parts, "; ")
+	}
+
+	return []string{
+		t.ID,
+		t.OccurredAt.In(loc).Format("2006-01-02"),
+		string(t.Type),
+		fmt.Sprintf("%d.%02d", t.AmountMinor/100, t.AmountMinor%100),
+		t.Currency,
+		csvText(category),
+		csvText(derefString(t.Note)),
+		csvText(strings.Join(t.Tags, ", ")),
+		t.UserID,
+		t.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+}
+
+// csvText keeps spreadsheet apps from evaluating user text as a formula.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
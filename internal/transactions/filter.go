@@ -0,0 +1,209 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterError describes the first invalid list filter parameter.
+type FilterError struct {
+	Msg   string
+	Field string
+	Hint  string
+}
+
+func (e *FilterError) Error() string { return e.Msg }
+
+// FilterKeys are the parameters ParseListFilter reads, spelled the canonical
+// way ("q" rather than its alias "search").
+var FilterKeys = []string{
+	"from", "to", "type", "category_id", "uncategorized", "amount_min", "amount_max", "currency",
+	"tags", "tags_match", "user_id", "created_from", "created_to", "updated_from", "updated_to", "q",
+}
+
+// ViewResolver looks up a saved view for a user: its FilterKeys values plus
+// its "sort". Views the user cannot see yield ErrViewNotFound.
+type ViewResolver interface {
+	ViewParams(ctx context.Context, workspaceID, userID, viewID string) (map[string]string, error)
+}
+
+// WithView looks parameters up through get and falls back to a saved
+// view's, so explicit parameters refine the view.
+func WithView(get func(string) string, params map[string]string) func(string) string {
+	return func(key string) string {
+		if v := get(key); v != "" {
+			return v
+		}
+		return params[key]
+	}
+}
+
+// ScopeQuery returns a subquery selecting the ids of live transactions that
+// match f in the workspace bound to $1, so other reports can be restricted
+// to a saved view. Its arguments are appended to args.
+func ScopeQuery(f ListFilter, args []any) (string, []any) {
+	var sb strings.Builder
+	sb.WriteString("SELECT id FROM transactions\nWHERE workspace_id = $1::uuid AND deleted_at IS NULL\n")
+	args = appendListFilter(&sb, args, f)
+	return sb.String(), args
+}
+
+// LineCategoryCondition returns the condition that keeps only the
+// transaction_lines rows, whose category column is col, matching f's category
+// filter, so a report over lines of a scope counts split transactions by
+// their matching lines only. It is empty when f does not filter by category.
+func LineCategoryCondition(f ListFilter, col string, args []any) (string, []any) {
+	switch {
+	case len(f.CategoryIDs) > 0 && f.Uncategorized:
+		args = append(args, f.CategoryIDs)
+		return fmt.Sprintf("(%s = ANY($%d::uuid[]) OR %s IS NULL)", col, len(args), col), args
+	case len(f.CategoryIDs) > 0:
+		args = append(args, f.CategoryIDs)
+		return fmt.Sprintf("%s = ANY($%d::uuid[])", col, len(args)), args
+	case f.Uncategorized:
+		return col + " IS NULL", args
+	}
+	return "", args
+}
+
+// ParseListFilter reads the list filter parameters through get, which looks
+// them up in a query string, a form or a JSON object. List parameters take
+// comma-separated values; amounts are in minor units.
+func ParseListFilter(get func(string) string, loc *time.Location) (ListFilter, *FilterError) {
+	var f ListFilter
+
+	var ferr *FilterError
+	parseTime := func(key string) *time.Time {
+		v := strings.TrimSpace(get(key))
+		if v == "" || ferr != nil {
+			return nil
+		}
+		t, err := ParseOccurredAtIn(v, loc)
+		if err != nil {
+			ferr = &FilterError{"invalid " + key, key, "YYYY-MM-DD or RFC3339"}
+			return nil
+		}
+		return &t
+	}
+	f.From = parseTime("from")
+	f.To = parseTime("to")
+	f.CreatedFrom = parseTime("created_from")
+	f.CreatedTo = parseTime("created_to")
+	f.UpdatedFrom = parseTime("updated_from")
+	f.UpdatedTo = parseTime("updated_to")
+	if ferr != nil {
+		return ListFilter{}, ferr
+	}
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		return ListFilter{}, &FilterError{"invalid range", "range", "from must be <= to"}
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return ListFilter{}, &FilterError{"invalid created range", "created_from", "created_from must be <= created_to"}
+	}
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && f.UpdatedFrom.After(*f.UpdatedTo) {
+		return ListFilter{}, &FilterError{"invalid updated range", "updated_from", "updated_from must be <= updated_to"}
+	}
+
+	if v := strings.TrimSpace(get("type")); v != "" {
+		typ := NormalizeType(v)
+		if !ValidateType(typ) {
+			return ListFilter{}, &FilterError{"invalid transaction type", "type", "income|expense"}
+		}
+		f.Type = &typ
+	}
+
+	for _, v := range splitList(get("category_id")) {
+		catID, err := NormalizeOptionalUUID(&v)
+		if err != nil {
+			return ListFilter{}, &FilterError{"invalid category_id", "category_id", "uuid or comma-separated uuids"}
+		}
+		f.CategoryIDs = append(f.CategoryIDs, *catID)
+	}
+	if v := strings.TrimSpace(get("uncategorized")); v != "" {
+		b, ok := parseFlag(v)
+		if !ok {
+			return ListFilter{}, &FilterError{"invalid uncategorized", "uncategorized", "true|false"}
+		}
+		f.Uncategorized = b
+	}
+
+	for _, key := range []string{"amount_min", "amount_max"} {
+		v := strings.TrimSpace(get(key))
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return ListFilter{}, &FilterError{"invalid " + key, key, "amount in minor units, int >= 0"}
+		}
+		if key == "amount_min" {
+			f.AmountMin = &n
+		} else {
+			f.AmountMax = &n
+		}
+	}
+	if f.AmountMin != nil && f.AmountMax != nil && *f.AmountMin > *f.AmountMax {
+		return ListFilter{}, &FilterError{"invalid amount range", "amount_min", "amount_min must be <= amount_max"}
+	}
+
+	if v := strings.TrimSpace(get("currency")); v != "" {
+		cur, err := NormalizeCurrencyStrict(strings.ToUpper(v))
+		if err != nil {
+			return ListFilter{}, &FilterError{"invalid currency", "currency", "ISO 4217 like UAH, USD"}
+		}
+		f.Currency = &cur
+	}
+
+	if v := strings.TrimSpace(get("tags")); v != "" {
+		tags, err := ParseTagsCSV(v)
+		if err != nil {
+			return ListFilter{}, &FilterError{"invalid tags", "tags", err.Error()}
+		}
+		f.Tags = tags
+	}
+	switch strings.TrimSpace(get("tags_match")) {
+	case "", "any":
+	case "all":
+		f.TagsMatchAll = true
+	default:
+		return ListFilter{}, &FilterError{"invalid tags_match", "tags_match", "any|all"}
+	}
+
+	if v := strings.TrimSpace(get("user_id")); v != "" {
+		userID, err := NormalizeOptionalUUID(&v)
+		if err != nil {
+			return ListFilter{}, &FilterError{"invalid user_id", "user_id", "must be uuid"}
+		}
+		f.UserID = userID
+	}
+
+	if v := strings.TrimSpace(get("q")); v != "" {
+		f.Search = &v
+	} else if v := strings.TrimSpace(get("search")); v != "" {
+		f.Search = &v
+	}
+	return f, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// parseFlag accepts the usual boolean spellings plus "on", which is what an
+// HTML checkbox submits.
+func parseFlag(s string) (bool, bool) {
+	if s == "on" {
+		return true, true
+	}
+	b, err := strconv.ParseBool(s)
+	return b, err == nil
+}
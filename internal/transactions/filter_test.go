@@ -0,0 +1,90 @@
+package transactions
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseListFilter(t *testing.T) {
+	kyiv := time.FixedZone("EET", 2*60*60)
+	i64 := func(n int64) *int64 { return &n }
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name  string
+		query map[string]string
+		want  ListFilter
+		field string // non-empty when a FilterError is expected
+	}{
+		{name: "empty", query: nil, want: ListFilter{}},
+		{name: "amount range", query: map[string]string{"amount_min": "100", "amount_max": "500"}, want: ListFilter{AmountMin: i64(100), AmountMax: i64(500)}},
+		{name: "amount min only", query: map[string]string{"amount_min": " 0 "}, want: ListFilter{AmountMin: i64(0)}},
+		{name: "equal amounts", query: map[string]string{"amount_min": "300", "amount_max": "300"}, want: ListFilter{AmountMin: i64(300), AmountMax: i64(300)}},
+		{name: "amount min above max", query: map[string]string{"amount_min": "501", "amount_max": "500"}, field: "amount_min"},
+		{name: "negative amount", query: map[string]string{"amount_max": "-1"}, field: "amount_max"},
+		{name: "non-numeric amount", query: map[string]string{"amount_min": "1.5"}, field: "amount_min"},
+		{
+			name:  "categories with uncategorized",
+			query: map[string]string{"category_id": id1 + ", ," + id2, "uncategorized": "on"},
+			want:  ListFilter{CategoryIDs: []string{id1, id2}, Uncategorized: true},
+		},
+		{name: "uncategorized only", query: map[string]string{"uncategorized": "true"}, want: ListFilter{Uncategorized: true}},
+		{name: "uncategorized off", query: map[string]string{"category_id": id1, "uncategorized": "false"}, want: ListFilter{CategoryIDs: []string{id1}}},
+		{name: "bad category in list", query: map[string]string{"category_id": id1 + ",food"}, field: "category_id"},
+		{name: "bad uncategorized", query: map[string]string{"uncategorized": "maybe"}, field: "uncategorized"},
+		{name: "tags any", query: map[string]string{"tags": "Food, trip,food"}, want: ListFilter{Tags: []string{"food", "trip"}}},
+		{name: "tags all", query: map[string]string{"tags": "food", "tags_match": "all"}, want: ListFilter{Tags: []string{"food"}, TagsMatchAll: true}},
+		{name: "bad tags_match", query: map[string]string{"tags": "food", "tags_match": "some"}, field: "tags_match"},
+		{name: "tag too long", query: map[string]string{"tags": "abcdefghijklmnopqrstuvwxyz0123456"}, field: "tags"},
+		{name: "member", query: map[string]string{"user_id": " " + id3 + " "}, want: ListFilter{UserID: str(id3)}},
+		{name: "bad member", query: map[string]string{"user_id": "me"}, field: "user_id"},
+		{name: "currency upper-cased", query: map[string]string{"currency": "usd"}, want: ListFilter{Currency: str("USD")}},
+		{name: "bad currency", query: map[string]string{"currency": "dollars"}, field: "currency"},
+		{
+			name:  "dates in location",
+			query: map[string]string{"from": "2024-03-01", "to": "2024-03-31T23:59:59Z"},
+			want: ListFilter{
+				From: timePtr(time.Date(2024, 3, 1, 0, 0, 0, 0, kyiv)),
+				To:   timePtr(time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)),
+			},
+		},
+		{name: "bad from", query: map[string]string{"from": "01.03.2024"}, field: "from"},
+		{name: "bad to", query: map[string]string{"to": "2024-13-01"}, field: "to"},
+		{name: "bad created_from", query: map[string]string{"created_from": "yesterday"}, field: "created_from"},
+		{name: "bad updated_to", query: map[string]string{"updated_to": "2024-03-01T25:00:00Z"}, field: "updated_to"},
+		{name: "from after to", query: map[string]string{"from": "2024-03-02", "to": "2024-03-01"}, field: "range"},
+		{name: "created range reversed", query: map[string]string{"created_from": "2024-03-02", "created_to": "2024-03-01"}, field: "created_from"},
+		{name: "search alias", query: map[string]string{"search": " rent "}, want: ListFilter{Search: str("rent")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ferr := ParseListFilter(func(k string) string { return tt.query[k] }, kyiv)
+			if tt.field != "" {
+				if ferr == nil || ferr.Field != tt.field {
+					t.Fatalf("err = %+v, want error on %q", ferr, tt.field)
+				}
+				return
+			}
+			if ferr != nil {
+				t.Fatalf("ParseListFilter: %+v", ferr)
+			}
+			if !timesEqual(got.From, tt.want.From) || !timesEqual(got.To, tt.want.To) {
+				t.Errorf("range = %v..%v, want %v..%v", got.From, got.To, tt.want.From, tt.want.To)
+			}
+			got.From, got.To, tt.want.From, tt.want.To = nil, nil, nil, nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func timesEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -1,7 +1,10 @@
 package transactions
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/skelbigo/FinanceTracker/internal/auth"
 	"github.com/skelbigo/FinanceTracker/internal/httpx"
 	"github.com/skelbigo/FinanceTracker/internal/workspaces"
@@ -12,13 +15,14 @@ import (
 )
 
 type Handler struct {
-	svc *Service
-	mw  gin.HandlerFunc
-	ws  workspaces.RoleProvider
+	svc   *Service
+	mw    gin.HandlerFunc
+	ws    workspaces.RoleProvider
+	views ViewResolver
 }
 
-func NewHandler(svc *Service, authMW gin.HandlerFunc, ws workspaces.RoleProvider) *Handler {
-	return &Handler{svc: svc, mw: authMW, ws: ws}
+func NewHandler(svc *Service, authMW gin.HandlerFunc, ws workspaces.RoleProvider, views ViewResolver) *Handler {
+	return &Handler{svc: svc, mw: authMW, ws: ws, views: views}
 }
 
 func (h *Handler) RegisterRoutes(r gin.IRouter) {
@@ -26,8 +30,15 @@ func (h *Handler) RegisterRoutes(r gin.IRouter) {
 	g.Use(h.mw)
 
 	wsg := g.Group("/:id")
-	wsg.POST("/transactions", workspaces.RequireWorkspaceRole(h.ws, workspaces.RoleMember), h.create)
-	wsg.GET("/transactions", workspaces.RequireWorkspaceRole(h.ws, workspaces.RoleViewer), h.list)
+	wsg.POST("/transactions", workspaces.RequirePermission(h.ws, workspaces.PermTxWriteOwn), h.create)
+	wsg.GET("/transactions", workspaces.RequirePermission(h.ws, workspaces.PermTxRead), h.list)
+	wsg.POST("/transactions/bulk", workspaces.RequirePermission(h.ws, workspaces.PermTxWriteOwn), h.bulk)
+	wsg.GET("/transactions/export", workspaces.RequirePermission(h.ws, workspaces.PermExport), h.export)
+	wsg.GET("/transactions/trash", workspaces.RequirePermission(h.ws, workspaces.PermTxRead), h.trash)
+	wsg.POST("/transactions/:txId/restore", workspaces.RequirePermission(h.ws, workspaces.PermTxWriteOwn), h.restore)
+	wsg.PUT("/transactions/:txId/splits", workspaces.RequirePermission(h.ws, workspaces.PermTxWriteOwn), h.setSplits)
+	wsg.GET("/transactions/:txId/history", workspaces.RequirePermission(h.ws, workspaces.PermTxRead), h.history)
+	wsg.POST("/transactions/:txId/history/:version/revert", workspaces.RequirePermission(h.ws, workspaces.PermTxWriteOwn), h.revert)
 }
 
 type createTxReq struct {
@@ -38,6 +49,7 @@ type createTxReq struct {
 	Note        *string  `json:"note"`
 	CategoryID  *string  `json:"category_id"`
 	Tags        []string `json:"tags"`
+	Splits      []Split  `json:"splits"`
 }
 
 func UserIDFromCtx(c *gin.Context) (string, bool) {
@@ -84,7 +96,7 @@ func (h *Handler) create(c *gin.Context) {
 		return
 	}
 
-	occ, err := ParseOccurredAt(req.OccurredAt)
+	occ, err := ParseOccurredAtIn(req.OccurredAt, workspaces.GetLocation(c))
 	if err != nil {
 		httpx.Unprocessable(c, "invalid occurred at", map[string]string{"occurred_at": "YYYY-MM-DD or RFC3339"})
 		return
@@ -96,6 +108,29 @@ func (h *Handler) create(c *gin.Context) {
 		return
 	}
 
+	splits, single, err := NormalizeSplits(req.Splits, req.AmountMinor)
+	if err != nil {
+		httpx.Unprocessable(c, "invalid splits", map[string]string{"splits": err.Error()})
+		return
+	}
+	if single != nil {
+		catID = single.CategoryID
+		if req.Note == nil {
+			req.Note = single.Note
+		}
+	}
+
+	if access, ok := workspaces.GetAccess(c); ok {
+		if !access.CanUseTxType(string(typ)) {
+			httpx.Error(c, http.StatusForbidden, "transaction type not allowed for your role", map[string]string{"type": "restricted"})
+			return
+		}
+		if !CanUseCategories(access, typ, catID, splits) {
+			httpx.Error(c, http.StatusForbidden, "category not allowed for your role", map[string]string{"category_id": "restricted"})
+			return
+		}
+	}
+
 	note := NormalizeOptionalNote(req.Note)
 
 	tags, err := NormalizeTagsSlice(req.Tags)
@@ -114,10 +149,19 @@ func (h *Handler) create(c *gin.Context) {
 		OccurredAt:  occ,
 		Note:        note,
 		Tags:        tags,
+		Splits:      splits,
 	}
 
 	out, err := h.svc.Create(c.Request.Context(), tx)
 	if err != nil {
+		if errors.Is(err, ErrSplitCategory) {
+			httpx.Unprocessable(c, "invalid splits", map[string]string{"splits": err.Error()})
+			return
+		}
+		if errors.Is(err, ErrCategoryArchived) {
+			httpx.Unprocessable(c, "category is archived", map[string]string{"category_id": "unarchive the category or pick another"})
+			return
+		}
 		httpx.Internal(c)
 		log.Printf("transactions.create: %v", err)
 		return
@@ -133,92 +177,517 @@ func (h *Handler) list(c *gin.Context) {
 		return
 	}
 
-	var f ListFilter
+	view, ok := ResolveView(c, h.views)
+	if !ok {
+		return
+	}
+	get := WithView(queryGetter(c), view)
+
+	f, ferr := ParseListFilter(get, workspaces.GetLocation(c))
+	if ferr != nil {
+		httpx.Unprocessable(c, ferr.Msg, map[string]string{ferr.Field: ferr.Hint})
+		return
+	}
 
-	if v := strings.TrimSpace(c.Query("from")); v != "" {
-		t, err := ParseOccurredAt(v)
-		if err != nil {
-			httpx.Unprocessable(c, "invalid from", map[string]string{"from": "YYYY-MM-DD or RFC3339"})
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			httpx.Unprocessable(c, "invalid limit", map[string]string{"limit": "must be positive int"})
+			return
+		}
+		f.Limit = n
+	} else if v := strings.TrimSpace(c.Query("page_size")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			httpx.Unprocessable(c, "invalid page_size", map[string]string{"page_size": "must be positive int"})
+			return
+		}
+		f.Limit = n
+	}
+
+	if v := strings.TrimSpace(c.Query("offset")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			httpx.Unprocessable(c, "invalid offset", map[string]string{"offset": "must be >= 0"})
 			return
 		}
-		f.From = &t
+		f.Offset = n
+	}
+
+	f.Sort = NormalizeSort(get("sort"))
+	if strings.TrimSpace(get("sort")) == "" && f.tsQuery() != "" {
+		f.Sort = SortRelevance
 	}
-	if v := strings.TrimSpace(c.Query("to")); v != "" {
-		t, err := ParseOccurredAt(v)
+
+	if v := strings.TrimSpace(c.Query("cursor")); v != "" {
+		cur, err := DecodeCursor(v, f.Sort)
 		if err != nil {
-			httpx.Unprocessable(c, "invalid to", map[string]string{"to": "YYYY-MM-DD or RFC3339"})
+			httpx.Unprocessable(c, "invalid cursor", map[string]string{"cursor": "use next_cursor or prev_cursor from a response with the same sort"})
 			return
 		}
-		f.To = &t
+		f.Cursor = &cur
+	}
+
+	res, err := h.svc.List(c.Request.Context(), workspaceID, f)
+	if err != nil {
+		httpx.Internal(c)
+		log.Printf("transactions.list: %v", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items":       res.Items,
+		"has_next":    res.HasNext,
+		"has_prev":    res.HasPrev,
+		"limit":       res.Limit,
+		"offset":      res.Offset,
+		"next_cursor": res.NextCursor,
+		"prev_cursor": res.PrevCursor,
+	})
+}
+
+func (h *Handler) export(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	view, ok := ResolveView(c, h.views)
+	if !ok {
+		return
+	}
+	get := WithView(queryGetter(c), view)
+
+	f, ferr := ParseListFilter(get, workspaces.GetLocation(c))
+	if ferr != nil {
+		httpx.Unprocessable(c, ferr.Msg, map[string]string{ferr.Field: ferr.Hint})
+		return
+	}
+	f.Sort = NormalizeSort(get("sort"))
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="transactions.csv"`)
+	if err := h.svc.WriteCSV(c.Request.Context(), workspaceID, f, workspaces.GetLocation(c), c.Writer); err != nil {
+		if !c.Writer.Written() {
+			httpx.Internal(c)
+		}
+		log.Printf("transactions.export: %v", err)
+	}
+}
+
+type bulkReq struct {
+	Action     string            `json:"action" binding:"required"`
+	IDs        []string          `json:"ids"`
+	Filter     map[string]string `json:"filter"`
+	CategoryID *string           `json:"category_id"`
+	Tags       []string          `json:"tags"`
+	OccurredAt string            `json:"occurred_at"`
+}
+
+func (h *Handler) bulk(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
 	}
-	if f.From != nil && f.To != nil && f.From.After(*f.To) {
-		httpx.Unprocessable(c, "invalid range", map[string]string{"range": "from must be <= to"})
+
+	userID, ok := UserIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	var req bulkReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
 		return
 	}
-	if v := strings.TrimSpace(c.Query("type")); v != "" {
-		typ := NormalizeType(v)
-		if !ValidateType(typ) {
-			httpx.Unprocessable(c, "invalid transaction type", map[string]string{"type": "income|expense"})
+
+	loc := workspaces.GetLocation(c)
+	sel := BulkSelection{IDs: req.IDs}
+	if req.Filter != nil {
+		f, ferr := ParseListFilter(func(k string) string { return req.Filter[k] }, loc)
+		if ferr != nil {
+			httpx.Unprocessable(c, ferr.Msg, map[string]string{"filter." + ferr.Field: ferr.Hint})
 			return
 		}
-		f.Type = &typ
+		sel.Filter = &f
 	}
 
-	if v := strings.TrimSpace(c.Query("category_id")); v != "" {
-		vv := v
-		catID, err := NormalizeOptionalUUID(&vv)
+	op := BulkOp{Action: BulkAction(strings.TrimSpace(req.Action)), CategoryID: req.CategoryID, Tags: req.Tags}
+	if op.Action == BulkSetDate {
+		occ, err := ParseOccurredAtIn(req.OccurredAt, loc)
 		if err != nil {
-			httpx.Unprocessable(c, "invalid category_id", map[string]string{"category_id": "must be uuid"})
+			httpx.Unprocessable(c, "invalid occurred at", map[string]string{"occurred_at": "YYYY-MM-DD or RFC3339"})
 			return
 		}
-		f.CategoryID = catID
+		op.OccurredAt = occ
+	}
+
+	access, hasAccess := workspaces.GetAccess(c)
+	if hasAccess && op.Action == BulkSetCategory {
+		catID, err := NormalizeOptionalUUID(op.CategoryID)
+		if err == nil && !access.CanUseCategory(catID) {
+			httpx.Error(c, http.StatusForbidden, "category not allowed for your role", map[string]string{"category_id": "restricted"})
+			return
+		}
+	}
+	check := func(t Transaction) error {
+		if hasAccess && (!access.CanWriteTx(userID, t.UserID) || !CanUseCategories(access, t.Type, t.CategoryID, t.Splits)) {
+			return ErrTxForbidden
+		}
+		return nil
+	}
+
+	res, err := h.svc.Bulk(c.Request.Context(), userID, workspaceID, sel, op, check)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidBulkOp):
+			httpx.Unprocessable(c, "invalid bulk operation", map[string]string{"bulk": err.Error()})
+		case errors.Is(err, ErrBulkTooMany):
+			httpx.Unprocessable(c, "too many transactions", map[string]string{"ids": fmt.Sprintf("at most %d per request; narrow the filter", MaxBulkItems)})
+		case errors.Is(err, ErrCategoryMissing):
+			httpx.Unprocessable(c, "invalid category_id", map[string]string{"category_id": err.Error()})
+		case errors.Is(err, ErrCategoryArchived):
+			httpx.Unprocessable(c, "category is archived", map[string]string{"category_id": "unarchive the category or pick another"})
+		default:
+			httpx.Internal(c)
+			log.Printf("transactions.bulk: %v", err)
+		}
+		return
 	}
 
-	if v := strings.TrimSpace(c.Query("q")); v != "" {
-		f.Search = &v
-	} else if v := strings.TrimSpace(c.Query("search")); v != "" {
-		f.Search = &v
+	c.JSON(http.StatusOK, gin.H{"result": res})
+}
+
+func (h *Handler) trash(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
 	}
 
+	limit, offset := 0, 0
 	if v := strings.TrimSpace(c.Query("limit")); v != "" {
 		n, err := strconv.Atoi(v)
 		if err != nil || n <= 0 {
 			httpx.Unprocessable(c, "invalid limit", map[string]string{"limit": "must be positive int"})
 			return
 		}
-		f.Limit = n
-	} else if v := strings.TrimSpace(c.Query("page_size")); v != "" {
-		n, err := strconv.Atoi(v)
-		if err != nil || n <= 0 {
-			httpx.Unprocessable(c, "invalid page_size", map[string]string{"page_size": "must be positive int"})
-			return
-		}
-		f.Limit = n
+		limit = n
 	}
-
 	if v := strings.TrimSpace(c.Query("offset")); v != "" {
 		n, err := strconv.Atoi(v)
 		if err != nil || n < 0 {
 			httpx.Unprocessable(c, "invalid offset", map[string]string{"offset": "must be >= 0"})
 			return
 		}
-		f.Offset = n
+		offset = n
 	}
 
-	if v := strings.TrimSpace(c.Query("sort")); v != "" {
-		f.Sort = v
-	}
-
-	res, err := h.svc.List(c.Request.Context(), workspaceID, f)
+	res, err := h.svc.ListDeleted(c.Request.Context(), workspaceID, limit, offset)
 	if err != nil {
 		httpx.Internal(c)
-		log.Printf("transactions.list: %v", err)
+		log.Printf("transactions.trash: %v", err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"items":    res.Items,
-		"has_next": res.HasNext,
-		"limit":    res.Limit,
-		"offset":   res.Offset,
+		"items":          res.Items,
+		"has_next":       res.HasNext,
+		"limit":          res.Limit,
+		"offset":         res.Offset,
+		"retention_days": int(h.svc.TrashRetention().Hours() / 24),
 	})
 }
+
+func (h *Handler) restore(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	userID, ok := UserIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	txID, ok := txIDParam(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.svc.GetDeletedByID(c.Request.Context(), workspaceID, txID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(c, http.StatusNotFound, "transaction not found in trash", nil)
+			return
+		}
+		httpx.Internal(c)
+		log.Printf("transactions.restore: %v", err)
+		return
+	}
+
+	if access, ok := workspaces.GetAccess(c); ok && (!access.CanWriteTx(userID, tx.UserID) || !CanUseCategories(access, tx.Type, tx.CategoryID, tx.Splits)) {
+		httpx.Error(c, http.StatusForbidden, "your role does not allow restoring this transaction", nil)
+		return
+	}
+
+	out, err := h.svc.Restore(c.Request.Context(), userID, workspaceID, txID)
+	if err != nil {
+		writeRestoreErr(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transaction": out})
+}
+
+// writeRestoreErr maps a failed restore. The transaction was in the trash
+// when the request was checked, so ErrNotInTrash means a concurrent restore
+// or purge got there first.
+func writeRestoreErr(c *gin.Context, err error) {
+	if errors.Is(err, ErrNotInTrash) {
+		httpx.Conflict(c, "transaction was already restored or purged")
+		return
+	}
+	httpx.Internal(c)
+	log.Printf("transactions.restore: %v", err)
+}
+
+func (h *Handler) history(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	txID, ok := txIDParam(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.svc.History(c.Request.Context(), workspaceID, txID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(c, http.StatusNotFound, "transaction not found", nil)
+			return
+		}
+		httpx.Internal(c)
+		log.Printf("transactions.history: %v", err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) revert(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	userID, ok := UserIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	txID, ok := txIDParam(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		httpx.BadRequest(c, "invalid version", map[string]string{"version": "must be positive int"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	cur, err := h.svc.GetForRevert(ctx, workspaceID, txID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInTrash):
+			httpx.Conflict(c, "restore the transaction from the trash before reverting it")
+			return
+		case errors.Is(err, pgx.ErrNoRows):
+			httpx.Error(c, http.StatusNotFound, "transaction not found", nil)
+			return
+		}
+		httpx.Internal(c)
+		log.Printf("transactions.revert: %v", err)
+		return
+	}
+	v, err := h.svc.GetVersion(ctx, workspaceID, txID, version)
+	if err != nil {
+		if errors.Is(err, ErrVersionNotFound) {
+			httpx.Error(c, http.StatusNotFound, "version not found", nil)
+			return
+		}
+		httpx.Internal(c)
+		log.Printf("transactions.revert: %v", err)
+		return
+	}
+
+	if access, ok := workspaces.GetAccess(c); ok {
+		if !access.CanWriteTx(userID, cur.UserID) || !CanUseCategories(access, cur.Type, cur.CategoryID, cur.Splits) {
+			httpx.Error(c, http.StatusForbidden, "your role does not allow changing this transaction", nil)
+			return
+		}
+		if !CanUseCategories(access, v.Snapshot.Type, v.Snapshot.CategoryID, v.Snapshot.Splits) {
+			httpx.Error(c, http.StatusForbidden, "category not allowed for your role", map[string]string{"category_id": "restricted"})
+			return
+		}
+	}
+
+	out, err := h.svc.Revert(ctx, userID, workspaceID, txID, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrVersionNotFound), errors.Is(err, pgx.ErrNoRows):
+			httpx.Error(c, http.StatusNotFound, "version not found", nil)
+		case errors.Is(err, ErrInTrash):
+			httpx.Conflict(c, "restore the transaction from the trash before reverting it")
+		case errors.Is(err, ErrCategoryArchived):
+			httpx.Unprocessable(c, "category is archived", map[string]string{"category_id": "unarchive the category before reverting to this version"})
+		default:
+			httpx.Internal(c)
+			log.Printf("transactions.revert: %v", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transaction": out})
+}
+
+type setSplitsReq struct {
+	Splits []Split `json:"splits"`
+}
+
+func (h *Handler) setSplits(c *gin.Context) {
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+
+	userID, ok := UserIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	txID, ok := txIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req setSplitsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	ctx := c.Request.Context()
+	cur, err := h.svc.GetByID(ctx, workspaceID, txID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(c, http.StatusNotFound, "transaction not found", nil)
+			return
+		}
+		httpx.Internal(c)
+		log.Printf("transactions.setSplits: %v", err)
+		return
+	}
+
+	if access, ok := workspaces.GetAccess(c); ok {
+		if !access.CanWriteTx(userID, cur.UserID) || !CanUseCategories(access, cur.Type, cur.CategoryID, cur.Splits) {
+			httpx.Error(c, http.StatusForbidden, "your role does not allow changing this transaction", nil)
+			return
+		}
+		if !CanUseCategories(access, cur.Type, cur.CategoryID, req.Splits) {
+			httpx.Error(c, http.StatusForbidden, "category not allowed for your role", map[string]string{"splits": "restricted"})
+			return
+		}
+	}
+
+	out, err := h.svc.SetSplits(ctx, userID, workspaceID, txID, req.Splits)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidSplits), errors.Is(err, ErrSplitCategory):
+			httpx.Unprocessable(c, "invalid splits", map[string]string{"splits": err.Error()})
+		case errors.Is(err, ErrCategoryArchived):
+			httpx.Unprocessable(c, "category is archived", map[string]string{"splits": "unarchive the category or pick another"})
+		case errors.Is(err, pgx.ErrNoRows):
+			httpx.Error(c, http.StatusNotFound, "transaction not found", nil)
+		default:
+			httpx.Internal(c)
+			log.Printf("transactions.setSplits: %v", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transaction": out})
+}
+
+// CanUseCategories reports whether a role limited to certain categories or
+// transaction types may record a transaction of txType in the transaction
+// category and every split category.
+func CanUseCategories(access workspaces.Access, txType Type, categoryID *string, splits []Split) bool {
+	if !access.CanUseTxType(string(txType)) {
+		return false
+	}
+	if !access.CanUseCategory(categoryID) && len(splits) == 0 {
+		return false
+	}
+	for _, sp := range splits {
+		if !access.CanUseCategory(sp.CategoryID) {
+			return false
+		}
+	}
+	return true
+}
+
+// queryGetter looks up query parameters, joining repeated ones with commas
+// so list filters can be given either way.
+func queryGetter(c *gin.Context) func(string) string {
+	return func(key string) string {
+		return strings.Join(c.QueryArray(key), ",")
+	}
+}
+
+// ResolveView loads the saved view named by the view_id query parameter,
+// writing the error response when that fails. Without view_id it returns
+// nil parameters.
+func ResolveView(c *gin.Context, views ViewResolver) (map[string]string, bool) {
+	viewID := strings.TrimSpace(c.Query("view_id"))
+	if viewID == "" {
+		return nil, true
+	}
+	workspaceID, ok := workspaces.GetWorkspaceID(c)
+	if !ok || views == nil {
+		httpx.Internal(c)
+		return nil, false
+	}
+
+	params, err := views.ViewParams(c.Request.Context(), workspaceID, c.GetString(auth.CtxUserIDKey), viewID)
+	if err != nil {
+		if errors.Is(err, ErrViewNotFound) {
+			httpx.Error(c, http.StatusNotFound, "saved view not found", nil)
+		} else {
+			httpx.Internal(c)
+			log.Printf("transactions.view: %v", err)
+		}
+		return nil, false
+	}
+	return params, true
+}
+
+func txIDParam(c *gin.Context) (string, bool) {
+	txID := strings.TrimSpace(c.Param("txId"))
+	if _, err := NormalizeOptionalUUID(&txID); err != nil || txID == "" {
+		httpx.BadRequest(c, "invalid transaction id", map[string]string{"txId": "must be uuid"})
+		return "", false
+	}
+	return txID, true
+}
@@ -0,0 +1,68 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+func TestWriteRestoreErr(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "restored or purged concurrently", err: ErrNotInTrash, want: http.StatusConflict},
+		{name: "wrapped conflict", err: fmt.Errorf("restore: %w", ErrNotInTrash), want: http.StatusConflict},
+		{name: "other failure", err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			writeRestoreErr(c, tt.err)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanUseCategories(t *testing.T) {
+	food, rent := "food", "rent"
+	limited := workspaces.Access{CategoryIDs: []string{food}}
+	expenses := workspaces.Access{TxTypes: []string{"expense"}}
+
+	tests := []struct {
+		name   string
+		access workspaces.Access
+		typ    Type
+		cat    *string
+		splits []Split
+		want   bool
+	}{
+		{name: "unrestricted", access: workspaces.Access{}, typ: TypeIncome, cat: &rent, want: true},
+		{name: "allowed category", access: limited, typ: TypeExpense, cat: &food, want: true},
+		{name: "other category", access: limited, typ: TypeExpense, cat: &rent, want: false},
+		{name: "uncategorized", access: limited, typ: TypeExpense, want: false},
+		{name: "split on allowed lines", access: limited, typ: TypeExpense, splits: []Split{{CategoryID: &food}, {CategoryID: &food}}, want: true},
+		{name: "split with another line", access: limited, typ: TypeExpense, splits: []Split{{CategoryID: &food}, {CategoryID: &rent}}, want: false},
+		{name: "allowed type", access: expenses, typ: TypeExpense, cat: &rent, want: true},
+		{name: "other type", access: expenses, typ: TypeIncome, cat: &rent, want: false},
+		{name: "other type in an allowed category", access: workspaces.Access{CategoryIDs: []string{food}, TxTypes: []string{"expense"}}, typ: TypeIncome, cat: &food, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanUseCategories(tt.access, tt.typ, tt.cat, tt.splits); got != tt.want {
+				t.Errorf("CanUseCategories = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
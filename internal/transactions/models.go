@@ -1,6 +1,9 @@
 package transactions
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type Type string
 
@@ -13,9 +16,34 @@ const (
 )
 
 type Transaction struct {
-	ID          string    `json:"id"`
-	WorkspaceID string    `json:"workspace_id"`
-	UserID      string    `json:"user_id"`
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspace_id"`
+	UserID      string     `json:"user_id"`
+	CategoryID  *string    `json:"category_id"`
+	Type        Type       `json:"type"`
+	AmountMinor int64      `json:"amount_minor"`
+	Currency    string     `json:"currency"`
+	OccurredAt  time.Time  `json:"occurred_at"`
+	Note        *string    `json:"note,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Splits      []Split    `json:"splits,omitempty"`
+	Search      *SearchHit `json:"search,omitempty"`
+}
+
+// Split is one line item of a transaction spread over several categories.
+// The amounts of all lines add up to the transaction's AmountMinor.
+type Split struct {
+	CategoryID  *string `json:"category_id"`
+	AmountMinor int64   `json:"amount_minor"`
+	Note        *string `json:"note,omitempty"`
+}
+
+// Snapshot holds the editable fields of a transaction as they were at some
+// point in its history.
+type Snapshot struct {
 	CategoryID  *string   `json:"category_id"`
 	Type        Type      `json:"type"`
 	AmountMinor int64     `json:"amount_minor"`
@@ -23,6 +51,153 @@ type Transaction struct {
 	OccurredAt  time.Time `json:"occurred_at"`
 	Note        *string   `json:"note,omitempty"`
 	Tags        []string  `json:"tags,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Splits      []Split   `json:"splits,omitempty"`
+}
+
+func (t Transaction) Snapshot() Snapshot {
+	return Snapshot{
+		CategoryID:  t.CategoryID,
+		Type:        t.Type,
+		AmountMinor: t.AmountMinor,
+		Currency:    t.Currency,
+		OccurredAt:  t.OccurredAt,
+		Note:        t.Note,
+		Tags:        t.Tags,
+		Splits:      t.Splits,
+	}
+}
+
+// Apply returns t with its editable fields replaced by those of s.
+func (s Snapshot) Apply(t Transaction) Transaction {
+	t.CategoryID = s.CategoryID
+	t.Type = s.Type
+	t.AmountMinor = s.AmountMinor
+	t.Currency = s.Currency
+	t.OccurredAt = s.OccurredAt
+	t.Note = s.Note
+	t.Tags = s.Tags
+	t.Splits = s.Splits
+	return t
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Version is a previous state of a transaction. Changes lists what the
+// update that replaced it changed.
+type Version struct {
+	Version    int           `json:"version"`
+	Snapshot   Snapshot      `json:"snapshot"`
+	ReplacedBy *string       `json:"replaced_by"`
+	ReplacedAt time.Time     `json:"replaced_at"`
+	Changes    []FieldChange `json:"changes"`
+}
+
+type History struct {
+	Current  Transaction `json:"current"`
+	Versions []Version   `json:"versions"`
+}
+
+type BulkAction string
+
+const (
+	BulkSetCategory BulkAction = "set_category"
+	BulkAddTags     BulkAction = "add_tags"
+	BulkRemoveTags  BulkAction = "remove_tags"
+	BulkSetDate     BulkAction = "set_date"
+	BulkDelete      BulkAction = "delete"
+)
+
+// MaxBulkItems caps how many transactions one bulk operation may touch.
+const MaxBulkItems = 1000
+
+// BulkOp is one change applied to many transactions at once. Only the field
+// its action needs is read.
+type BulkOp struct {
+	Action     BulkAction
+	CategoryID *string
+	Tags       []string
+	OccurredAt time.Time
+}
+
+// BulkSelection picks the transactions of a bulk operation: either explicit
+// ids or everything matching Filter.
+type BulkSelection struct {
+	IDs    []string
+	Filter *ListFilter
+}
+
+type BulkStatus string
+
+const (
+	BulkUpdated   BulkStatus = "updated"
+	BulkDeleted   BulkStatus = "deleted"
+	BulkUnchanged BulkStatus = "unchanged"
+	BulkNotFound  BulkStatus = "not_found"
+	BulkFailed    BulkStatus = "failed"
+)
+
+type BulkItem struct {
+	ID     string     `json:"id"`
+	Status BulkStatus `json:"status"`
+	Error  string     `json:"error,omitempty"`
+}
+
+type BulkResult struct {
+	Matched   int        `json:"matched"`
+	Updated   int        `json:"updated"`
+	Deleted   int        `json:"deleted"`
+	Unchanged int        `json:"unchanged"`
+	Failed    int        `json:"failed"`
+	Items     []BulkItem `json:"items"`
+}
+
+func (r *BulkResult) add(id string, status BulkStatus, err error) {
+	item := BulkItem{ID: id, Status: status}
+	switch status {
+	case BulkUpdated:
+		r.Updated++
+	case BulkDeleted:
+		r.Deleted++
+	case BulkUnchanged:
+		r.Unchanged++
+	default:
+		r.Failed++
+	}
+	if err != nil {
+		item.Error = err.Error()
+	}
+	r.Items = append(r.Items, item)
+}
+
+// apply returns t with op's change made; delete is handled by the caller.
+func (op BulkOp) apply(t Transaction) (Transaction, error) {
+	switch op.Action {
+	case BulkSetCategory:
+		if len(t.Splits) > 0 {
+			return Transaction{}, ErrTxIsSplit
+		}
+		t.CategoryID = op.CategoryID
+	case BulkAddTags:
+		tags := append([]string{}, t.Tags...)
+		for _, tag := range op.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) > maxTags {
+			return Transaction{}, ErrTooManyTags
+		}
+		t.Tags = tags
+	case BulkRemoveTags:
+		t.Tags = slices.DeleteFunc(append([]string{}, t.Tags...), func(tag string) bool {
+			return slices.Contains(op.Tags, tag)
+		})
+	case BulkSetDate:
+		t.OccurredAt = op.OccurredAt
+	}
+	return t, nil
 }
@@ -0,0 +1,107 @@
+package transactions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBulkOpApply(t *testing.T) {
+	food := "food"
+	at := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	plain := Transaction{ID: "t1", AmountMinor: 1000, Tags: []string{"a", "b"}}
+	split := plain
+	split.Splits = []Split{{CategoryID: &food, AmountMinor: 600}, {AmountMinor: 400}}
+	full := plain
+	full.Tags = []string{"t0", "t1", "t2", "t3", "t4", "t5", "t6", "t7", "t8", "t9"}
+
+	tests := []struct {
+		name     string
+		op       BulkOp
+		in       Transaction
+		wantErr  error
+		wantCat  *string
+		wantTags []string
+		wantAt   time.Time
+	}{
+		{name: "set category", op: BulkOp{Action: BulkSetCategory, CategoryID: &food}, in: plain, wantCat: &food, wantTags: plain.Tags},
+		{name: "clear category", op: BulkOp{Action: BulkSetCategory}, in: plain, wantTags: plain.Tags},
+		{name: "set category on split", op: BulkOp{Action: BulkSetCategory, CategoryID: &food}, in: split, wantErr: ErrTxIsSplit},
+		{name: "add tags keeps order and skips present", op: BulkOp{Action: BulkAddTags, Tags: []string{"b", "c"}}, in: plain, wantTags: []string{"a", "b", "c"}},
+		{name: "add tags over limit", op: BulkOp{Action: BulkAddTags, Tags: []string{"extra"}}, in: full, wantErr: ErrTooManyTags},
+		{name: "add present tag at limit", op: BulkOp{Action: BulkAddTags, Tags: []string{"t0"}}, in: full, wantTags: full.Tags},
+		{name: "remove tags", op: BulkOp{Action: BulkRemoveTags, Tags: []string{"a", "z"}}, in: plain, wantTags: []string{"b"}},
+		{name: "set date", op: BulkOp{Action: BulkSetDate, OccurredAt: at}, in: plain, wantTags: plain.Tags, wantAt: at},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op.apply(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			if !reflect.DeepEqual(got.CategoryID, tt.wantCat) {
+				t.Errorf("category = %v, want %v", got.CategoryID, tt.wantCat)
+			}
+			if !reflect.DeepEqual(got.Tags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", got.Tags, tt.wantTags)
+			}
+			if !got.OccurredAt.Equal(tt.wantAt) {
+				t.Errorf("occurred_at = %v, want %v", got.OccurredAt, tt.wantAt)
+			}
+		})
+	}
+
+	// apply must not modify the caller's tag slice, which is still the
+	// "before" side of the audit record.
+	in := Transaction{Tags: []string{"a", "b"}}
+	if _, err := (BulkOp{Action: BulkRemoveTags, Tags: []string{"a"}}).apply(in); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in.Tags, []string{"a", "b"}) {
+		t.Errorf("input tags modified: %v", in.Tags)
+	}
+}
+
+func TestBulkResultAdd(t *testing.T) {
+	var r BulkResult
+	r.add("t1", BulkUpdated, nil)
+	r.add("t2", BulkDeleted, nil)
+	r.add("t3", BulkUnchanged, nil)
+	r.add("t4", BulkNotFound, nil)
+	r.add("t5", BulkFailed, ErrTxForbidden)
+
+	if r.Updated != 1 || r.Deleted != 1 || r.Unchanged != 1 || r.Failed != 2 {
+		t.Errorf("counts = %+v", r)
+	}
+	want := []BulkItem{
+		{ID: "t1", Status: BulkUpdated},
+		{ID: "t2", Status: BulkDeleted},
+		{ID: "t3", Status: BulkUnchanged},
+		{ID: "t4", Status: BulkNotFound},
+		{ID: "t5", Status: BulkFailed, Error: ErrTxForbidden.Error()},
+	}
+	if !reflect.DeepEqual(r.Items, want) {
+		t.Errorf("items = %+v, want %+v", r.Items, want)
+	}
+}
+
+func TestBulkIDs(t *testing.T) {
+	locked := []Transaction{{ID: "a"}, {ID: "b"}}
+
+	got := bulkIDs(BulkSelection{IDs: []string{"b", "x", "b", "a", "x"}}, locked)
+	if want := []string{"b", "x", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ids = %v, want %v", got, want)
+	}
+
+	got = bulkIDs(BulkSelection{Filter: &ListFilter{}}, locked)
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("filter ids = %v, want %v", got, want)
+	}
+}
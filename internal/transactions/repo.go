@@ -2,10 +2,17 @@ package transactions
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/skelbigo/FinanceTracker/internal/audit"
 )
 
 type Repo struct {
@@ -14,96 +21,266 @@ type Repo struct {
 
 func NewRepo(pool *pgxpool.Pool) *Repo { return &Repo{pool: pool} }
 
+const txColumns = `id::text, workspace_id::text, user_id::text, category_id::text, type, amount_minor, currency, occurred_at, note, tags,
+	created_at, updated_at, deleted_at,
+	COALESCE((
+		SELECT jsonb_agg(jsonb_build_object('category_id', s.category_id, 'amount_minor', s.amount_minor, 'note', s.note) ORDER BY s.position)
+		FROM transaction_splits s
+		WHERE s.transaction_id = transactions.id
+	), '[]'::jsonb)`
+
+// scanTx scans a row of txColumns, followed by any extra columns into extra.
+func scanTx(row pgx.Row, extra ...any) (Transaction, error) {
+	var out Transaction
+	var typ string
+	dest := append([]any{&out.ID, &out.WorkspaceID, &out.UserID, &out.CategoryID, &typ, &out.AmountMinor, &out.Currency,
+		&out.OccurredAt, &out.Note, &out.Tags, &out.CreatedAt, &out.UpdatedAt, &out.DeletedAt, &out.Splits}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return Transaction{}, err
+	}
+	out.Type = Type(typ)
+	if len(out.Splits) == 0 {
+		out.Splits = nil
+	}
+	return out, nil
+}
+
 func (r *Repo) Create(ctx context.Context, t Transaction) (Transaction, error) {
 	if t.Tags == nil {
 		t.Tags = []string{}
 	}
-	const q = `
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Transaction{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := rejectArchivedCategories(ctx, tx, t.WorkspaceID, categoryIDs(t)); err != nil {
+		return Transaction{}, err
+	}
+
+	out, err := scanTx(tx.QueryRow(ctx, `
 INSERT INTO transactions (workspace_id, user_id, category_id, type, amount_minor, currency, occurred_at, note, tags)
 VALUES ($1::uuid, $2::uuid, $3::uuid, $4, $5, $6, $7, $8, $9::text[])
-RETURNING id::text, workspace_id::text, user_id::text, category_id::text, type, amount_minor, currency, occurred_at, note, tags, created_at, updated_at
-`
-	var out Transaction
-	var typ string
+RETURNING `+txColumns, t.WorkspaceID, t.UserID, t.CategoryID, string(t.Type), t.AmountMinor, t.Currency,
+		t.OccurredAt, t.Note, t.Tags))
+	if err != nil {
+		return Transaction{}, err
+	}
 
-	err := r.pool.QueryRow(ctx, q, t.WorkspaceID, t.UserID, t.CategoryID, string(t.Type), t.AmountMinor, t.Currency,
-		t.OccurredAt, t.Note, t.Tags).Scan(&out.ID, &out.WorkspaceID, &out.UserID, &out.CategoryID, &typ, &out.AmountMinor,
-		&out.Currency, &out.OccurredAt, &out.Note, &out.Tags, &out.CreatedAt, &out.UpdatedAt)
+	if err := replaceSplits(ctx, tx, out.WorkspaceID, out.ID, t.Splits); err != nil {
+		return Transaction{}, err
+	}
+	out.Splits = t.Splits
 
-	if err != nil {
+	if err := audit.Record(ctx, tx, audit.Entry{
+		WorkspaceID: out.WorkspaceID,
+		ActorID:     t.UserID,
+		EntityType:  audit.EntityTransaction,
+		EntityID:    out.ID,
+		Action:      audit.ActionCreate,
+		After:       out,
+	}); err != nil {
+		return Transaction{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return Transaction{}, err
 	}
-	out.Type = Type(typ)
 	return out, nil
 }
 
-type ListFilter struct {
-	From       *time.Time
-	To         *time.Time
-	Type       *Type
-	CategoryID *string
-	Search     *string
-	Limit      int
-	Offset     int
-	Sort       string
-}
-
-func orderByFromSort(sort string) string {
-	switch strings.TrimSpace(sort) {
-	case "occurred_at_asc":
-		return "occurred_at ASC"
-	case "amount_desc":
-		return "amount_minor DESC"
-	case "amount_asc":
-		return "amount_minor ASC"
-	case "occurred_at_desc":
-		fallthrough
-	default:
-		return "occurred_at DESC"
+// rejectArchivedCategories keeps transactions from moving into archived
+// categories, which only remain so existing records can still show them.
+func rejectArchivedCategories(ctx context.Context, tx pgx.Tx, workspaceID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var archived bool
+	if err := tx.QueryRow(ctx, `
+SELECT EXISTS (
+	SELECT 1 FROM categories
+	WHERE workspace_id = $1::uuid AND id = ANY($2::uuid[]) AND archived_at IS NOT NULL
+)
+`, workspaceID, ids).Scan(&archived); err != nil {
+		return err
+	}
+	if archived {
+		return ErrCategoryArchived
 	}
+	return nil
 }
 
-func (r *Repo) List(ctx context.Context, workspaceID string, f ListFilter) ([]Transaction, error) {
-	orderBy := orderByFromSort(f.Sort)
+// categoryIDs lists the category of t and of each of its split lines.
+func categoryIDs(t Transaction) []string {
+	var ids []string
+	if t.CategoryID != nil {
+		ids = append(ids, *t.CategoryID)
+	}
+	for _, sp := range t.Splits {
+		if sp.CategoryID != nil {
+			ids = append(ids, *sp.CategoryID)
+		}
+	}
+	return ids
+}
 
-	args := []any{workspaceID}
-	argN := 2
+// addedCategoryIDs lists the categories t uses that before did not, so an
+// update can keep a transaction in a category archived since it was filed
+// there.
+func addedCategoryIDs(before, t Transaction) []string {
+	had := categoryIDs(before)
+	var ids []string
+	for _, id := range categoryIDs(t) {
+		if !slices.Contains(had, id) && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+type ListFilter struct {
+	From *time.Time
+	To   *time.Time
+	Type *Type
+	// CategoryIDs and Uncategorized match any line of a transaction, so a
+	// split transaction is found under each of its line categories.
+	CategoryIDs   []string
+	Uncategorized bool
+	AmountMin     *int64
+	AmountMax     *int64
+	Currency      *string
+	// Tags matches transactions carrying any of them, or all of them when
+	// TagsMatchAll is set.
+	Tags         []string
+	TagsMatchAll bool
+	UserID       *string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	UpdatedFrom  *time.Time
+	UpdatedTo    *time.Time
+	// Search is a full-text query in BuildTSQuery syntax.
+	Search *string
+	Limit  int
+	Offset int
+	Sort   string
+	// Cursor, when set, replaces Offset: the page starts right after (or,
+	// for a Before cursor, right before) the row it marks.
+	Cursor *Cursor
+}
+
+// appendListFilter writes the AND conditions for f to sb and returns args
+// extended with their values.
+func appendListFilter(sb *strings.Builder, args []any, f ListFilter) []any {
+	cond := func(format string, v any) {
+		args = append(args, v)
+		sb.WriteString(fmt.Sprintf(format, len(args)) + "\n")
+	}
 
-	var sb strings.Builder
-	sb.WriteString(`
-SELECT id::text, workspace_id::text, user_id::text, category_id::text, type, amount_minor, currency, occurred_at, note,
-	tags, created_at, updated_at
-FROM transactions
-WHERE workspace_id = $1::uuid
-`)
 	if f.From != nil {
-		sb.WriteString(fmt.Sprintf("AND occurred_at >= $%d::timestamptz\n", argN))
-		args = append(args, *f.From)
-		argN++
+		cond("AND occurred_at >= $%d::timestamptz", *f.From)
 	}
 	if f.To != nil {
-		sb.WriteString(fmt.Sprintf("AND occurred_at <= &%d::timestamptz\n", argN))
-		args = append(args, *f.To)
-		argN++
+		cond("AND occurred_at <= $%d::timestamptz", *f.To)
 	}
 	if f.Type != nil {
-		sb.WriteString(fmt.Sprintf("AND type = &%d::text\n", argN))
-		args = append(args, string(*f.Type))
-		argN++
+		cond("AND type = $%d::text", string(*f.Type))
 	}
-	if f.CategoryID != nil {
-		sb.WriteString(fmt.Sprintf("AND category_id = $%d::uuid\n", argN))
-		args = append(args, *f.CategoryID)
-		argN++
+
+	switch {
+	case len(f.CategoryIDs) > 0 && f.Uncategorized:
+		cond(`AND EXISTS (SELECT 1 FROM transaction_lines l WHERE l.transaction_id = transactions.id
+	AND (l.category_id = ANY($%d::uuid[]) OR l.category_id IS NULL))`, f.CategoryIDs)
+	case len(f.CategoryIDs) > 0:
+		cond(`AND EXISTS (SELECT 1 FROM transaction_lines l WHERE l.transaction_id = transactions.id
+	AND l.category_id = ANY($%d::uuid[]))`, f.CategoryIDs)
+	case f.Uncategorized:
+		sb.WriteString(`AND EXISTS (SELECT 1 FROM transaction_lines l WHERE l.transaction_id = transactions.id
+	AND l.category_id IS NULL)` + "\n")
 	}
-	if f.Search != nil {
-		q := strings.TrimSpace(*f.Search)
-		if q != "" {
-			sb.WriteString(fmt.Sprintf("AND (COALESCE(note, '') ILIKE '%%' || '%d' || '%%' OR $%d = ANY(tags))\n", argN, argN))
-			args = append(args, q)
-			argN++
+
+	if f.AmountMin != nil {
+		cond("AND amount_minor >= $%d", *f.AmountMin)
+	}
+	if f.AmountMax != nil {
+		cond("AND amount_minor <= $%d", *f.AmountMax)
+	}
+	if f.Currency != nil {
+		cond("AND currency = $%d", *f.Currency)
+	}
+	if len(f.Tags) > 0 {
+		if f.TagsMatchAll {
+			cond("AND tags @> $%d::text[]", f.Tags)
+		} else {
+			cond("AND tags && $%d::text[]", f.Tags)
 		}
 	}
+	if f.UserID != nil {
+		cond("AND user_id = $%d::uuid", *f.UserID)
+	}
+	if f.CreatedFrom != nil {
+		cond("AND created_at >= $%d::timestamptz", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		cond("AND created_at <= $%d::timestamptz", *f.CreatedTo)
+	}
+	if f.UpdatedFrom != nil {
+		cond("AND updated_at >= $%d::timestamptz", *f.UpdatedFrom)
+	}
+	if f.UpdatedTo != nil {
+		cond("AND updated_at <= $%d::timestamptz", *f.UpdatedTo)
+	}
+
+	if q := f.tsQuery(); q != "" {
+		cond("AND search_vector @@ to_tsquery('simple', $%d)", q)
+	}
+	return args
+}
+
+func (f ListFilter) tsQuery() string {
+	if f.Search == nil {
+		return ""
+	}
+	return BuildTSQuery(*f.Search)
+}
+
+// List returns a page of transactions. With a Before cursor the rows come
+// back in reverse sort order, nearest to the cursor first.
+func (r *Repo) List(ctx context.Context, workspaceID string, f ListFilter) ([]Transaction, error) {
+	tsq := f.tsQuery()
+	col, desc := listOrder(f, tsq != "")
+
+	args := []any{workspaceID}
+	columns := txColumns
+	orderCol := col
+	if tsq != "" {
+		args = append(args, tsq, headlineOptions)
+		columns += `,
+	ts_rank_cd(search_vector, to_tsquery('simple', $2))::float8,
+	ts_headline('simple', ` + headlineSource + `, to_tsquery('simple', $2), $3)`
+		if col == "rank" {
+			orderCol = "ts_rank_cd(search_vector, to_tsquery('simple', $2))::float8"
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`
+SELECT ` + columns + `
+FROM transactions
+WHERE workspace_id = $1::uuid
+  AND deleted_at IS NULL
+`)
+	args = appendListFilter(&sb, args, f)
+	argN := len(args) + 1
+
+	if f.Cursor != nil {
+		sb.WriteString("AND " + keysetCondition(orderCol, desc, argN) + "\n")
+		args = append(args, f.Cursor.value(col), f.Cursor.ID)
+		argN += 2
+		f.Offset = 0
+	}
 
 	if f.Limit <= 0 {
 		f.Limit = 50
@@ -112,7 +289,7 @@ WHERE workspace_id = $1::uuid
 		f.Offset = 0
 	}
 
-	sb.WriteString("ORDER BY " + orderBy + "\n")
+	sb.WriteString("ORDER BY " + keysetOrder(orderCol, desc) + "\n")
 	sb.WriteString(fmt.Sprintf("LIMIT $%d OFFSET $%d;\n", argN, argN+1))
 	args = append(args, f.Limit, f.Offset)
 
@@ -122,70 +299,534 @@ WHERE workspace_id = $1::uuid
 	}
 	defer rows.Close()
 
+	scan := func(row pgx.Row) (Transaction, error) { return scanTx(row) }
+	if tsq != "" {
+		scan = scanTxHit
+	}
+
 	var out []Transaction
 	for rows.Next() {
-		var t Transaction
-		var typ string
-		if err := rows.Scan(&t.ID, &t.WorkspaceID, &t.UserID, &t.CategoryID, &typ, &t.AmountMinor, &t.Currency,
-			&t.OccurredAt, &t.Note, &t.Tags, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		t, err := scan(rows)
+		if err != nil {
 			return nil, err
 		}
-		t.Type = Type(typ)
 		out = append(out, t)
 	}
 	return out, rows.Err()
 }
 
+// scanTxHit scans a row of txColumns followed by search rank and headline.
+func scanTxHit(row pgx.Row) (Transaction, error) {
+	hit := &SearchHit{}
+	var headline string
+	out, err := scanTx(row, &hit.Rank, &headline)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if out.Note != nil && strings.Contains(headline, hlStart) {
+		hit.Snippet = snippetHTML(headline)
+	}
+	out.Search = hit
+	return out, nil
+}
+
+// categoryNames maps the ids of the workspace's categories to their names.
+func (r *Repo) categoryNames(ctx context.Context, workspaceID string) (map[string]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id::text, name FROM categories WHERE workspace_id = $1::uuid`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]string{}
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		out[id] = name
+	}
+	return out, rows.Err()
+}
+
 func (r *Repo) GetByID(ctx context.Context, workspaceID, txID string) (Transaction, error) {
-	const q = `
-SELECT id::text, workspace_id::text, user_id::text, category_id::text, type, amount_minor, currency, occurred_at, note, tags,
-	created_at, updated_at
+	return scanTx(r.pool.QueryRow(ctx, `
+SELECT `+txColumns+`
 FROM transactions
-WHERE workspace_id = $1::uuid AND id = $2::uuid
+WHERE workspace_id = $1::uuid AND id = $2::uuid AND deleted_at IS NULL
 LIMIT 1;
-`
-	var out Transaction
-	var typ string
-	err := r.pool.QueryRow(ctx, q, workspaceID, txID).Scan(&out.ID, &out.WorkspaceID, &out.UserID, &out.CategoryID, &typ,
-		&out.AmountMinor, &out.Currency, &out.OccurredAt, &out.Note, &out.CreatedAt, &out.UpdatedAt)
+`, workspaceID, txID))
+}
+
+// GetDeletedByID returns a transaction that is currently in the trash.
+func (r *Repo) GetDeletedByID(ctx context.Context, workspaceID, txID string) (Transaction, error) {
+	return scanTx(r.pool.QueryRow(ctx, `
+SELECT `+txColumns+`
+FROM transactions
+WHERE workspace_id = $1::uuid AND id = $2::uuid AND deleted_at IS NOT NULL
+LIMIT 1;
+`, workspaceID, txID))
+}
+
+// ListDeleted returns trashed transactions, most recently deleted first.
+func (r *Repo) ListDeleted(ctx context.Context, workspaceID string, limit, offset int) ([]Transaction, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT `+txColumns+`
+FROM transactions
+WHERE workspace_id = $1::uuid AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+`, workspaceID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Transaction
+	for rows.Next() {
+		t, err := scanTx(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func lockTx(ctx context.Context, tx pgx.Tx, workspaceID, txID string, deleted bool) (Transaction, error) {
+	cond := "deleted_at IS NULL"
+	if deleted {
+		cond = "deleted_at IS NOT NULL"
+	}
+	return scanTx(tx.QueryRow(ctx, `
+SELECT `+txColumns+`
+FROM transactions
+WHERE workspace_id = $1::uuid AND id = $2::uuid AND `+cond+`
+FOR UPDATE
+`, workspaceID, txID))
+}
+
+func (r *Repo) Update(ctx context.Context, actorID string, t Transaction) (Transaction, error) {
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return Transaction{}, err
 	}
-	out.Type = Type(typ)
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := lockTx(ctx, tx, t.WorkspaceID, t.ID, false)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	out, err := updateLocked(ctx, tx, actorID, before, t)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Transaction{}, err
+	}
 	return out, nil
 }
 
-func (r *Repo) Update(ctx context.Context, t Transaction) (Transaction, error) {
+// updateLocked stores t over before, which the caller has locked, keeping
+// before as a history version.
+func updateLocked(ctx context.Context, tx pgx.Tx, actorID string, before, t Transaction) (Transaction, error) {
 	if t.Tags == nil {
 		t.Tags = []string{}
 	}
-	const q = `
+
+	if err := rejectArchivedCategories(ctx, tx, t.WorkspaceID, addedCategoryIDs(before, t)); err != nil {
+		return Transaction{}, err
+	}
+
+	if err := insertVersion(ctx, tx, before, actorID); err != nil {
+		return Transaction{}, err
+	}
+
+	out, err := scanTx(tx.QueryRow(ctx, `
 UPDATE transactions
 SET category_id=$3::uuid, type=$4, amount_minor=$5, currency=$6, occurred_at=$7, note=$8, tags=$9::text[], updated_at=now()
-WHERE workspace_id=$1::uuid AND id=$2::uuid
-RETURNING id::text, workspace_id::text, user_id::text, category_id::text, type, amount_minor, currency, occurred_at, note, tags,
-    created_at, updated_at;
-`
-	var out Transaction
-	var typ string
-	err := r.pool.QueryRow(ctx, q, t.WorkspaceID, t.ID, t.CategoryID, string(t.Type), t.AmountMinor, t.Currency, t.OccurredAt,
-		t.Note, t.Tags).Scan(&out.ID, &out.WorkspaceID, &out.UserID, &out.CategoryID, &typ, &out.AmountMinor, &out.Currency,
-		&out.OccurredAt, &out.Note, &out.Tags, &out.CreatedAt, &out.UpdatedAt)
+WHERE workspace_id=$1::uuid AND id=$2::uuid AND deleted_at IS NULL
+RETURNING `+txColumns, t.WorkspaceID, t.ID, t.CategoryID, string(t.Type), t.AmountMinor, t.Currency, t.OccurredAt,
+		t.Note, t.Tags))
 	if err != nil {
 		return Transaction{}, err
 	}
-	out.Type = Type(typ)
+
+	if err := replaceSplits(ctx, tx, out.WorkspaceID, out.ID, t.Splits); err != nil {
+		return Transaction{}, err
+	}
+	out.Splits = t.Splits
+
+	if err := audit.Record(ctx, tx, audit.Entry{
+		WorkspaceID: out.WorkspaceID,
+		ActorID:     actorID,
+		EntityType:  audit.EntityTransaction,
+		EntityID:    out.ID,
+		Action:      audit.ActionUpdate,
+		Before:      before,
+		After:       out,
+	}); err != nil {
+		return Transaction{}, err
+	}
 	return out, nil
 }
 
-func (r *Repo) Delete(ctx context.Context, workspaceID, txID string) (bool, error) {
-	const q = `
-DELETE FROM transactions
-WHERE workspace_id = $1::uuid AND id = $2::uuid
-`
-	ct, err := r.pool.Exec(ctx, q, workspaceID, txID)
+// Bulk applies op to the selected transactions in one database transaction.
+// check vets every locked transaction first; an error from it fails that item
+// and leaves it untouched. Items are reported in the order of sel.IDs, or by
+// id when selecting by filter.
+func (r *Repo) Bulk(ctx context.Context, actorID, workspaceID string, sel BulkSelection, op BulkOp, check func(Transaction) error) (BulkResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return BulkResult{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if op.Action == BulkSetCategory && op.CategoryID != nil {
+		var archived bool
+		err := tx.QueryRow(ctx, `
+SELECT archived_at IS NOT NULL FROM categories WHERE workspace_id = $1::uuid AND id = $2::uuid
+`, workspaceID, *op.CategoryID).Scan(&archived)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return BulkResult{}, ErrCategoryMissing
+		}
+		if err != nil {
+			return BulkResult{}, err
+		}
+		if archived {
+			return BulkResult{}, ErrCategoryArchived
+		}
+	}
+
+	locked, err := lockBulk(ctx, tx, workspaceID, sel)
+	if err != nil {
+		return BulkResult{}, err
+	}
+	if len(locked) > MaxBulkItems {
+		return BulkResult{}, ErrBulkTooMany
+	}
+
+	ids := bulkIDs(sel, locked)
+	byID := make(map[string]Transaction, len(locked))
+	for _, t := range locked {
+		byID[t.ID] = t
+	}
+
+	res := BulkResult{Items: make([]BulkItem, 0, len(ids))}
+	for _, id := range ids {
+		before, ok := byID[id]
+		if !ok {
+			res.add(id, BulkNotFound, nil)
+			continue
+		}
+		res.Matched++
+		if err := check(before); err != nil {
+			res.add(id, BulkFailed, err)
+			continue
+		}
+
+		if op.Action == BulkDelete {
+			if err := trashLocked(ctx, tx, actorID, before); err != nil {
+				return BulkResult{}, err
+			}
+			res.add(id, BulkDeleted, nil)
+			continue
+		}
+
+		after, err := op.apply(before)
+		if err != nil {
+			res.add(id, BulkFailed, err)
+			continue
+		}
+		if len(diffSnapshots(before.Snapshot(), after.Snapshot())) == 0 {
+			res.add(id, BulkUnchanged, nil)
+			continue
+		}
+		if _, err := updateLocked(ctx, tx, actorID, before, after); err != nil {
+			return BulkResult{}, err
+		}
+		res.add(id, BulkUpdated, nil)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return BulkResult{}, err
+	}
+	return res, nil
+}
+
+// bulkIDs lists the transactions a bulk operation reports on: the requested
+// ids in order with repeats dropped, so none is changed twice from a stale
+// copy, or every locked row for a filter selection.
+func bulkIDs(sel BulkSelection, locked []Transaction) []string {
+	if sel.Filter != nil {
+		ids := make([]string, 0, len(locked))
+		for _, t := range locked {
+			ids = append(ids, t.ID)
+		}
+		return ids
+	}
+	ids := make([]string, 0, len(sel.IDs))
+	for _, id := range sel.IDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// lockBulk locks the live transactions picked by sel in id order, so that
+// concurrent bulk operations cannot deadlock. A filter selection returns at
+// most MaxBulkItems+1 rows so the caller can tell when it matched too many.
+func lockBulk(ctx context.Context, tx pgx.Tx, workspaceID string, sel BulkSelection) ([]Transaction, error) {
+	args := []any{workspaceID}
+
+	var sb strings.Builder
+	sb.WriteString(`
+SELECT ` + txColumns + `
+FROM transactions
+WHERE workspace_id = $1::uuid
+  AND deleted_at IS NULL
+`)
+	if sel.Filter != nil {
+		args = appendListFilter(&sb, args, *sel.Filter)
+		sb.WriteString(fmt.Sprintf("ORDER BY id\nLIMIT %d\n", MaxBulkItems+1))
+	} else {
+		sb.WriteString("AND id = ANY($2::uuid[])\nORDER BY id\n")
+		args = append(args, sel.IDs)
+	}
+	sb.WriteString("FOR UPDATE")
+
+	rows, err := tx.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Transaction
+	for rows.Next() {
+		t, err := scanTx(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// replaceSplits swaps the line items of a transaction for splits, checking
+// that every split category belongs to the workspace.
+func replaceSplits(ctx context.Context, tx pgx.Tx, workspaceID, txID string, splits []Split) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM transaction_splits WHERE transaction_id = $1::uuid`, txID); err != nil {
+		return err
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var catIDs []string
+	for _, sp := range splits {
+		if sp.CategoryID != nil && !seen[*sp.CategoryID] {
+			seen[*sp.CategoryID] = true
+			catIDs = append(catIDs, *sp.CategoryID)
+		}
+	}
+	if len(catIDs) > 0 {
+		var n int
+		if err := tx.QueryRow(ctx, `
+SELECT COUNT(*) FROM categories WHERE workspace_id = $1::uuid AND id = ANY($2::uuid[])
+`, workspaceID, catIDs).Scan(&n); err != nil {
+			return err
+		}
+		if n != len(catIDs) {
+			return ErrSplitCategory
+		}
+	}
+
+	for i, sp := range splits {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO transaction_splits (transaction_id, position, category_id, amount_minor, note)
+VALUES ($1::uuid, $2, $3::uuid, $4, $5)
+`, txID, i+1, sp.CategoryID, sp.AmountMinor, sp.Note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertVersion stores prev as the next history version of its transaction.
+// The caller must hold the row lock on the transaction.
+func insertVersion(ctx context.Context, tx pgx.Tx, prev Transaction, actorID string) error {
+	snap, err := json.Marshal(prev.Snapshot())
+	if err != nil {
+		return fmt.Errorf("transaction snapshot: %w", err)
+	}
+
+	var actor *string
+	if actorID != "" {
+		actor = &actorID
+	}
+
+	_, err = tx.Exec(ctx, `
+INSERT INTO transaction_versions (transaction_id, version, snapshot, replaced_by)
+SELECT $1::uuid, COALESCE(MAX(version), 0) + 1, $2::jsonb, $3::uuid
+FROM transaction_versions
+WHERE transaction_id = $1::uuid
+`, prev.ID, string(snap), actor)
+	return err
+}
+
+const versionColumns = `v.version, v.snapshot, v.replaced_by::text, v.replaced_at`
+
+func scanVersion(row pgx.Row) (Version, error) {
+	var out Version
+	var snap []byte
+	if err := row.Scan(&out.Version, &snap, &out.ReplacedBy, &out.ReplacedAt); err != nil {
+		return Version{}, err
+	}
+	if err := json.Unmarshal(snap, &out.Snapshot); err != nil {
+		return Version{}, fmt.Errorf("transaction snapshot: %w", err)
+	}
+	return out, nil
+}
+
+// ListVersions returns the stored versions of a transaction, oldest first.
+func (r *Repo) ListVersions(ctx context.Context, workspaceID, txID string) ([]Version, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT `+versionColumns+`
+FROM transaction_versions v
+JOIN transactions t ON t.id = v.transaction_id
+WHERE t.workspace_id = $1::uuid AND v.transaction_id = $2::uuid
+ORDER BY v.version ASC;
+`, workspaceID, txID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Version
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) GetVersion(ctx context.Context, workspaceID, txID string, version int) (Version, error) {
+	v, err := scanVersion(r.pool.QueryRow(ctx, `
+SELECT `+versionColumns+`
+FROM transaction_versions v
+JOIN transactions t ON t.id = v.transaction_id
+WHERE t.workspace_id = $1::uuid AND v.transaction_id = $2::uuid AND v.version = $3;
+`, workspaceID, txID, version))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Version{}, ErrVersionNotFound
+	}
+	return v, err
+}
+
+// Delete moves a transaction to the trash. It stays restorable until the
+// retention purge removes it for good.
+func (r *Repo) Delete(ctx context.Context, actorID, workspaceID, txID string) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return false, err
 	}
-	return ct.RowsAffected() > 0, nil
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := lockTx(ctx, tx, workspaceID, txID, false)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := trashLocked(ctx, tx, actorID, before); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// trashLocked moves before, which the caller has locked, to the trash.
+func trashLocked(ctx context.Context, tx pgx.Tx, actorID string, before Transaction) error {
+	if _, err := tx.Exec(ctx, `
+UPDATE transactions
+SET deleted_at = now()
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+`, before.WorkspaceID, before.ID); err != nil {
+		return err
+	}
+
+	return audit.Record(ctx, tx, audit.Entry{
+		WorkspaceID: before.WorkspaceID,
+		ActorID:     actorID,
+		EntityType:  audit.EntityTransaction,
+		EntityID:    before.ID,
+		Action:      audit.ActionDelete,
+		Before:      before,
+	})
+}
+
+func (r *Repo) Restore(ctx context.Context, actorID, workspaceID, txID string) (Transaction, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Transaction{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := lockTx(ctx, tx, workspaceID, txID, true)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Transaction{}, ErrNotInTrash
+		}
+		return Transaction{}, err
+	}
+
+	out, err := scanTx(tx.QueryRow(ctx, `
+UPDATE transactions
+SET deleted_at = NULL
+WHERE workspace_id = $1::uuid AND id = $2::uuid
+RETURNING `+txColumns, workspaceID, txID))
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	if err := audit.Record(ctx, tx, audit.Entry{
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		EntityType:  audit.EntityTransaction,
+		EntityID:    txID,
+		Action:      audit.ActionRestore,
+		Before:      before,
+		After:       out,
+	}); err != nil {
+		return Transaction{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Transaction{}, err
+	}
+	return out, nil
+}
+
+// PurgeDeleted permanently removes transactions trashed before the cutoff.
+func (r *Repo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+DELETE FROM transactions
+WHERE deleted_at IS NOT NULL AND deleted_at < $1
+`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
 }
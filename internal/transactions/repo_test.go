@@ -0,0 +1,34 @@
+package transactions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddedCategoryIDs(t *testing.T) {
+	food, rent, fun := "food", "rent", "fun"
+	plain := Transaction{CategoryID: &food}
+	split := Transaction{Splits: []Split{{CategoryID: &food}, {CategoryID: &rent}, {}}}
+
+	tests := []struct {
+		name   string
+		before Transaction
+		after  Transaction
+		want   []string
+	}{
+		{name: "unchanged category", before: plain, after: plain},
+		{name: "category cleared", before: plain, after: Transaction{}},
+		{name: "new category", before: plain, after: Transaction{CategoryID: &rent}, want: []string{rent}},
+		{name: "split keeps old lines", before: split, after: Transaction{Splits: []Split{{CategoryID: &rent}, {CategoryID: &food}}}},
+		{name: "split adds a line category once", before: split, after: Transaction{Splits: []Split{{CategoryID: &fun}, {CategoryID: &fun}, {CategoryID: &food}}}, want: []string{fun}},
+		{name: "collapsing a split onto one of its lines", before: split, after: Transaction{CategoryID: &rent}},
+		{name: "from uncategorized", before: Transaction{}, after: plain, want: []string{food}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addedCategoryIDs(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addedCategoryIDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package transactions
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// SearchHit is attached to transactions listed with a search query.
+type SearchHit struct {
+	Rank float64 `json:"rank"`
+	// Snippet is an HTML-escaped excerpt of the note with matches wrapped
+	// in <mark>.
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// Markers ts_headline puts around matches; they are swapped for <mark> tags
+// after escaping, so note text can never inject markup. They are the STX and
+// ETX control characters, which headlineSource strips from the note so only
+// ts_headline can produce them.
+const (
+	hlStart = "\x02"
+	hlStop  = "\x03"
+)
+
+// headlineSource is the note text handed to ts_headline.
+const headlineSource = "translate(COALESCE(note, ''), chr(2) || chr(3), '')"
+
+const headlineOptions = "StartSel=" + hlStart + ", StopSel=" + hlStop + ", MaxFragments=2, MaxWords=20, MinWords=5"
+
+func snippetHTML(headline string) string {
+	s := html.EscapeString(headline)
+	s = strings.ReplaceAll(s, hlStart, "<mark>")
+	return strings.ReplaceAll(s, hlStop, "</mark>")
+}
+
+// BuildTSQuery turns a search box query into to_tsquery syntax. Words must
+// all match; "quoted words" must appear as a phrase, a trailing * matches a
+// prefix and a leading - excludes a word. Anything that is not a letter or
+// digit separates words, so the result is always valid to_tsquery input. It
+// returns "" when q has no searchable words.
+func BuildTSQuery(q string) string {
+	var terms []string
+	for len(q) > 0 {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			break
+		}
+
+		if q[0] == '"' {
+			end := strings.IndexByte(q[1:], '"')
+			phrase := q[1:]
+			q = ""
+			if end >= 0 {
+				phrase, q = phrase[:end], phrase[end+1:]
+			}
+			if words := lexemes(phrase); len(words) > 0 {
+				terms = append(terms, "("+strings.Join(words, " <-> ")+")")
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(q, unicode.IsSpace)
+		if end < 0 {
+			end = len(q)
+		}
+		word := q[:end]
+		q = q[end:]
+
+		negate := strings.HasPrefix(word, "-")
+		prefix := strings.HasSuffix(word, "*")
+		words := lexemes(word)
+		if len(words) == 0 {
+			continue
+		}
+		if prefix {
+			words[len(words)-1] += ":*"
+		}
+		term := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			term = "(" + term + ")"
+		}
+		if negate {
+			term = "!" + term
+		}
+		terms = append(terms, term)
+	}
+
+	// A query made only of exclusions would need a full scan; require at
+	// least one positive term.
+	for _, t := range terms {
+		if !strings.HasPrefix(t, "!") {
+			return strings.Join(terms, " & ")
+		}
+	}
+	return ""
+}
+
+// lexemes splits s into lower-cased runs of letters and digits.
+func lexemes(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
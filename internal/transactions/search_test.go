@@ -0,0 +1,43 @@
+package transactions
+
+import "testing"
+
+func TestBuildTSQuery(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"Coffee", "coffee"},
+		{"coffee shop", "coffee & shop"},
+		{`"coffee shop" beans`, "(coffee <-> shop) & beans"},
+		{"groc*", "groc:*"},
+		{"coffee -decaf", "coffee & !decaf"},
+		{"-decaf", ""},
+		{"e-mail", "(e <-> mail)"},
+		{"кава", "кава"},
+		{`a & b | !c ')`, "a & b & c"},
+		{`"unterminated phrase`, "(unterminated <-> phrase)"},
+		{"*** ---", ""},
+	}
+	for _, tc := range cases {
+		if got := BuildTSQuery(tc.in); got != tc.want {
+			t.Errorf("BuildTSQuery(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSnippetHTML(t *testing.T) {
+	got := snippetHTML("lunch at " + hlStart + "Joe's" + hlStop + " <b>diner</b>")
+	want := "lunch at <mark>Joe&#39;s</mark> &lt;b&gt;diner&lt;/b&gt;"
+	if got != want {
+		t.Fatalf("snippetHTML = %q, want %q", got, want)
+	}
+
+	// Brackets and other look-alike characters in a note stay literal text.
+	got = snippetHTML("⟦x⟧ [[y]] " + hlStart + "z" + hlStop)
+	want = "⟦x⟧ [[y]] <mark>z</mark>"
+	if got != want {
+		t.Fatalf("snippetHTML = %q, want %q", got, want)
+	}
+}
@@ -1,18 +1,32 @@
 package transactions
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
 
 type Service struct {
-	repo *Repo
+	repo           *Repo
+	trashRetention time.Duration
 }
 
-func NewService(repo *Repo) *Service { return &Service{repo: repo} }
+func NewService(repo *Repo, trashRetention time.Duration) *Service {
+	return &Service{repo: repo, trashRetention: trashRetention}
+}
 
 type ListResult struct {
-	Items   []Transaction `json:"items"`
-	HasNext bool          `json:"has_next"`
-	Limit   int           `json:"limit"`
-	Offset  int           `json:"offset"`
+	Items      []Transaction `json:"items"`
+	HasNext    bool          `json:"has_next"`
+	HasPrev    bool          `json:"has_prev"`
+	Limit      int           `json:"limit"`
+	Offset     int           `json:"offset"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	PrevCursor string        `json:"prev_cursor,omitempty"`
 }
 
 func (s *Service) Create(ctx context.Context, t Transaction) (Transaction, error) {
@@ -20,6 +34,9 @@ func (s *Service) Create(ctx context.Context, t Transaction) (Transaction, error
 	return s.repo.Create(ctx, t)
 }
 
+// List returns one page of transactions together with cursors for the pages
+// around it. A cursor in f takes precedence over the offset, which remains
+// for clients that page by number.
 func (s *Service) List(ctx context.Context, workspaceID string, f ListFilter) (ListResult, error) {
 	pageSize := f.Limit
 	if pageSize <= 0 {
@@ -30,7 +47,7 @@ func (s *Service) List(ctx context.Context, workspaceID string, f ListFilter) (L
 	}
 
 	offset := f.Offset
-	if offset < 0 {
+	if offset < 0 || f.Cursor != nil {
 		offset = 0
 	}
 
@@ -42,28 +59,262 @@ func (s *Service) List(ctx context.Context, workspaceID string, f ListFilter) (L
 		return ListResult{}, err
 	}
 
+	return buildPage(items, f, pageSize, offset), nil
+}
+
+// buildPage turns up to pageSize+1 rows fetched by Repo.List into a page:
+// the extra row only signals that more exist, and rows fetched for a Before
+// cursor are put back in sort order.
+func buildPage(items []Transaction, f ListFilter, pageSize, offset int) ListResult {
+	more := len(items) > pageSize
+	if more {
+		items = items[:pageSize]
+	}
+
+	res := ListResult{Limit: pageSize, Offset: offset}
+	if f.Cursor != nil && f.Cursor.Before {
+		slices.Reverse(items)
+		res.HasNext = true
+		res.HasPrev = more
+	} else {
+		res.HasNext = more
+		res.HasPrev = f.Cursor != nil || offset > 0
+	}
+	res.Items = items
+
+	if len(items) > 0 {
+		if res.HasNext {
+			res.NextCursor = cursorAt(items[len(items)-1], f, false).Encode()
+		}
+		if res.HasPrev {
+			res.PrevCursor = cursorAt(items[0], f, true).Encode()
+		}
+	}
+	return res
+}
+
+func (s *Service) GetByID(ctx context.Context, workspaceID, txID string) (Transaction, error) {
+	return s.repo.GetByID(ctx, workspaceID, txID)
+}
+
+func (s *Service) Update(ctx context.Context, actorID string, t Transaction) (Transaction, error) {
+	return s.repo.Update(ctx, actorID, t)
+}
+
+// SetSplits replaces the line items of a transaction; an empty list removes
+// the split. The change is stored as a new version like any other update.
+func (s *Service) SetSplits(ctx context.Context, actorID, workspaceID, txID string, splits []Split) (Transaction, error) {
+	cur, err := s.repo.GetByID(ctx, workspaceID, txID)
+	if err != nil {
+		return Transaction{}, err
+	}
+	norm, single, err := NormalizeSplits(splits, cur.AmountMinor)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if single != nil {
+		cur.CategoryID = single.CategoryID
+	}
+	cur.Splits = norm
+	return s.repo.Update(ctx, actorID, cur)
+}
+
+// Bulk validates op and the selection, then applies op to every selected
+// transaction at once. check decides per transaction whether the actor may
+// change it.
+func (s *Service) Bulk(ctx context.Context, actorID, workspaceID string, sel BulkSelection, op BulkOp, check func(Transaction) error) (BulkResult, error) {
+	op, err := NormalizeBulkOp(op)
+	if err != nil {
+		return BulkResult{}, err
+	}
+
+	if (len(sel.IDs) == 0) == (sel.Filter == nil) {
+		return BulkResult{}, fmt.Errorf("%w: select transactions by ids or by filter", ErrInvalidBulkOp)
+	}
+	if sel.Filter == nil {
+		ids := make([]string, 0, len(sel.IDs))
+		for _, id := range sel.IDs {
+			v, err := NormalizeOptionalUUID(&id)
+			if err != nil || v == nil {
+				return BulkResult{}, fmt.Errorf("%w: ids must be uuids", ErrInvalidBulkOp)
+			}
+			if !slices.Contains(ids, *v) {
+				ids = append(ids, *v)
+			}
+		}
+		if len(ids) > MaxBulkItems {
+			return BulkResult{}, ErrBulkTooMany
+		}
+		sel.IDs = ids
+	}
+
+	return s.repo.Bulk(ctx, actorID, workspaceID, sel, op, check)
+}
+
+func (s *Service) Delete(ctx context.Context, actorID, workspaceID, txID string) (bool, error) {
+	return s.repo.Delete(ctx, actorID, workspaceID, txID)
+}
+
+func (s *Service) Restore(ctx context.Context, actorID, workspaceID, txID string) (Transaction, error) {
+	return s.repo.Restore(ctx, actorID, workspaceID, txID)
+}
+
+func (s *Service) GetDeletedByID(ctx context.Context, workspaceID, txID string) (Transaction, error) {
+	return s.repo.GetDeletedByID(ctx, workspaceID, txID)
+}
+
+// ListDeleted pages through the workspace trash using the same limit rules
+// as List.
+func (s *Service) ListDeleted(ctx context.Context, workspaceID string, limit, offset int) (ListResult, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.repo.ListDeleted(ctx, workspaceID, limit+1, offset)
+	if err != nil {
+		return ListResult{}, err
+	}
+
 	hasNext := false
-	if len(items) > pageSize {
+	if len(items) > limit {
 		hasNext = true
-		items = items[:pageSize]
+		items = items[:limit]
 	}
 
 	return ListResult{
 		Items:   items,
 		HasNext: hasNext,
-		Limit:   pageSize,
+		HasPrev: offset > 0,
+		Limit:   limit,
 		Offset:  offset,
 	}, nil
 }
 
-func (s *Service) GetByID(ctx context.Context, workspaceID, txID string) (Transaction, error) {
-	return s.repo.GetByID(ctx, workspaceID, txID)
+// TrashRetention reports how long deleted transactions stay restorable.
+func (s *Service) TrashRetention() time.Duration { return s.trashRetention }
+
+// PurgeDeleted permanently removes transactions whose retention has passed.
+func (s *Service) PurgeDeleted(ctx context.Context) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, s.purgeCutoff(time.Now()))
+}
+
+// purgeCutoff is the deleted_at before which trashed transactions are purged.
+func (s *Service) purgeCutoff(now time.Time) time.Time {
+	return now.Add(-s.trashRetention)
+}
+
+// History returns the current state of a transaction and its previous
+// versions, newest first, each with the changes made by the update that
+// replaced it.
+func (s *Service) History(ctx context.Context, workspaceID, txID string) (History, error) {
+	cur, err := s.repo.GetByID(ctx, workspaceID, txID)
+	if err != nil {
+		return History{}, err
+	}
+
+	versions, err := s.repo.ListVersions(ctx, workspaceID, txID)
+	if err != nil {
+		return History{}, err
+	}
+
+	next := cur.Snapshot()
+	out := make([]Version, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		v.Changes = diffSnapshots(v.Snapshot, next)
+		out = append(out, v)
+		next = v.Snapshot
+	}
+
+	return History{Current: cur, Versions: out}, nil
+}
+
+func (s *Service) GetVersion(ctx context.Context, workspaceID, txID string, version int) (Version, error) {
+	return s.repo.GetVersion(ctx, workspaceID, txID, version)
+}
+
+// GetForRevert returns the transaction a revert would change. A trashed
+// transaction is returned with ErrInTrash rather than looking missing.
+func (s *Service) GetForRevert(ctx context.Context, workspaceID, txID string) (Transaction, error) {
+	cur, err := s.repo.GetByID(ctx, workspaceID, txID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if trashed, derr := s.repo.GetDeletedByID(ctx, workspaceID, txID); derr == nil {
+			return trashed, ErrInTrash
+		}
+	}
+	return cur, err
+}
+
+// Revert brings a transaction back to a stored version. The state being
+// replaced is itself kept as a new version, so a revert can be undone.
+func (s *Service) Revert(ctx context.Context, actorID, workspaceID, txID string, version int) (Transaction, error) {
+	v, err := s.repo.GetVersion(ctx, workspaceID, txID, version)
+	if err != nil {
+		return Transaction{}, err
+	}
+	cur, err := s.GetForRevert(ctx, workspaceID, txID)
+	if err != nil && !errors.Is(err, ErrInTrash) {
+		return Transaction{}, err
+	}
+	next, err := revertTo(cur, v)
+	if err != nil {
+		return Transaction{}, err
+	}
+	return s.repo.Update(ctx, actorID, next)
 }
 
-func (s *Service) Update(ctx context.Context, t Transaction) (Transaction, error) {
-	return s.repo.Update(ctx, t)
+// revertTo returns cur with the fields stored in v. Trashed transactions
+// have to be restored before they can be reverted.
+func revertTo(cur Transaction, v Version) (Transaction, error) {
+	if cur.DeletedAt != nil {
+		return Transaction{}, ErrInTrash
+	}
+	return v.Snapshot.Apply(cur), nil
 }
 
-func (s *Service) Delete(ctx context.Context, workspaceID, txID string) (bool, error) {
-	return s.repo.Delete(ctx, workspaceID, txID)
+func diffSnapshots(from, to Snapshot) []FieldChange {
+	changes := []FieldChange{}
+	if derefString(from.CategoryID) != derefString(to.CategoryID) {
+		changes = append(changes, FieldChange{Field: "category_id", From: from.CategoryID, To: to.CategoryID})
+	}
+	if from.Type != to.Type {
+		changes = append(changes, FieldChange{Field: "type", From: from.Type, To: to.Type})
+	}
+	if from.AmountMinor != to.AmountMinor {
+		changes = append(changes, FieldChange{Field: "amount_minor", From: from.AmountMinor, To: to.AmountMinor})
+	}
+	if from.Currency != to.Currency {
+		changes = append(changes, FieldChange{Field: "currency", From: from.Currency, To: to.Currency})
+	}
+	if !from.OccurredAt.Equal(to.OccurredAt) {
+		changes = append(changes, FieldChange{Field: "occurred_at", From: from.OccurredAt, To: to.OccurredAt})
+	}
+	if derefString(from.Note) != derefString(to.Note) {
+		changes = append(changes, FieldChange{Field: "note", From: from.Note, To: to.Note})
+	}
+	if !slices.Equal(from.Tags, to.Tags) && (len(from.Tags) > 0 || len(to.Tags) > 0) {
+		changes = append(changes, FieldChange{Field: "tags", From: from.Tags, To: to.Tags})
+	}
+	if !slices.EqualFunc(from.Splits, to.Splits, splitEqual) {
+		changes = append(changes, FieldChange{Field: "splits", From: from.Splits, To: to.Splits})
+	}
+	return changes
+}
+
+func splitEqual(a, b Split) bool {
+	return derefString(a.CategoryID) == derefString(b.CategoryID) && a.AmountMinor == b.AmountMinor &&
+		derefString(a.Note) == derefString(b.Note)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
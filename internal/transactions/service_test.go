@@ -0,0 +1,145 @@
+package transactions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPurgeCutoff(t *testing.T) {
+	now := time.Date(2024, 3, 31, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		retention time.Duration
+		want      time.Time
+	}{
+		{retention: 30 * 24 * time.Hour, want: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{retention: 24 * time.Hour, want: time.Date(2024, 3, 30, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s := NewService(nil, tt.retention)
+		if got := s.purgeCutoff(now); !got.Equal(tt.want) {
+			t.Errorf("retention %v: cutoff = %v, want %v", tt.retention, got, tt.want)
+		}
+	}
+}
+
+func changedFields(changes []FieldChange) []string {
+	out := []string{}
+	for _, c := range changes {
+		out = append(out, c.Field)
+	}
+	return out
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	food, rent := "food", "rent"
+	note := "lunch"
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	base := Snapshot{
+		CategoryID:  &food,
+		Type:        TypeExpense,
+		AmountMinor: 1000,
+		Currency:    "UAH",
+		OccurredAt:  at,
+		Tags:        []string{"work"},
+	}
+	split := func(cat *string, amount int64) Split { return Split{CategoryID: cat, AmountMinor: amount} }
+
+	tests := []struct {
+		name string
+		edit func(s Snapshot) Snapshot
+		want []string
+	}{
+		{name: "unchanged", edit: func(s Snapshot) Snapshot { return s }, want: []string{}},
+		{name: "same instant in another zone", edit: func(s Snapshot) Snapshot {
+			s.OccurredAt = at.In(time.FixedZone("UTC+2", 2*3600))
+			return s
+		}, want: []string{}},
+		{name: "nil and empty note are equal", edit: func(s Snapshot) Snapshot {
+			empty := ""
+			s.Note = &empty
+			return s
+		}, want: []string{}},
+		{name: "tags cleared", edit: func(s Snapshot) Snapshot {
+			s.Tags = nil
+			return s
+		}, want: []string{"tags"}},
+		{name: "scalar fields", edit: func(s Snapshot) Snapshot {
+			s.CategoryID = &rent
+			s.AmountMinor = 2000
+			s.Note = &note
+			return s
+		}, want: []string{"category_id", "amount_minor", "note"}},
+		{name: "tags reordered", edit: func(s Snapshot) Snapshot {
+			s.Tags = []string{"work", "travel"}
+			return s
+		}, want: []string{"tags"}},
+		{name: "splits added", edit: func(s Snapshot) Snapshot {
+			s.CategoryID = nil
+			s.Splits = []Split{split(&food, 600), split(&rent, 400)}
+			return s
+		}, want: []string{"category_id", "splits"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := changedFields(diffSnapshots(base, tt.edit(base)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changed fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	untagged := base
+	untagged.Tags = nil
+	emptyTags := base
+	emptyTags.Tags = []string{}
+	if got := diffSnapshots(untagged, emptyTags); len(got) != 0 {
+		t.Errorf("nil and empty tags reported as changed: %v", got)
+	}
+
+	withSplits := base
+	withSplits.CategoryID = nil
+	withSplits.Splits = []Split{split(&food, 600), split(&rent, 400)}
+	moved := withSplits
+	moved.Splits = []Split{split(&food, 500), split(&rent, 500)}
+	if got := changedFields(diffSnapshots(withSplits, moved)); !reflect.DeepEqual(got, []string{"splits"}) {
+		t.Errorf("split amounts: changed fields = %v", got)
+	}
+	same := withSplits
+	same.Splits = []Split{split(&food, 600), split(&rent, 400)}
+	if got := diffSnapshots(withSplits, same); len(got) != 0 {
+		t.Errorf("equal splits reported as changed: %v", got)
+	}
+}
+
+func TestRevertTo(t *testing.T) {
+	food := "food"
+	deletedAt := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	cur := Transaction{
+		ID:          "tx",
+		WorkspaceID: "ws",
+		UserID:      "u1",
+		Type:        TypeExpense,
+		AmountMinor: 2000,
+		Currency:    "UAH",
+	}
+	v := Version{Version: 1, Snapshot: Snapshot{CategoryID: &food, Type: TypeExpense, AmountMinor: 1000, Currency: "UAH", Tags: []string{"work"}}}
+
+	got, err := revertTo(cur, v)
+	if err != nil {
+		t.Fatalf("revertTo: %v", err)
+	}
+	if got.ID != cur.ID || got.UserID != cur.UserID || got.WorkspaceID != cur.WorkspaceID {
+		t.Errorf("identity changed: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Snapshot(), v.Snapshot) {
+		t.Errorf("snapshot = %+v, want %+v", got.Snapshot(), v.Snapshot)
+	}
+
+	trashed := cur
+	trashed.DeletedAt = &deletedAt
+	if _, err := revertTo(trashed, v); !errors.Is(err, ErrInTrash) {
+		t.Errorf("revert of trashed transaction err = %v, want ErrInTrash", err)
+	}
+}
@@ -44,9 +44,15 @@ func NormalizeCurrencyStrict(s string) (string, error) {
 }
 
 func ParseOccurredAt(s string) (time.Time, error) {
+	return ParseOccurredAtIn(s, time.UTC)
+}
+
+// ParseOccurredAtIn treats a date-only value as local midnight in loc; RFC3339
+// values carry their own offset and are returned as-is.
+func ParseOccurredAtIn(s string, loc *time.Location) (time.Time, error) {
 	s = strings.TrimSpace(s)
 	if len(s) == 10 {
-		if t, err := time.Parse("2006-01-02", s); err == nil {
+		if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
 			return t, nil
 		}
 	}
@@ -149,6 +155,8 @@ func isDigits(s string) bool {
 	return true
 }
 
+const maxTags = 10
+
 func ParseTagsCSV(s string) ([]string, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -171,7 +179,7 @@ func ParseTagsCSV(s string) ([]string, error) {
 		}
 		seen[v] = struct{}{}
 		out = append(out, v)
-		if len(out) >= 10 {
+		if len(out) >= maxTags {
 			break
 		}
 	}
@@ -197,9 +205,76 @@ func NormalizeTagsSlice(tags []string) ([]string, error) {
 		}
 		seen[v] = struct{}{}
 		out = append(out, v)
-		if len(out) >= 10 {
+		if len(out) >= maxTags {
 			break
 		}
 	}
 	return out, nil
 }
+
+const maxSplits = 50
+
+// NormalizeSplits validates line items against the transaction total; the
+// amounts are minor units and must add up exactly. An empty list means the
+// transaction is not split. A single line covering the total is not a split
+// either: it is returned as single, with no lines, so the caller can use its
+// category for the whole transaction.
+func NormalizeSplits(splits []Split, total int64) (lines []Split, single *Split, err error) {
+	if len(splits) == 0 {
+		return nil, nil, nil
+	}
+	if len(splits) > maxSplits {
+		return nil, nil, fmt.Errorf("%w: need at most %d lines", ErrInvalidSplits, maxSplits)
+	}
+
+	out := make([]Split, 0, len(splits))
+	var sum int64
+	for i, sp := range splits {
+		if sp.AmountMinor <= 0 {
+			return nil, nil, fmt.Errorf("%w: line %d amount must be > 0", ErrInvalidSplits, i+1)
+		}
+		catID, err := NormalizeOptionalUUID(sp.CategoryID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: line %d category_id must be uuid", ErrInvalidSplits, i+1)
+		}
+		sum += sp.AmountMinor
+		out = append(out, Split{CategoryID: catID, AmountMinor: sp.AmountMinor, Note: NormalizeOptionalNote(sp.Note)})
+	}
+	if sum != total {
+		return nil, nil, fmt.Errorf("%w: lines add up to %d, expected %d", ErrInvalidSplits, sum, total)
+	}
+	if len(out) == 1 {
+		return nil, &out[0], nil
+	}
+	return out, nil, nil
+}
+
+// NormalizeBulkOp checks that op carries what its action needs.
+func NormalizeBulkOp(op BulkOp) (BulkOp, error) {
+	switch op.Action {
+	case BulkSetCategory:
+		catID, err := NormalizeOptionalUUID(op.CategoryID)
+		if err != nil {
+			return BulkOp{}, fmt.Errorf("%w: category_id must be uuid", ErrInvalidBulkOp)
+		}
+		return BulkOp{Action: op.Action, CategoryID: catID}, nil
+	case BulkAddTags, BulkRemoveTags:
+		tags, err := NormalizeTagsSlice(op.Tags)
+		if err != nil {
+			return BulkOp{}, fmt.Errorf("%w: %v", ErrInvalidBulkOp, err)
+		}
+		if len(tags) == 0 {
+			return BulkOp{}, fmt.Errorf("%w: tags required", ErrInvalidBulkOp)
+		}
+		return BulkOp{Action: op.Action, Tags: tags}, nil
+	case BulkSetDate:
+		if op.OccurredAt.IsZero() {
+			return BulkOp{}, fmt.Errorf("%w: occurred_at required", ErrInvalidBulkOp)
+		}
+		return BulkOp{Action: op.Action, OccurredAt: op.OccurredAt}, nil
+	case BulkDelete:
+		return BulkOp{Action: op.Action}, nil
+	default:
+		return BulkOp{}, fmt.Errorf("%w: action must be set_category|add_tags|remove_tags|set_date|delete", ErrInvalidBulkOp)
+	}
+}
@@ -0,0 +1,78 @@
+package transactions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeSplits(t *testing.T) {
+	food := "5f1c3a52-8f0e-4a4c-9a55-3b1f6f0f2a11"
+	home := "0b7e7c3e-2f2d-4d1e-8f3b-6a9d2c4e5f60"
+	note := "  soap  "
+	trimmed := "soap"
+	line := func(cat *string, amount int64) Split { return Split{CategoryID: cat, AmountMinor: amount} }
+
+	tests := []struct {
+		name       string
+		splits     []Split
+		total      int64
+		wantLines  []Split
+		wantSingle *Split
+		wantErr    bool
+	}{
+		{name: "no lines", total: 1000},
+		{
+			name:      "lines add up",
+			splits:    []Split{line(&food, 700), {CategoryID: &home, AmountMinor: 300, Note: &note}},
+			total:     1000,
+			wantLines: []Split{line(&food, 700), {CategoryID: &home, AmountMinor: 300, Note: &trimmed}},
+		},
+		{
+			name:      "thirds need an explicit remainder",
+			splits:    []Split{line(&food, 333), line(&home, 333), line(nil, 334)},
+			total:     1000,
+			wantLines: []Split{line(&food, 333), line(&home, 333), line(nil, 334)},
+		},
+		{name: "thirds rounded down do not add up", splits: []Split{line(&food, 333), line(&home, 333), line(nil, 333)}, total: 1000, wantErr: true},
+		{name: "lines exceed total", splits: []Split{line(&food, 700), line(&home, 400)}, total: 1000, wantErr: true},
+		{name: "zero line", splits: []Split{line(&food, 1000), line(&home, 0)}, total: 1000, wantErr: true},
+		{name: "negative line", splits: []Split{line(&food, 1100), line(&home, -100)}, total: 1000, wantErr: true},
+		{name: "bad category", splits: []Split{line(&food, 500), {CategoryID: &note, AmountMinor: 500}}, total: 1000, wantErr: true},
+		{
+			name:       "single line collapses",
+			splits:     []Split{line(&food, 1000)},
+			total:      1000,
+			wantSingle: &Split{CategoryID: &food, AmountMinor: 1000},
+		},
+		{name: "single line short of total", splits: []Split{line(&food, 900)}, total: 1000, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, single, err := NormalizeSplits(tt.splits, tt.total)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSplits) {
+					t.Fatalf("err = %v, want ErrInvalidSplits", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeSplits: %v", err)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("lines = %+v, want %+v", lines, tt.wantLines)
+			}
+			if !reflect.DeepEqual(single, tt.wantSingle) {
+				t.Errorf("single = %+v, want %+v", single, tt.wantSingle)
+			}
+		})
+	}
+
+	tooMany := make([]Split, maxSplits+1)
+	for i := range tooMany {
+		tooMany[i] = line(nil, 1)
+	}
+	if _, _, err := NormalizeSplits(tooMany, int64(len(tooMany))); !errors.Is(err, ErrInvalidSplits) {
+		t.Errorf("%d lines: err = %v, want ErrInvalidSplits", len(tooMany), err)
+	}
+}
@@ -0,0 +1,44 @@
+package tzx
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const Default = "UTC"
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// Normalize validates an IANA zone name such as "Europe/Kyiv" and returns its
+// canonical spelling. "Local" is rejected because it depends on the host.
+func Normalize(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "local") {
+		return "", ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return "", ErrInvalidTimezone
+	}
+	return loc.String(), nil
+}
+
+// Load resolves a stored zone name, falling back to UTC for empty or unknown values.
+func Load(name string) *time.Location {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// StartOfDay returns local midnight of the calendar day t falls on in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
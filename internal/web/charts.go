@@ -0,0 +1,189 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	chartWidth   = 640
+	chartHeight  = 220
+	chartPadding = 32
+
+	sparkWidth   = 240
+	sparkHeight  = 48
+	sparkPadding = 3
+)
+
+var chartPalette = []string{
+	"#20f2c8", "#00a6ff", "#ffb020", "#ff5a5f", "#9b6bff",
+	"#4cd964", "#ff7ac6", "#f5e663", "#6be4ff", "#c0c4cc",
+}
+
+type lineChartVM struct {
+	Width    int
+	Height   int
+	Points   string
+	Area     string
+	Dots     []chartDotVM
+	MaxLabel string
+	BaseY    int
+	Empty    bool
+}
+
+type chartDotVM struct {
+	X     string
+	Y     string
+	Label string
+	Value string
+}
+
+type barChartVM struct {
+	Width  int
+	Height int
+	Bars   []chartBarVM
+	Empty  bool
+}
+
+type chartBarVM struct {
+	Y     int
+	Width string
+	Label string
+	Value string
+	Color string
+}
+
+type donutChartVM struct {
+	Slices []donutSliceVM
+	Total  string
+	Empty  bool
+}
+
+type donutSliceVM struct {
+	Dash   string
+	Offset string
+	Color  string
+	Label  string
+	Share  string
+}
+
+type chartValue struct {
+	Label string
+	Value int64
+}
+
+func colorAt(i int) string {
+	return chartPalette[i%len(chartPalette)]
+}
+
+func fmtCoord(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
+
+func buildLineChart(values []chartValue) lineChartVM {
+	return buildLineChartSized(values, chartWidth, chartHeight, chartPadding)
+}
+
+func buildSparkline(values []chartValue) lineChartVM {
+	return buildLineChartSized(values, sparkWidth, sparkHeight, sparkPadding)
+}
+
+// buildLineChartSized lays values out left to right, scaled so the largest
+// value touches the top padding.
+func buildLineChartSized(values []chartValue, width, height, pad int) lineChartVM {
+	vm := lineChartVM{Width: width, Height: height, BaseY: height - pad}
+
+	var maxV int64
+	for _, v := range values {
+		if v.Value > maxV {
+			maxV = v.Value
+		}
+	}
+	if len(values) == 0 || maxV == 0 {
+		vm.Empty = true
+		return vm
+	}
+
+	plotW := float64(width - 2*pad)
+	plotH := float64(height - 2*pad)
+	step := 0.0
+	if len(values) > 1 {
+		step = plotW / float64(len(values)-1)
+	}
+
+	pts := make([]string, 0, len(values))
+	for i, v := range values {
+		x := float64(pad) + step*float64(i)
+		if len(values) == 1 {
+			x = float64(pad) + plotW/2
+		}
+		y := float64(height-pad) - plotH*float64(v.Value)/float64(maxV)
+		xs, ys := fmtCoord(x), fmtCoord(y)
+		pts = append(pts, xs+","+ys)
+		vm.Dots = append(vm.Dots, chartDotVM{X: xs, Y: ys, Label: v.Label, Value: formatMinor(v.Value)})
+	}
+
+	vm.Points = strings.Join(pts, " ")
+	first, last := vm.Dots[0], vm.Dots[len(vm.Dots)-1]
+	base := fmt.Sprintf("%d", vm.BaseY)
+	vm.Area = first.X + "," + base + " " + vm.Points + " " + last.X + "," + base
+	vm.MaxLabel = formatMinor(maxV)
+	return vm
+}
+
+// buildBarChart renders one horizontal bar per value; widths are percentages
+// of the largest value.
+func buildBarChart(values []chartValue) barChartVM {
+	const rowH = 28
+
+	vm := barChartVM{Width: chartWidth, Height: rowH*len(values) + 4}
+
+	var maxV int64
+	for _, v := range values {
+		if v.Value > maxV {
+			maxV = v.Value
+		}
+	}
+	if len(values) == 0 || maxV == 0 {
+		vm.Empty = true
+		return vm
+	}
+
+	for i, v := range values {
+		vm.Bars = append(vm.Bars, chartBarVM{
+			Y:     i * rowH,
+			Width: fmtCoord(float64(v.Value) * 100 / float64(maxV)),
+			Label: v.Label,
+			Value: formatMinor(v.Value),
+			Color: colorAt(i),
+		})
+	}
+	return vm
+}
+
+// buildDonutChart uses a circle with circumference 100 (r ≈ 15.915) so each
+// slice's stroke-dasharray is simply its percentage share.
+func buildDonutChart(values []chartValue) donutChartVM {
+	var total int64
+	for _, v := range values {
+		total += v.Value
+	}
+	if total <= 0 {
+		return donutChartVM{Empty: true}
+	}
+
+	vm := donutChartVM{Total: formatMinor(total)}
+	acc := 0.0
+	for i, v := range values {
+		share := float64(v.Value) * 100 / float64(total)
+		vm.Slices = append(vm.Slices, donutSliceVM{
+			Dash:   fmtCoord(share) + " " + fmtCoord(100-share),
+			Offset: fmtCoord(25 - acc),
+			Color:  colorAt(i),
+			Label:  v.Label,
+			Share:  fmt.Sprintf("%.1f%%", share),
+		})
+		acc += share
+	}
+	return vm
+}
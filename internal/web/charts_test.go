@@ -0,0 +1,105 @@
+package web
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBuildLineChartSized(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []chartValue
+		wantEmpty bool
+		wantPts   string
+		wantArea  string
+	}{
+		{name: "no values", wantEmpty: true},
+		{name: "all zeros", values: []chartValue{{"a", 0}, {"b", 0}}, wantEmpty: true},
+		{name: "single point is centred at the top", values: []chartValue{{"a", 500}}, wantPts: "50,10", wantArea: "50,90 50,10 50,90"},
+		{
+			name:     "scaled to the largest value",
+			values:   []chartValue{{"a", 0}, {"b", 200}, {"c", 100}},
+			wantPts:  "10,90 50,10 90,50",
+			wantArea: "10,90 10,90 50,10 90,50 90,90",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := buildLineChartSized(tt.values, 100, 100, 10)
+			if vm.Width != 100 || vm.Height != 100 || vm.BaseY != 90 {
+				t.Errorf("size = %dx%d base %d", vm.Width, vm.Height, vm.BaseY)
+			}
+			if vm.Empty != tt.wantEmpty {
+				t.Fatalf("Empty = %v, want %v", vm.Empty, tt.wantEmpty)
+			}
+			if vm.Points != tt.wantPts || vm.Area != tt.wantArea {
+				t.Errorf("points = %q, area = %q; want %q, %q", vm.Points, vm.Area, tt.wantPts, tt.wantArea)
+			}
+			if len(vm.Dots) != len(tt.values) && !tt.wantEmpty {
+				t.Errorf("dots = %d, want %d", len(vm.Dots), len(tt.values))
+			}
+		})
+	}
+}
+
+func TestBuildBarChart(t *testing.T) {
+	if vm := buildBarChart(nil); !vm.Empty {
+		t.Error("no values should be empty")
+	}
+	if vm := buildBarChart([]chartValue{{"a", 0}}); !vm.Empty {
+		t.Error("all zeros should be empty")
+	}
+
+	vm := buildBarChart([]chartValue{{"a", 300}, {"b", 100}, {"c", 0}})
+	if vm.Empty || vm.Height != 3*28+4 {
+		t.Fatalf("got empty=%v height=%d", vm.Empty, vm.Height)
+	}
+	wantWidths := []string{"100", "33.33", "0"}
+	for i, bar := range vm.Bars {
+		if bar.Width != wantWidths[i] || bar.Y != i*28 || bar.Color != chartPalette[i] {
+			t.Errorf("bar %d = %+v, want width %s", i, bar, wantWidths[i])
+		}
+	}
+}
+
+func TestBuildDonutChart(t *testing.T) {
+	for _, values := range [][]chartValue{nil, {{"a", 0}, {"b", 0}}} {
+		if vm := buildDonutChart(values); !vm.Empty || len(vm.Slices) != 0 {
+			t.Errorf("buildDonutChart(%v) = %+v, want empty", values, vm)
+		}
+	}
+
+	vm := buildDonutChart([]chartValue{{"a", 1}, {"b", 1}, {"c", 1}})
+	if vm.Empty || len(vm.Slices) != 3 {
+		t.Fatalf("got %+v", vm)
+	}
+	// Each slice starts where the previous one ended, and the slices close
+	// the circle of circumference 100.
+	acc := 0.0
+	for i, sl := range vm.Slices {
+		dash := strings.Fields(sl.Dash)
+		share, _ := strconv.ParseFloat(dash[0], 64)
+		gap, _ := strconv.ParseFloat(dash[1], 64)
+		offset, _ := strconv.ParseFloat(sl.Offset, 64)
+		if math.Abs(share+gap-100) > 0.011 {
+			t.Errorf("slice %d dash %q does not add up to 100", i, sl.Dash)
+		}
+		if math.Abs(offset-(25-acc)) > 0.011 {
+			t.Errorf("slice %d offset = %v, want %v", i, offset, 25-acc)
+		}
+		if sl.Share != "33.3%" {
+			t.Errorf("slice %d share = %q", i, sl.Share)
+		}
+		acc += share
+	}
+	if math.Abs(acc-100) > 0.02 {
+		t.Errorf("shares add up to %v, want 100", acc)
+	}
+
+	one := buildDonutChart([]chartValue{{"a", 0}, {"b", 250}})
+	if one.Slices[1].Dash != "100 0" || one.Slices[1].Offset != "25" || one.Slices[0].Dash != "0 100" {
+		t.Errorf("single non-zero slice = %+v", one.Slices)
+	}
+}
@@ -2,6 +2,7 @@ package web
 
 import (
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,8 +12,13 @@ const (
 	AccessCookie           = "access_token"
 	RefreshCookie          = "refresh_token"
 	CurrentWorkspaceCookie = "current_workspace"
+	NewInviteLinkCookie    = "new_invite_link"
 )
 
+// newInviteLinkTTL is how long a freshly created invite link waits for the
+// redirect to the members page that shows it.
+const newInviteLinkTTL = time.Minute
+
 const currentWorkspaceCookieMaxAgeDays = 180
 
 type CookieConfig struct {
@@ -112,3 +118,42 @@ func clearCurrentWorkspaceCookie(c *gin.Context, cfg CookieConfig) {
 		SameSite: http.SameSiteLaxMode,
 	})
 }
+
+// setNewInviteLinkCookie hands a new invite link URL, or outside prod an
+// invitation's accept link, to the members page. The URL carries a bearer
+// token, so it travels in a cookie scoped to that page rather than in the
+// redirect URL, where logs and Referer headers would pick it up.
+func setNewInviteLinkCookie(c *gin.Context, cfg CookieConfig, link string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     NewInviteLinkCookie,
+		Value:    url.QueryEscape(link),
+		Path:     "/app/members",
+		Domain:   cfg.Domain,
+		MaxAge:   int(newInviteLinkTTL.Seconds()),
+		Expires:  time.Now().Add(newInviteLinkTTL),
+		Secure:   cfg.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// takeNewInviteLinkCookie returns the invite link set by
+// setNewInviteLinkCookie, if any, and clears it so it is shown only once.
+func takeNewInviteLinkCookie(c *gin.Context, cfg CookieConfig) string {
+	raw, err := c.Cookie(NewInviteLinkCookie)
+	if err != nil || raw == "" {
+		return ""
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     NewInviteLinkCookie,
+		Value:    "",
+		Path:     "/app/members",
+		Domain:   cfg.Domain,
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+		Secure:   cfg.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return raw
+}
@@ -0,0 +1,189 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/analytics"
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/savedviews"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+const analyticsTopCategories = 8
+
+type analyticsFiltersVM struct {
+	From     string
+	To       string
+	Currency string
+	Type     string
+	Bucket   string
+	ViewID   string
+}
+
+func (h *Handlers) GetAnalyticsPage(c *gin.Context) {
+	if h.Analytics == nil {
+		c.String(http.StatusInternalServerError, "analytics service is not configured")
+		return
+	}
+
+	f := readAnalyticsFilters(c)
+	views, err := h.viewOptions(c, c.GetString(workspaces.CtxWorkspaceIDKey), f.ViewID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list views")
+		return
+	}
+
+	h.render(c, "app/analytics.html", gin.H{
+		"Title":     "Analytics",
+		"BodyClass": "app-dark",
+		"Flash":     c.Query("flash"),
+		"Workspace": workspaceFromContext(c),
+		"Filters":   f,
+		"Views":     views,
+	})
+}
+
+func (h *Handlers) GetAnalyticsCharts(c *gin.Context) {
+	if h.Analytics == nil {
+		c.String(http.StatusInternalServerError, "analytics service is not configured")
+		return
+	}
+
+	wsUUID, err := uuid.Parse(c.GetString(workspaces.CtxWorkspaceIDKey))
+	if err != nil {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	f := readAnalyticsFilters(c)
+	loc := workspaces.GetLocation(c)
+	ctx := c.Request.Context()
+
+	svc := h.Analytics
+	if f.ViewID != "" && h.SavedViews != nil {
+		params, err := h.SavedViews.ViewParams(ctx, wsUUID.String(), c.GetString(auth.CtxUserIDKey), f.ViewID)
+		if err != nil {
+			h.renderAnalyticsError(c, err)
+			return
+		}
+		scope, ferr := transactions.ParseListFilter(func(k string) string { return params[k] }, loc)
+		if ferr != nil {
+			h.renderAnalyticsError(c, ferr)
+			return
+		}
+		svc = svc.Scoped(scope)
+	}
+
+	summary, err := svc.Summary(ctx, wsUUID, loc, f.From, f.To, f.Currency)
+	if err != nil {
+		h.renderAnalyticsError(c, err)
+		return
+	}
+
+	byCat, err := svc.ByCategory(ctx, wsUUID, loc, f.From, f.To, f.Currency, f.Type, 0)
+	if err != nil {
+		h.renderAnalyticsError(c, err)
+		return
+	}
+
+	series, err := svc.Timeseries(ctx, wsUUID, loc, f.From, f.To, f.Currency, f.Bucket, "", f.Type)
+	if err != nil {
+		h.renderAnalyticsError(c, err)
+		return
+	}
+
+	catValues := make([]chartValue, 0, len(byCat.Items))
+	var other int64
+	for i, it := range byCat.Items {
+		if i >= analyticsTopCategories {
+			other += it.Total
+			continue
+		}
+		catValues = append(catValues, chartValue{Label: it.Name, Value: it.Total})
+	}
+	if other > 0 {
+		catValues = append(catValues, chartValue{Label: "Other", Value: other})
+	}
+
+	seriesValues := make([]chartValue, 0, len(series.Points))
+	for _, p := range series.Points {
+		seriesValues = append(seriesValues, chartValue{Label: p.Period, Value: p.Total})
+	}
+
+	h.renderPartial(c, "analytics_charts", gin.H{
+		"Filters": f,
+		"Summary": gin.H{
+			"Income":   formatMinor(summary.IncomeTotal),
+			"Expense":  formatMinor(summary.ExpenseTotal),
+			"Net":      formatMinor(summary.Net),
+			"Negative": summary.Net < 0,
+		},
+		"Total": formatMinor(byCat.Total),
+		"Line":  buildLineChart(seriesValues),
+		"Bars":  buildBarChart(catValues),
+		"Donut": buildDonutChart(catValues),
+	})
+}
+
+func (h *Handlers) renderAnalyticsError(c *gin.Context, err error) {
+	msg := ""
+	var ferr *transactions.FilterError
+	switch {
+	case errors.Is(err, savedviews.ErrViewNotFound):
+		msg = "Saved view not found"
+	case errors.As(err, &ferr):
+		msg = "Saved view has an invalid filter: " + ferr.Msg
+	case errors.Is(err, analytics.ErrInvalidDateRange):
+		msg = "Invalid date range"
+	case errors.Is(err, analytics.ErrInvalidCurrency):
+		msg = "Currency must be a 3-letter code"
+	case errors.Is(err, analytics.ErrInvalidType):
+		msg = "Type must be income or expense"
+	case errors.Is(err, analytics.ErrInvalidBucket):
+		msg = "Invalid bucket"
+	default:
+		c.String(http.StatusInternalServerError, "could not load analytics")
+		return
+	}
+	c.Status(http.StatusBadRequest)
+	h.renderPartial(c, "analytics_error", gin.H{"Error": msg})
+}
+
+// readAnalyticsFilters defaults to expenses by month over the last six
+// calendar months in the workspace currency.
+func readAnalyticsFilters(c *gin.Context) analyticsFiltersVM {
+	loc := workspaces.GetLocation(c)
+	now := time.Now().In(loc)
+
+	f := analyticsFiltersVM{
+		From:     strings.TrimSpace(c.Query("from")),
+		To:       strings.TrimSpace(c.Query("to")),
+		Currency: strings.ToUpper(strings.TrimSpace(c.Query("currency"))),
+		Type:     strings.TrimSpace(c.Query("type")),
+		Bucket:   strings.TrimSpace(c.Query("bucket")),
+		ViewID:   strings.TrimSpace(c.Query("view_id")),
+	}
+	if f.From == "" {
+		f.From = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, -5, 0).Format("2006-01-02")
+	}
+	if f.To == "" {
+		f.To = now.Format("2006-01-02")
+	}
+	if f.Currency == "" {
+		f.Currency = workspaceCurrency(c)
+	}
+	if f.Type == "" {
+		f.Type = string(analytics.TypeExpense)
+	}
+	if f.Bucket == "" {
+		f.Bucket = string(analytics.BucketMonth)
+	}
+	return f
+}
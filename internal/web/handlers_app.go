@@ -1,6 +1,37 @@
 package web
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/analytics"
+	"github.com/skelbigo/FinanceTracker/internal/budgets"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+const (
+	dashboardRecentLimit  = 10
+	dashboardRiskLimit    = 5
+	dashboardRiskPercent  = 75
+	dashboardSparkDays    = 30
+	dashboardDateLayout   = "2006-01-02"
+	dashboardPartialError = "dashboard_error"
+)
+
+type dashboardRiskVM struct {
+	Category  string
+	Spent     string
+	Amount    string
+	Remaining string
+	Percent   int
+	IsOver    bool
+}
 
 func (h *Handlers) GetDashboard(c *gin.Context) {
 	kicker := c.Query("flash")
@@ -9,5 +40,208 @@ func (h *Handlers) GetDashboard(c *gin.Context) {
 		"Kicker":    kicker,
 		"BodyClass": "app-dark",
 		"MainClass": "dash-main",
+		"Workspace": workspaceFromContext(c),
+	})
+}
+
+func (h *Handlers) GetDashboardSummary(c *gin.Context) {
+	if h.Analytics == nil {
+		c.String(http.StatusInternalServerError, "analytics service is not configured")
+		return
+	}
+	wsUUID, ok := dashboardWorkspace(c)
+	if !ok {
+		return
+	}
+
+	loc := workspaces.GetLocation(c)
+	now := time.Now().In(loc)
+	from, to := monthToDate(now)
+	currency := workspaceCurrency(c)
+
+	sum, err := h.Analytics.Summary(c.Request.Context(), wsUUID, loc, from, to, currency)
+	if err != nil {
+		h.renderPartial(c, dashboardPartialError, gin.H{"Error": "Could not load this month's totals"})
+		return
+	}
+
+	h.renderPartial(c, "dashboard_summary", dashboardSummaryData(sum, now, currency))
+}
+
+// dashboardSummaryData formats the month-to-date totals for the summary card.
+func dashboardSummaryData(sum analytics.SummaryResponse, now time.Time, currency string) gin.H {
+	return gin.H{
+		"Month":    now.Format("January 2006"),
+		"Currency": currency,
+		"Income":   formatMinor(sum.IncomeTotal),
+		"Expense":  formatMinor(sum.ExpenseTotal),
+		"Net":      formatMinor(sum.Net),
+		"Negative": sum.Net < 0,
+	}
+}
+
+func (h *Handlers) GetDashboardBudgets(c *gin.Context) {
+	if h.Budgets == nil || h.Categories == nil {
+		c.String(http.StatusInternalServerError, "categories/budgets service is not configured")
+		return
+	}
+	wsUUID, ok := dashboardWorkspace(c)
+	if !ok {
+		return
+	}
+
+	loc := workspaces.GetLocation(c)
+	now := time.Now().In(loc)
+
+	items, err := h.Budgets.GetBudgetsForMonth(c.Request.Context(), wsUUID, now.Year(), int(now.Month()), loc)
+	if err != nil {
+		h.renderPartial(c, dashboardPartialError, gin.H{"Error": "Could not load budgets"})
+		return
+	}
+	cats, err := h.Categories.List(c.Request.Context(), wsUUID.String())
+	if err != nil {
+		h.renderPartial(c, dashboardPartialError, gin.H{"Error": "Could not load budgets"})
+		return
+	}
+	names := map[string]string{}
+	for _, cat := range cats {
+		names[cat.ID] = cat.Name
+	}
+
+	h.renderPartial(c, "dashboard_budgets", gin.H{
+		"Items":    budgetRisks(items, names),
+		"Currency": workspaceCurrency(c),
+	})
+}
+
+// budgetRisks picks the budgets at least dashboardRiskPercent spent, overspent
+// ones first and then by how much is used, keeping the top dashboardRiskLimit.
+func budgetRisks(items []budgets.BudgetResponse, names map[string]string) []dashboardRiskVM {
+	risks := make([]dashboardRiskVM, 0)
+	for _, b := range items {
+		p := budgetPercent(b.Spent, b.Amount)
+		if p < dashboardRiskPercent && !b.IsOver {
+			continue
+		}
+		id := b.CategoryID.String()
+		risks = append(risks, dashboardRiskVM{
+			Category:  categoryName(&id, names),
+			Spent:     formatMinor(b.Spent),
+			Amount:    formatMinor(b.Amount),
+			Remaining: formatMinor(b.Remaining),
+			Percent:   p,
+			IsOver:    b.IsOver,
+		})
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		if risks[i].IsOver != risks[j].IsOver {
+			return risks[i].IsOver
+		}
+		return risks[i].Percent > risks[j].Percent
+	})
+	if len(risks) > dashboardRiskLimit {
+		risks = risks[:dashboardRiskLimit]
+	}
+	return risks
+}
+
+func (h *Handlers) GetDashboardRecent(c *gin.Context) {
+	if h.Transactions == nil || h.Categories == nil {
+		c.String(http.StatusInternalServerError, "categories/transactions service is not configured")
+		return
+	}
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	result, err := h.Transactions.List(c.Request.Context(), wsID, transactions.ListFilter{
+		Limit: dashboardRecentLimit,
+		Sort:  "occurred_at_desc",
 	})
+	if err != nil {
+		h.renderPartial(c, dashboardPartialError, gin.H{"Error": "Could not load recent transactions"})
+		return
+	}
+	cats, err := h.Categories.List(c.Request.Context(), wsID)
+	if err != nil {
+		h.renderPartial(c, dashboardPartialError, gin.H{"Error": "Could not load recent transactions"})
+		return
+	}
+	names := map[string]string{}
+	for _, cat := range cats {
+		names[cat.ID] = cat.Name
+	}
+
+	loc := workspaces.GetLocation(c)
+	rows := make([]txRowVM, 0, len(result.Items))
+	for _, item := range result.Items {
+		rows = append(rows, txRowVM{
+			ID:       item.ID,
+			Occurred: item.OccurredAt.In(loc).Format(dashboardDateLayout),
+			Type:     string(item.Type),
+			Category: categoryName(item.CategoryID, names),
+			Amount:   formatMinor(item.AmountMinor),
+			Currency: item.Currency,
+			Note:     optionalString(item.Note),
+			Tags:     strings.Join(item.Tags, ", "),
+		})
+	}
+
+	h.renderPartial(c, "dashboard_recent", gin.H{"Items": rows})
+}
+
+func (h *Handlers) GetDashboardSparkline(c *gin.Context) {
+	if h.Analytics == nil {
+		c.String(http.StatusInternalServerError, "analytics service is not configured")
+		return
+	}
+	wsUUID, ok := dashboardWorkspace(c)
+	if !ok {
+		return
+	}
+
+	loc := workspaces.GetLocation(c)
+	now := time.Now().In(loc)
+	from := now.AddDate(0, 0, -(dashboardSparkDays - 1)).Format(dashboardDateLayout)
+	to := now.Format(dashboardDateLayout)
+	currency := workspaceCurrency(c)
+
+	series, err := h.Analytics.Timeseries(c.Request.Context(), wsUUID, loc, from, to, currency,
+		string(analytics.BucketDay), "", string(analytics.TypeExpense))
+	if err != nil {
+		h.renderPartial(c, dashboardPartialError, gin.H{"Error": "Could not load spending trend"})
+		return
+	}
+
+	values := make([]chartValue, 0, len(series.Points))
+	var total int64
+	for _, p := range series.Points {
+		values = append(values, chartValue{Label: p.Period, Value: p.Total})
+		total += p.Total
+	}
+
+	h.renderPartial(c, "dashboard_sparkline", gin.H{
+		"Line":     buildSparkline(values),
+		"Total":    formatMinor(total),
+		"Currency": currency,
+		"Days":     dashboardSparkDays,
+	})
+}
+
+func dashboardWorkspace(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.GetString(workspaces.CtxWorkspaceIDKey))
+	if err != nil {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+// monthToDate returns the first day of now's month and now's date, both in
+// now's location.
+func monthToDate(now time.Time) (string, string) {
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return first.Format(dashboardDateLayout), now.Format(dashboardDateLayout)
 }
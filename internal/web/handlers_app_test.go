@@ -0,0 +1,98 @@
+package web
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/analytics"
+	"github.com/skelbigo/FinanceTracker/internal/budgets"
+)
+
+func TestMonthToDate(t *testing.T) {
+	instant := time.Date(2024, 3, 31, 23, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		loc      *time.Location
+		from, to string
+	}{
+		{name: "utc", loc: time.UTC, from: "2024-03-01", to: "2024-03-31"},
+		{name: "ahead of utc is already in april", loc: time.FixedZone("UTC+3", 3*60*60), from: "2024-04-01", to: "2024-04-01"},
+		{name: "behind utc", loc: time.FixedZone("UTC-5", -5*60*60), from: "2024-03-01", to: "2024-03-31"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := monthToDate(instant.In(tt.loc))
+			if from != tt.from || to != tt.to {
+				t.Errorf("monthToDate = %s..%s, want %s..%s", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestDashboardSummaryData(t *testing.T) {
+	now := time.Date(2024, 4, 1, 2, 30, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+
+	got := dashboardSummaryData(analytics.SummaryResponse{IncomeTotal: 150000, ExpenseTotal: 20050, Net: 129950}, now, "UAH")
+	want := map[string]any{
+		"Month": "April 2024", "Currency": "UAH",
+		"Income": "1500.00", "Expense": "200.50", "Net": "1299.50", "Negative": false,
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+
+	got = dashboardSummaryData(analytics.SummaryResponse{IncomeTotal: 1000, ExpenseTotal: 13345, Net: -12345}, now, "UAH")
+	if got["Net"] != "-123.45" || got["Negative"] != true {
+		t.Errorf("negative net = %v (negative %v), want -123.45 (true)", got["Net"], got["Negative"])
+	}
+	got = dashboardSummaryData(analytics.SummaryResponse{ExpenseTotal: 50, Net: -50}, now, "UAH")
+	if got["Net"] != "-0.50" {
+		t.Errorf("small negative net = %v, want -0.50", got["Net"])
+	}
+}
+
+func TestBudgetRisks(t *testing.T) {
+	ids := make([]uuid.UUID, 8)
+	names := map[string]string{}
+	for i := range ids {
+		ids[i] = uuid.New()
+		names[ids[i].String()] = string(rune('A' + i))
+	}
+	budget := func(i int, amount, spent int64) budgets.BudgetResponse {
+		return budgets.BudgetResponse{CategoryID: ids[i], Amount: amount, Spent: spent, Remaining: amount - spent, IsOver: spent > amount}
+	}
+
+	got := budgetRisks([]budgets.BudgetResponse{
+		budget(0, 1000, 500),  // 50%: not at risk
+		budget(1, 1000, 800),  // 80%
+		budget(2, 1000, 1500), // over
+		budget(3, 1000, 950),  // 95%
+		budget(4, 1000, 1100), // over
+		budget(5, 1000, 750),  // 75%: threshold
+		budget(6, 1000, 760),  // 76%: dropped by the limit
+		budget(7, 0, 0),       // unused zero budget
+	}, names)
+
+	var order []string
+	for _, r := range got {
+		order = append(order, r.Category)
+	}
+	if want := "C E D B G"; strings.Join(order, " ") != want {
+		t.Fatalf("order = %s, want %s", strings.Join(order, " "), want)
+	}
+	if !got[0].IsOver || got[0].Percent != 100 || got[0].Remaining != "-5.00" {
+		t.Errorf("first risk = %+v, want overspent at 100%% with -5.00 left", got[0])
+	}
+	if got[2].IsOver || got[2].Percent != 95 || got[2].Spent != "9.50" || got[2].Amount != "10.00" {
+		t.Errorf("third risk = %+v", got[2])
+	}
+
+	if got := budgetRisks(nil, names); got == nil || len(got) != 0 {
+		t.Errorf("no budgets = %#v, want an empty list", got)
+	}
+}
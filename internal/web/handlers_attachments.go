@@ -0,0 +1,157 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/attachments"
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type attachmentVM struct {
+	ID       string
+	FileName string
+	Size     string
+}
+
+type txAttachmentsVM struct {
+	TxID  string
+	Items []attachmentVM
+	Error string
+	CSRF  string
+}
+
+// txAttachments builds the attachments block shown in the transaction edit
+// row; it is nil when attachments are not configured.
+func (h *Handlers) txAttachments(c *gin.Context, wsID, txID, errMsg string) *txAttachmentsVM {
+	if h.Attachments == nil {
+		return nil
+	}
+
+	csrf := strings.TrimSpace(c.GetHeader("X-CSRF-Token"))
+	if csrf == "" {
+		csrf = GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
+	}
+	vm := &txAttachmentsVM{TxID: txID, Error: errMsg, CSRF: csrf}
+
+	items, err := h.Attachments.List(c.Request.Context(), wsID, txID)
+	if err != nil {
+		if vm.Error == "" {
+			vm.Error = "Could not load attachments"
+		}
+		return vm
+	}
+	for _, a := range items {
+		vm.Items = append(vm.Items, attachmentVM{ID: a.ID, FileName: a.FileName, Size: formatBytes(a.SizeBytes)})
+	}
+	return vm
+}
+
+func (h *Handlers) GetTransactionAttachment(c *gin.Context) {
+	if h.Attachments == nil {
+		c.String(http.StatusNotFound, "not found")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	a, rc, err := h.Attachments.Open(c.Request.Context(), wsID, c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		c.String(http.StatusNotFound, "not found")
+		return
+	}
+	defer rc.Close()
+
+	c.DataFromReader(http.StatusOK, a.SizeBytes, a.ContentType, rc, attachments.ContentHeaders(a))
+}
+
+func (h *Handlers) PostUploadTransactionAttachment(c *gin.Context) {
+	if h.Attachments == nil {
+		c.String(http.StatusNotFound, "not found")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	txID := strings.TrimSpace(c.Param("id"))
+	if !h.authorizeTxWrite(c, wsID, txID) {
+		return
+	}
+
+	msg := ""
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Attachments.MaxFileBytes()+1<<20)
+	fh, err := c.FormFile("file")
+	switch {
+	case err != nil:
+		msg = "Choose a file to upload (max " + formatBytes(h.Attachments.MaxFileBytes()) + ")"
+	case fh.Size > h.Attachments.MaxFileBytes():
+		msg = attachmentErrorMessage(attachments.ErrFileTooLarge, h.Attachments.MaxFileBytes())
+	default:
+		f, err := fh.Open()
+		if err != nil {
+			msg = "Could not read the uploaded file"
+			break
+		}
+		_, err = h.Attachments.Upload(c.Request.Context(), c.GetString(auth.CtxUserIDKey), wsID, txID, fh.Filename, f)
+		_ = f.Close()
+		if err != nil {
+			msg = attachmentErrorMessage(err, h.Attachments.MaxFileBytes())
+		}
+	}
+
+	h.renderPartial(c, "tx_attachments", gin.H{"Attachments": h.txAttachments(c, wsID, txID, msg)})
+}
+
+func (h *Handlers) PostDeleteTransactionAttachment(c *gin.Context) {
+	if h.Attachments == nil {
+		c.String(http.StatusNotFound, "not found")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	txID := strings.TrimSpace(c.Param("id"))
+	if !h.authorizeTxWrite(c, wsID, txID) {
+		return
+	}
+
+	msg := ""
+	if err := h.Attachments.Delete(c.Request.Context(), wsID, txID, c.Param("attachmentId")); err != nil {
+		msg = attachmentErrorMessage(err, h.Attachments.MaxFileBytes())
+	}
+
+	h.renderPartial(c, "tx_attachments", gin.H{"Attachments": h.txAttachments(c, wsID, txID, msg)})
+}
+
+func attachmentErrorMessage(err error, maxBytes int64) string {
+	switch {
+	case errors.Is(err, attachments.ErrEmptyFile):
+		return "The file is empty"
+	case errors.Is(err, attachments.ErrFileTooLarge):
+		return "The file is larger than " + formatBytes(maxBytes)
+	case errors.Is(err, attachments.ErrUnsupportedType):
+		return "Only PDF, JPEG, PNG, WebP and GIF files can be attached"
+	case errors.Is(err, attachments.ErrQuotaExceeded):
+		return "This workspace has used up its attachment storage"
+	case errors.Is(err, attachments.ErrAttachmentNotFound), errors.Is(err, attachments.ErrTransactionNotFound):
+		return "Attachment not found"
+	default:
+		return "Could not save the attachment"
+	}
+}
+
+// formatBytes renders a byte count for display, e.g. "1.5 MB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
@@ -14,6 +15,8 @@ func (h *Handlers) GetLogin(c *gin.Context) {
 	h.render(c, "auth/login.html", gin.H{
 		"Title":      "Login",
 		"Flash":      c.Query("flash"),
+		"Invite":     c.Query("invite"),
+		"Join":       c.Query("join"),
 		"BodyClass":  "auth",
 		"MainClass":  "auth-main",
 		"HideHeader": true,
@@ -102,6 +105,8 @@ func (h *Handlers) PostLogin(c *gin.Context) {
 			h.render(c, "auth/login.html", gin.H{
 				"Title":      "Login",
 				"Flash":      "Invalid credentials",
+				"Invite":     c.PostForm("invite"),
+				"Join":       c.PostForm("join"),
 				"BodyClass":  "auth",
 				"MainClass":  "auth-main",
 				"HideHeader": true,
@@ -111,6 +116,8 @@ func (h *Handlers) PostLogin(c *gin.Context) {
 			h.render(c, "auth/login.html", gin.H{
 				"Title":      "Login",
 				"Flash":      "Something went wrong. Please try again.",
+				"Invite":     c.PostForm("invite"),
+				"Join":       c.PostForm("join"),
 				"BodyClass":  "auth",
 				"MainClass":  "auth-main",
 				"HideHeader": true,
@@ -120,13 +127,24 @@ func (h *Handlers) PostLogin(c *gin.Context) {
 	}
 
 	setAuthCookies(c, h.CookieCfg, resp.AccessToken, h.AccessTTL, resp.RefreshToken, h.RefreshTTL)
-	c.Redirect(http.StatusSeeOther, "/app")
+	c.Redirect(http.StatusSeeOther, h.afterAuthRedirect(c, resp.User.ID))
 }
 
 func (h *Handlers) GetRegister(c *gin.Context) {
+	invite := strings.TrimSpace(c.Query("invite"))
+	email := ""
+	if invite != "" && h.Invitations != nil {
+		if p, err := h.Invitations.Preview(c.Request.Context(), invite); err == nil {
+			email = p.Email
+		}
+	}
+
 	h.render(c, "auth/register.html", gin.H{
 		"Title":      "Register",
 		"Flash":      c.Query("flash"),
+		"Invite":     invite,
+		"Join":       c.Query("join"),
+		"Email":      email,
 		"BodyClass":  "auth",
 		"MainClass":  "auth-main",
 		"HideHeader": true,
@@ -152,6 +170,9 @@ func (h *Handlers) PostRegister(c *gin.Context) {
 		h.render(c, "auth/register.html", gin.H{
 			"Title":      "Register",
 			"Flash":      flash,
+			"Invite":     c.PostForm("invite"),
+			"Join":       c.PostForm("join"),
+			"Email":      req.Email,
 			"BodyClass":  "auth",
 			"MainClass":  "auth-main",
 			"HideHeader": true,
@@ -160,7 +181,7 @@ func (h *Handlers) PostRegister(c *gin.Context) {
 	}
 
 	setAuthCookies(c, h.CookieCfg, resp.AccessToken, h.AccessTTL, resp.RefreshToken, h.RefreshTTL)
-	c.Redirect(http.StatusSeeOther, "/app")
+	c.Redirect(http.StatusSeeOther, h.afterAuthRedirect(c, resp.User.ID))
 }
 
 func (h *Handlers) PostLogout(c *gin.Context) {
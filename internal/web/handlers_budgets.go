@@ -0,0 +1,278 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/budgets"
+	"github.com/skelbigo/FinanceTracker/internal/categories"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+const monthLayout = "2006-01"
+
+type budgetRowVM struct {
+	CategoryID  string
+	Category    string
+	Month       string
+	HasBudget   bool
+	Amount      string
+	Spent       string
+	Remaining   string
+	AmountInput string
+	IsOver      bool
+	Percent     int
+	Currency    string
+	CSRF        string
+}
+
+func (h *Handlers) GetBudgetsPage(c *gin.Context) {
+	if h.Categories == nil || h.Budgets == nil {
+		c.String(http.StatusInternalServerError, "categories/budgets service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	wsUUID, err := uuid.Parse(wsID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	loc := workspaces.GetLocation(c)
+	month, ok := parseMonthParam(c.Query("month"), loc)
+	if !ok {
+		c.Redirect(http.StatusSeeOther, "/app/budgets?flash="+url.QueryEscape("Invalid month"))
+		return
+	}
+
+	items, err := h.Budgets.GetBudgetsForMonth(c.Request.Context(), wsUUID, month.Year(), int(month.Month()), loc)
+	if err != nil {
+		if errors.Is(err, budgets.ErrInvalidYear) || errors.Is(err, budgets.ErrInvalidMonth) {
+			c.Redirect(http.StatusSeeOther, "/app/budgets?flash="+url.QueryEscape("Budgets are not available for that month"))
+			return
+		}
+		c.String(http.StatusInternalServerError, "could not list budgets")
+		return
+	}
+
+	cats, err := h.Categories.List(c.Request.Context(), wsID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list categories")
+		return
+	}
+
+	byCat := map[string]budgets.BudgetResponse{}
+	var totalAmount, totalSpent int64
+	for _, b := range items {
+		byCat[b.CategoryID.String()] = b
+		totalAmount += b.Amount
+		totalSpent += b.Spent
+	}
+
+	currency := workspaceCurrency(c)
+	csrf := GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
+	monthStr := month.Format(monthLayout)
+
+	rows := make([]budgetRowVM, 0, len(cats))
+	for _, cat := range cats {
+		b, has := byCat[cat.ID]
+		if !has && (cat.Type != categories.TypeExpense || cat.Archived()) {
+			continue
+		}
+		rows = append(rows, newBudgetRowVM(cat, monthStr, b, has, currency, csrf))
+	}
+
+	h.render(c, "app/budgets.html", gin.H{
+		"Title":        "Budgets",
+		"BodyClass":    "app-dark",
+		"Flash":        c.Query("flash"),
+		"Workspace":    workspaceFromContext(c),
+		"CSRF":         csrf,
+		"Month":        monthStr,
+		"MonthLabel":   month.Format("January 2006"),
+		"PrevMonth":    month.AddDate(0, -1, 0).Format(monthLayout),
+		"NextMonth":    month.AddDate(0, 1, 0).Format(monthLayout),
+		"Rows":         rows,
+		"Currency":     currency,
+		"TotalAmount":  formatMinor(totalAmount),
+		"TotalSpent":   formatMinor(totalSpent),
+		"TotalOver":    totalSpent > totalAmount,
+		"TotalPercent": budgetPercent(totalSpent, totalAmount),
+	})
+}
+
+func (h *Handlers) PostUpsertBudget(c *gin.Context) {
+	if h.Categories == nil || h.Budgets == nil {
+		c.String(http.StatusInternalServerError, "categories/budgets service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	wsUUID, err := uuid.Parse(wsID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	loc := workspaces.GetLocation(c)
+	var errs []string
+
+	catID, err := uuid.Parse(strings.TrimSpace(c.PostForm("category_id")))
+	if err != nil {
+		errs = append(errs, "Category id is invalid")
+	}
+
+	month, ok := parseMonthParam(c.PostForm("month"), loc)
+	if !ok {
+		errs = append(errs, "Month must look like 2025-01")
+	}
+
+	amount, err := parseBudgetAmount(c.PostForm("amount"))
+	if err != nil {
+		errs = append(errs, "Amount must be zero or a positive number (e.g. 1500.00)")
+	}
+
+	if len(errs) > 0 {
+		c.Status(http.StatusUnprocessableEntity)
+		h.renderPartial(c, "budget_form_errors", gin.H{"Errors": errs})
+		return
+	}
+
+	_, err = h.Budgets.UpsertBudget(c.Request.Context(), c.GetString(auth.CtxUserIDKey), wsUUID, budgets.UpsertBudgetRequest{
+		CategoryID: catID,
+		Year:       month.Year(),
+		Month:      int(month.Month()),
+		Amount:     amount,
+	})
+	if err != nil {
+		msg := ""
+		switch {
+		case errors.Is(err, budgets.ErrInvalidYear), errors.Is(err, budgets.ErrInvalidMonth):
+			msg = "Budgets are not available for that month"
+		case errors.Is(err, budgets.ErrInvalidAmount):
+			msg = "Amount must be zero or a positive number"
+		case errors.Is(err, budgets.ErrCategoryNotFound):
+			msg = "Category not found"
+		case errors.Is(err, budgets.ErrCategoryNotExpense):
+			msg = "Budgets can only be set for expense categories"
+		case errors.Is(err, budgets.ErrCategoryArchived):
+			msg = "This category is archived"
+		default:
+			c.String(http.StatusInternalServerError, "could not save budget")
+			return
+		}
+		c.Status(http.StatusUnprocessableEntity)
+		h.renderPartial(c, "budget_form_errors", gin.H{"Errors": []string{msg}})
+		return
+	}
+
+	items, err := h.Budgets.GetBudgetsForMonth(c.Request.Context(), wsUUID, month.Year(), int(month.Month()), loc)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list budgets")
+		return
+	}
+
+	cats, err := h.Categories.List(c.Request.Context(), wsID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list categories")
+		return
+	}
+
+	var cat categories.Category
+	for _, cc := range cats {
+		if cc.ID == catID.String() {
+			cat = cc
+			break
+		}
+	}
+
+	var b budgets.BudgetResponse
+	has := false
+	for _, it := range items {
+		if it.CategoryID == catID {
+			b, has = it, true
+			break
+		}
+	}
+
+	csrf := strings.TrimSpace(c.GetHeader("X-CSRF-Token"))
+	if csrf == "" {
+		csrf = GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
+	}
+	row := newBudgetRowVM(cat, month.Format(monthLayout), b, has, workspaceCurrency(c), csrf)
+
+	h.renderPartial(c, "budget_update_response", gin.H{"Row": row})
+}
+
+func newBudgetRowVM(cat categories.Category, month string, b budgets.BudgetResponse, has bool, currency, csrf string) budgetRowVM {
+	row := budgetRowVM{
+		CategoryID: cat.ID,
+		Category:   cat.Name,
+		Month:      month,
+		HasBudget:  has,
+		Currency:   currency,
+		CSRF:       csrf,
+	}
+	if !has {
+		return row
+	}
+	row.Amount = formatMinor(b.Amount)
+	row.Spent = formatMinor(b.Spent)
+	row.Remaining = formatMinor(b.Remaining)
+	row.AmountInput = formatMinor(b.Amount)
+	row.IsOver = b.IsOver
+	row.Percent = budgetPercent(b.Spent, b.Amount)
+	return row
+}
+
+// parseMonthParam parses YYYY-MM; an empty value means the current month in loc.
+func parseMonthParam(s string, loc *time.Location) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		now := time.Now().In(loc)
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc), true
+	}
+	t, err := time.ParseInLocation(monthLayout, s, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func parseBudgetAmount(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s != "" && strings.Trim(s, "0.,") == "" {
+		return 0, nil
+	}
+	return transactions.ParseAmountMinor(s)
+}
+
+func budgetPercent(spent, amount int64) int {
+	if amount <= 0 {
+		if spent > 0 {
+			return 100
+		}
+		return 0
+	}
+	p := int(spent * 100 / amount)
+	if p > 100 {
+		return 100
+	}
+	return p
+}
+
+func workspaceCurrency(c *gin.Context) string {
+	if w, ok := workspaceFromContext(c).(workspaces.Workspace); ok && w.DefaultCurrency != "" {
+		return w.DefaultCurrency
+	}
+	return "UAH"
+}
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMonthParam(t *testing.T) {
+	loc := time.FixedZone("UTC+14", 14*60*60)
+
+	before := time.Now().In(loc)
+	got, ok := parseMonthParam("  ", loc)
+	after := time.Now().In(loc)
+	if !ok || got.Location() != loc || got.Day() != 1 || got.Hour() != 0 {
+		t.Fatalf("empty month = %v, %v; want the first of the month in loc", got, ok)
+	}
+	if (got.Year() != before.Year() || got.Month() != before.Month()) && (got.Year() != after.Year() || got.Month() != after.Month()) {
+		t.Errorf("empty month = %v, want the current month in loc (%v)", got, before)
+	}
+
+	got, ok = parseMonthParam("2024-02", loc)
+	if want := time.Date(2024, 2, 1, 0, 0, 0, 0, loc); !ok || !got.Equal(want) {
+		t.Errorf("parseMonthParam(2024-02) = %v, %v; want %v", got, ok, want)
+	}
+
+	for _, in := range []string{"2024-13", "2024-2-1", "february", "2024/02"} {
+		if _, ok := parseMonthParam(in, loc); ok {
+			t.Errorf("parseMonthParam(%q) accepted an invalid month", in)
+		}
+	}
+}
+
+func TestParseBudgetAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "0.00", want: 0},
+		{in: "0", want: 0},
+		{in: " 0,0 ", want: 0},
+		{in: "12.34", want: 1234},
+		{in: "12,5", want: 1250},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseBudgetAmount(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseBudgetAmount(%q) = %d, %v; want %d, err %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBudgetPercent(t *testing.T) {
+	tests := []struct {
+		name          string
+		spent, amount int64
+		want          int
+	}{
+		{name: "nothing spent", spent: 0, amount: 1000, want: 0},
+		{name: "partly spent", spent: 255, amount: 1000, want: 25},
+		{name: "exactly spent", spent: 1000, amount: 1000, want: 100},
+		{name: "overspent clamps", spent: 5000, amount: 1000, want: 100},
+		{name: "zero budget unused", spent: 0, amount: 0, want: 0},
+		{name: "zero budget spent", spent: 1, amount: 0, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := budgetPercent(tt.spent, tt.amount); got != tt.want {
+				t.Errorf("budgetPercent(%d, %d) = %d, want %d", tt.spent, tt.amount, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type bulkResultVM struct {
+	Summary  string
+	Failures []string
+}
+
+// PostBulkTransactions applies one action to the rows ticked in the table, or
+// to every transaction matching the filters bar when "all matching" is set.
+func (h *Handlers) PostBulkTransactions(c *gin.Context) {
+	if h.Transactions == nil {
+		c.String(http.StatusInternalServerError, "transactions service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+	userID := c.GetString(auth.CtxUserIDKey)
+
+	loc := workspaces.GetLocation(c)
+	var errs []string
+
+	op := transactions.BulkOp{Action: transactions.BulkAction(strings.TrimSpace(c.PostForm("action")))}
+	switch op.Action {
+	case transactions.BulkSetCategory:
+		catRaw := strings.TrimSpace(c.PostForm("bulk_category_id"))
+		catID, err := transactions.NormalizeOptionalUUID(&catRaw)
+		if err != nil {
+			errs = append(errs, "Category id is invalid")
+		} else if !currentAccess(c).CanUseCategory(catID) {
+			errs = append(errs, "Your role cannot record transactions in this category")
+		}
+		op.CategoryID = catID
+	case transactions.BulkAddTags, transactions.BulkRemoveTags:
+		tags, err := transactions.ParseTagsCSV(c.PostForm("bulk_tags"))
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else if len(tags) == 0 {
+			errs = append(errs, "Enter at least one tag")
+		}
+		op.Tags = tags
+	case transactions.BulkSetDate:
+		occurredAt, err := transactions.ParseOccurredAtIn(c.PostForm("bulk_occurred_at"), loc)
+		if err != nil {
+			errs = append(errs, "Date must be a valid date")
+		}
+		op.OccurredAt = occurredAt
+	case transactions.BulkDelete:
+	default:
+		errs = append(errs, "Choose a bulk action")
+	}
+
+	var sel transactions.BulkSelection
+	if c.PostForm("all_matching") != "" {
+		f, err := buildTxListFilter(readTxFiltersFromForm(c), loc)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		f.Limit, f.Offset, f.Sort = 0, 0, ""
+		sel.Filter = &f
+	} else {
+		sel.IDs = c.PostFormArray("ids")
+		if len(sel.IDs) == 0 {
+			errs = append(errs, "Select at least one transaction")
+		}
+	}
+
+	if len(errs) > 0 {
+		c.Status(http.StatusUnprocessableEntity)
+		h.renderPartial(c, "tx_form_errors", gin.H{"Errors": errs})
+		return
+	}
+
+	res, err := h.Transactions.Bulk(c.Request.Context(), userID, wsID, sel, op, func(tx transactions.Transaction) error {
+		if !canWriteTx(c, tx) {
+			return transactions.ErrTxForbidden
+		}
+		return nil
+	})
+	if err != nil {
+		var msg string
+		switch {
+		case errors.Is(err, transactions.ErrBulkTooMany):
+			msg = fmt.Sprintf("At most %d transactions can be changed at once; narrow the filters", transactions.MaxBulkItems)
+		case errors.Is(err, transactions.ErrInvalidBulkOp), errors.Is(err, transactions.ErrCategoryMissing):
+			msg = err.Error()
+		case errors.Is(err, transactions.ErrCategoryArchived):
+			msg = "This category is archived"
+		default:
+			c.String(http.StatusInternalServerError, "could not apply bulk action")
+			return
+		}
+		c.Status(http.StatusUnprocessableEntity)
+		h.renderPartial(c, "tx_form_errors", gin.H{"Errors": []string{msg}})
+		return
+	}
+
+	h.renderPartial(c, "tx_bulk_result", gin.H{"Result": newBulkResultVM(res)})
+}
+
+func newBulkResultVM(res transactions.BulkResult) bulkResultVM {
+	vm := bulkResultVM{
+		Summary: fmt.Sprintf("Updated %d, deleted %d, unchanged %d, failed %d.",
+			res.Updated, res.Deleted, res.Unchanged, res.Failed),
+	}
+	for _, item := range res.Items {
+		switch item.Status {
+		case transactions.BulkNotFound:
+			vm.Failures = append(vm.Failures, item.ID+": not found")
+		case transactions.BulkFailed:
+			vm.Failures = append(vm.Failures, item.ID+": "+item.Error)
+		}
+	}
+	return vm
+}
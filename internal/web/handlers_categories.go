@@ -0,0 +1,226 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/categories"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type categoryRowVM struct {
+	ID               string
+	Name             string
+	Type             string
+	Archived         bool
+	TransactionCount int64
+	BudgetCount      int64
+	CSRF             string
+}
+
+func (h *Handlers) GetCategoriesPage(c *gin.Context) {
+	if h.Categories == nil {
+		c.String(http.StatusInternalServerError, "categories service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	items, err := h.Categories.ListUsage(c.Request.Context(), wsID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list categories")
+		return
+	}
+
+	csrf := GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
+	rows := make([]categoryRowVM, 0, len(items))
+	for _, it := range items {
+		rows = append(rows, newCategoryRowVM(it, csrf))
+	}
+
+	h.render(c, "app/categories.html", gin.H{
+		"Title":     "Categories",
+		"BodyClass": "app-dark",
+		"Flash":     c.Query("flash"),
+		"Workspace": workspaceFromContext(c),
+		"CSRF":      csrf,
+		"Rows":      rows,
+	})
+}
+
+func (h *Handlers) PostCreateCategory(c *gin.Context) {
+	if h.Categories == nil {
+		c.String(http.StatusInternalServerError, "categories service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	typ := categories.Type(strings.ToLower(strings.TrimSpace(c.PostForm("type"))))
+	if typ != categories.TypeIncome && typ != categories.TypeExpense {
+		c.Status(http.StatusUnprocessableEntity)
+		h.renderPartial(c, "category_form_errors", gin.H{"Errors": []string{"Type must be income or expense"}})
+		return
+	}
+
+	cat, err := h.Categories.Create(c.Request.Context(), c.GetString(auth.CtxUserIDKey), wsID, c.PostForm("name"), typ)
+	if err != nil {
+		h.renderCategoryError(c, err)
+		return
+	}
+
+	h.renderPartial(c, "category_create_response", gin.H{
+		"Row": newCategoryRowVM(categories.CategoryUsage{Category: cat}, requestCSRF(c, h)),
+	})
+}
+
+func (h *Handlers) GetCategoryEdit(c *gin.Context) {
+	if h.Categories == nil {
+		c.String(http.StatusInternalServerError, "categories service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	u, err := h.Categories.GetUsage(c.Request.Context(), wsID, strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		if errors.Is(err, categories.ErrCategoryNotFound) {
+			c.String(http.StatusNotFound, "not found")
+			return
+		}
+		c.String(http.StatusInternalServerError, "could not load category")
+		return
+	}
+
+	h.renderPartial(c, "category_row_edit", gin.H{"Row": newCategoryRowVM(u, requestCSRF(c, h))})
+}
+
+func (h *Handlers) GetCategoryRow(c *gin.Context) {
+	h.respondCategoryRow(c, strings.TrimSpace(c.Param("id")))
+}
+
+func (h *Handlers) PostRenameCategory(c *gin.Context) {
+	if h.Categories == nil {
+		c.String(http.StatusInternalServerError, "categories service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if _, err := h.Categories.Rename(c.Request.Context(), c.GetString(auth.CtxUserIDKey), wsID, id, c.PostForm("name")); err != nil {
+		h.renderCategoryError(c, err)
+		return
+	}
+	h.respondCategoryRow(c, id)
+}
+
+func (h *Handlers) PostArchiveCategory(c *gin.Context) {
+	h.setCategoryArchived(c, true)
+}
+
+func (h *Handlers) PostUnarchiveCategory(c *gin.Context) {
+	h.setCategoryArchived(c, false)
+}
+
+func (h *Handlers) setCategoryArchived(c *gin.Context, archived bool) {
+	if h.Categories == nil {
+		c.String(http.StatusInternalServerError, "categories service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if _, err := h.Categories.SetArchived(c.Request.Context(), c.GetString(auth.CtxUserIDKey), wsID, id, archived); err != nil {
+		h.renderCategoryError(c, err)
+		return
+	}
+	h.respondCategoryRow(c, id)
+}
+
+func (h *Handlers) respondCategoryRow(c *gin.Context, id string) {
+	if h.Categories == nil {
+		c.String(http.StatusInternalServerError, "categories service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	u, err := h.Categories.GetUsage(c.Request.Context(), wsID, id)
+	if err != nil {
+		h.renderCategoryError(c, err)
+		return
+	}
+	h.renderPartial(c, "category_update_response", gin.H{"Row": newCategoryRowVM(u, requestCSRF(c, h))})
+}
+
+func (h *Handlers) renderCategoryError(c *gin.Context, err error) {
+	msg := ""
+	switch {
+	case errors.Is(err, categories.ErrInvalidName):
+		msg = "Name is required"
+	case errors.Is(err, categories.ErrInvalidType):
+		msg = "Type must be income or expense"
+	case errors.Is(err, categories.ErrCategoryExists):
+		msg = "A category with this name already exists"
+	case errors.Is(err, categories.ErrCategoryNotFound):
+		c.String(http.StatusNotFound, "not found")
+		return
+	default:
+		c.String(http.StatusInternalServerError, "could not save category")
+		return
+	}
+	c.Status(http.StatusUnprocessableEntity)
+	h.renderPartial(c, "category_form_errors", gin.H{"Errors": []string{msg}})
+}
+
+func newCategoryRowVM(u categories.CategoryUsage, csrf string) categoryRowVM {
+	return categoryRowVM{
+		ID:               u.ID,
+		Name:             u.Name,
+		Type:             string(u.Type),
+		Archived:         u.Archived(),
+		TransactionCount: u.TransactionCount,
+		BudgetCount:      u.BudgetCount,
+		CSRF:             csrf,
+	}
+}
+
+// requestCSRF reuses the token HTMX sent with the request so swapped-in
+// forms stay valid, minting a fresh one otherwise.
+func requestCSRF(c *gin.Context, h *Handlers) string {
+	csrf := strings.TrimSpace(c.GetHeader("X-CSRF-Token"))
+	if csrf == "" {
+		csrf = GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
+	}
+	return csrf
+}
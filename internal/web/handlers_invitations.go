@@ -0,0 +1,315 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type invitationRowVM struct {
+	ID      string
+	Email   string
+	Role    string
+	Expires string
+}
+
+func (h *Handlers) GetInvite(c *gin.Context) {
+	token := strings.TrimSpace(c.Query("token"))
+	data := gin.H{
+		"Title":      "Invitation",
+		"Flash":      c.Query("flash"),
+		"Token":      token,
+		"BodyClass":  "auth",
+		"MainClass":  "auth-main",
+		"HideHeader": true,
+	}
+
+	if h.Invitations == nil {
+		c.String(http.StatusInternalServerError, "invitations service is not configured")
+		return
+	}
+	p, err := h.Invitations.Preview(c.Request.Context(), token)
+	if err != nil {
+		data["Error"] = invitationErrorMessage(err)
+		c.Status(http.StatusNotFound)
+		h.render(c, "auth/invite.html", data)
+		return
+	}
+	data["Invite"] = p
+	h.render(c, "auth/invite.html", data)
+}
+
+func (h *Handlers) PostAcceptInvite(c *gin.Context) {
+	token := strings.TrimSpace(c.PostForm("token"))
+	userID := c.GetString(auth.CtxUserIDKey)
+	c.Redirect(http.StatusSeeOther, h.acceptInvitation(c, token, userID))
+}
+
+// afterAuthRedirect picks where to send the browser after login/register,
+// carrying on with an email invitation or a join link if the form had one.
+func (h *Handlers) afterAuthRedirect(c *gin.Context, userID string) string {
+	if token := strings.TrimSpace(c.PostForm("invite")); token != "" {
+		return h.acceptInvitation(c, token, userID)
+	}
+	if token := strings.TrimSpace(c.PostForm("join")); token != "" {
+		return "/join/" + url.PathEscape(token)
+	}
+	return "/app"
+}
+
+// acceptInvitation accepts token for userID and returns where to send the
+// browser next.
+func (h *Handlers) acceptInvitation(c *gin.Context, token, userID string) string {
+	if h.Invitations == nil || token == "" {
+		return "/app"
+	}
+	wsID, err := h.Invitations.Accept(c.Request.Context(), token, userID)
+	if err != nil {
+		return "/app/workspaces?flash=" + url.QueryEscape(invitationErrorMessage(err))
+	}
+	setCurrentWorkspaceCookie(c, h.CookieCfg, wsID)
+	return "/app"
+}
+
+func (h *Handlers) PostCreateInvitation(c *gin.Context) {
+	wsID, ok := h.requireMembersManager(c)
+	if !ok {
+		return
+	}
+	if h.Invitations == nil {
+		c.String(http.StatusInternalServerError, "invitations service is not configured")
+		return
+	}
+
+	inviterID := c.GetString(auth.CtxUserIDKey)
+	email := strings.TrimSpace(c.PostForm("email"))
+	role := workspaces.Role(strings.TrimSpace(c.PostForm("role")))
+
+	inv, err := h.Invitations.Create(c.Request.Context(), wsID, inviterID, email, role)
+	if err != nil {
+		redirectMembers(c, invitationErrorMessage(err))
+		return
+	}
+
+	flash := "Invitation sent to " + inv.Email
+	if !inv.EmailSent {
+		flash = "Invitation created, but the email could not be sent"
+	}
+	if inv.Token != "" {
+		// Outside prod the token comes back for testing; like invite links it
+		// must not end up in the redirect URL.
+		setNewInviteLinkCookie(c, h.CookieCfg, "/invite?token="+url.QueryEscape(inv.Token))
+		flash += ". Dev link below"
+	}
+	redirectMembers(c, flash)
+}
+
+func (h *Handlers) PostRevokeInvitation(c *gin.Context) {
+	wsID, ok := h.requireMembersManager(c)
+	if !ok {
+		return
+	}
+	if h.Invitations == nil {
+		c.String(http.StatusInternalServerError, "invitations service is not configured")
+		return
+	}
+
+	if err := h.Invitations.Revoke(c.Request.Context(), wsID, strings.TrimSpace(c.Param("id"))); err != nil {
+		redirectMembers(c, invitationErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Invitation revoked")
+}
+
+func (h *Handlers) pendingInvitations(c *gin.Context, wsID string) []invitationRowVM {
+	if h.Invitations == nil || !currentAccess(c).Has(workspaces.PermMembersManage) {
+		return nil
+	}
+	items, err := h.Invitations.ListPending(c.Request.Context(), wsID)
+	if err != nil {
+		return nil
+	}
+	loc := workspaces.GetLocation(c)
+	out := make([]invitationRowVM, 0, len(items))
+	for _, it := range items {
+		out = append(out, invitationRowVM{
+			ID:      it.ID,
+			Email:   it.Email,
+			Role:    string(it.Role),
+			Expires: it.ExpiresAt.In(loc).Format("2006-01-02 15:04"),
+		})
+	}
+	return out
+}
+
+func invitationErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, workspaces.ErrInvalidInvitation):
+		return "This invitation is invalid or has expired"
+	case errors.Is(err, workspaces.ErrInvitationEmailMismatch):
+		return "This invitation was sent to a different email address"
+	case errors.Is(err, workspaces.ErrInvitationNotFound):
+		return "Invitation not found"
+	case errors.Is(err, workspaces.ErrInvalidEmail):
+		return "Enter a valid email address"
+	case errors.Is(err, workspaces.ErrInvalidInviteLink):
+		return "This invite link is invalid, expired or already used up"
+	case errors.Is(err, workspaces.ErrInviteLinkNotFound):
+		return "Invite link not found"
+	case errors.Is(err, workspaces.ErrInvalidMaxUses):
+		return "Max uses must be between 1 and 1000"
+	case errors.Is(err, workspaces.ErrInvalidExpiry):
+		return "Expiry must be between 1 and 30 days"
+	default:
+		return memberErrorMessage(err)
+	}
+}
+
+type inviteLinkRowVM struct {
+	ID      string
+	Role    string
+	Uses    string
+	Expires string
+}
+
+func (h *Handlers) GetJoin(c *gin.Context) {
+	if h.Invitations == nil {
+		c.String(http.StatusInternalServerError, "invitations service is not configured")
+		return
+	}
+
+	token := strings.TrimSpace(c.Param("token"))
+	data := gin.H{
+		"Title":      "Join workspace",
+		"Flash":      c.Query("flash"),
+		"Token":      token,
+		"BodyClass":  "auth",
+		"MainClass":  "auth-main",
+		"HideHeader": true,
+	}
+
+	p, err := h.Invitations.PreviewLink(c.Request.Context(), token)
+	if err != nil {
+		data["Error"] = invitationErrorMessage(err)
+		c.Status(http.StatusNotFound)
+		h.render(c, "auth/join.html", data)
+		return
+	}
+	data["Link"] = p
+	h.render(c, "auth/join.html", data)
+}
+
+func (h *Handlers) PostJoin(c *gin.Context) {
+	if h.Invitations == nil {
+		c.String(http.StatusInternalServerError, "invitations service is not configured")
+		return
+	}
+
+	token := strings.TrimSpace(c.Param("token"))
+	userID := c.GetString(auth.CtxUserIDKey)
+
+	wsID, err := h.Invitations.Join(c.Request.Context(), token, userID)
+	if err != nil && !errors.Is(err, workspaces.ErrAlreadyMember) {
+		c.Redirect(http.StatusSeeOther, "/join/"+url.PathEscape(token)+"?flash="+url.QueryEscape(invitationErrorMessage(err)))
+		return
+	}
+	setCurrentWorkspaceCookie(c, h.CookieCfg, wsID)
+	c.Redirect(http.StatusSeeOther, "/app")
+}
+
+func (h *Handlers) PostCreateInviteLink(c *gin.Context) {
+	wsID, ok := h.requireMembersManager(c)
+	if !ok {
+		return
+	}
+	if h.Invitations == nil {
+		c.String(http.StatusInternalServerError, "invitations service is not configured")
+		return
+	}
+
+	role := workspaces.Role(strings.TrimSpace(c.PostForm("role")))
+
+	var maxUses *int
+	if raw := strings.TrimSpace(c.PostForm("max_uses")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			redirectMembers(c, invitationErrorMessage(workspaces.ErrInvalidMaxUses))
+			return
+		}
+		maxUses = &n
+	}
+
+	var expiresIn time.Duration
+	if raw := strings.TrimSpace(c.PostForm("expires_in_days")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			redirectMembers(c, invitationErrorMessage(workspaces.ErrInvalidExpiry))
+			return
+		}
+		expiresIn = time.Duration(n) * 24 * time.Hour
+	}
+
+	link, err := h.Invitations.CreateLink(c.Request.Context(), wsID, c.GetString(auth.CtxUserIDKey), role, maxUses, expiresIn)
+	if errors.Is(err, workspaces.ErrInvalidRole) {
+		redirectMembers(c, "Invite links can only grant member or viewer")
+		return
+	}
+	if err != nil {
+		redirectMembers(c, invitationErrorMessage(err))
+		return
+	}
+	setNewInviteLinkCookie(c, h.CookieCfg, link.URL)
+	redirectMembers(c, "Invite link created")
+}
+
+func (h *Handlers) PostRevokeInviteLink(c *gin.Context) {
+	wsID, ok := h.requireMembersManager(c)
+	if !ok {
+		return
+	}
+	if h.Invitations == nil {
+		c.String(http.StatusInternalServerError, "invitations service is not configured")
+		return
+	}
+
+	if err := h.Invitations.RevokeLink(c.Request.Context(), wsID, strings.TrimSpace(c.Param("id"))); err != nil {
+		redirectMembers(c, invitationErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Invite link revoked")
+}
+
+func (h *Handlers) activeInviteLinks(c *gin.Context, wsID string) []inviteLinkRowVM {
+	if h.Invitations == nil || !currentAccess(c).Has(workspaces.PermMembersManage) {
+		return nil
+	}
+	items, err := h.Invitations.ListLinks(c.Request.Context(), wsID)
+	if err != nil {
+		return nil
+	}
+	loc := workspaces.GetLocation(c)
+	out := make([]inviteLinkRowVM, 0, len(items))
+	for _, it := range items {
+		uses := strconv.Itoa(it.UseCount)
+		if it.MaxUses != nil {
+			uses += " / " + strconv.Itoa(*it.MaxUses)
+		} else {
+			uses += " / unlimited"
+		}
+		out = append(out, inviteLinkRowVM{
+			ID:      it.ID,
+			Role:    string(it.Role),
+			Uses:    uses,
+			Expires: it.ExpiresAt.In(loc).Format("2006-01-02 15:04"),
+		})
+	}
+	return out
+}
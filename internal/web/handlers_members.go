@@ -0,0 +1,314 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type memberRowVM struct {
+	UserID       string
+	Email        string
+	Name         string
+	Role         string
+	CustomRoleID string
+	CustomRole   string
+	Joined       string
+	IsSelf       bool
+}
+
+type customRoleVM struct {
+	ID          string
+	Name        string
+	Permissions string
+	Categories  string
+	TxTypes     string
+}
+
+func (h *Handlers) GetMembersPage(c *gin.Context) {
+	if h.Workspaces == nil {
+		c.String(http.StatusInternalServerError, "workspaces service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+	userID := c.GetString(auth.CtxUserIDKey)
+
+	members, err := h.Workspaces.ListMembers(c.Request.Context(), wsID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list members")
+		return
+	}
+
+	emails := make(map[string]string, len(members))
+	for _, m := range members {
+		emails[m.UserID] = m.Email
+	}
+
+	rows := make([]memberRowVM, 0, len(members))
+	for _, m := range members {
+		rows = append(rows, memberRowVM{
+			UserID:       m.UserID,
+			Email:        m.Email,
+			Name:         optionalString(m.Name),
+			Role:         string(m.Role),
+			CustomRoleID: optionalString(m.CustomRoleID),
+			CustomRole:   optionalString(m.CustomRoleName),
+			Joined:       m.CreatedAt.In(workspaces.GetLocation(c)).Format("2006-01-02"),
+			IsSelf:       m.UserID == userID,
+		})
+	}
+
+	isOwner := currentRole(c) == workspaces.RoleOwner
+
+	h.render(c, "app/members.html", gin.H{
+		"Title":     "Members",
+		"BodyClass": "app-dark",
+		"Flash":     c.Query("flash"),
+		"Workspace": workspaceFromContext(c),
+		"IsOwner":   isOwner,
+		"CanManage": currentAccess(c).Has(workspaces.PermMembersManage),
+		"Rows":      rows,
+		"Invites":   h.pendingInvitations(c, wsID),
+		"Links":     h.activeInviteLinks(c, wsID),
+		"NewLink":   takeNewInviteLinkCookie(c, h.CookieCfg),
+		"Transfer":  h.pendingTransferVM(c, wsID, userID, emails),
+		"Roles":     []workspaces.Role{workspaces.RoleOwner, workspaces.RoleMember, workspaces.RoleViewer},
+
+		"CustomRoles":    h.customRoleVMs(c, wsID),
+		"AllPermissions": workspaces.AllPermissions,
+		"Categories":     h.roleCategoryOptions(c, wsID, isOwner),
+	})
+}
+
+func (h *Handlers) PostAddMember(c *gin.Context) {
+	wsID, ok := h.requireMembersManager(c)
+	if !ok {
+		return
+	}
+
+	email := strings.TrimSpace(c.PostForm("email"))
+	role := workspaces.Role(strings.TrimSpace(c.PostForm("role")))
+	if email == "" {
+		redirectMembers(c, "Email is required")
+		return
+	}
+
+	actorID := c.GetString(auth.CtxUserIDKey)
+	err := h.Workspaces.AddMemberByEmail(c.Request.Context(), wsID, actorID, email, role)
+	if err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Member added")
+}
+
+func (h *Handlers) PostUpdateMemberRole(c *gin.Context) {
+	wsID, ok := h.requireMembersManager(c)
+	if !ok {
+		return
+	}
+
+	actorID := c.GetString(auth.CtxUserIDKey)
+	targetID := strings.TrimSpace(c.Param("userId"))
+	role := workspaces.Role(strings.TrimSpace(c.PostForm("role")))
+
+	err := h.Workspaces.UpdateMemberRole(c.Request.Context(), wsID, actorID, targetID, role)
+	if err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Role updated")
+}
+
+func (h *Handlers) PostRemoveMember(c *gin.Context) {
+	wsID, ok := h.requireMembersManager(c)
+	if !ok {
+		return
+	}
+
+	actorID := c.GetString(auth.CtxUserIDKey)
+	targetID := strings.TrimSpace(c.Param("userId"))
+
+	err := h.Workspaces.RemoveMember(c.Request.Context(), wsID, actorID, targetID)
+	if err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Member removed")
+}
+
+func (h *Handlers) requireOwner(c *gin.Context) (string, bool) {
+	wsID, ok := h.membersWorkspaceID(c)
+	if !ok {
+		return "", false
+	}
+	if currentRole(c) != workspaces.RoleOwner {
+		redirectMembers(c, "Only owners can do this")
+		return "", false
+	}
+	return wsID, true
+}
+
+func (h *Handlers) requireMembersManager(c *gin.Context) (string, bool) {
+	wsID, ok := h.membersWorkspaceID(c)
+	if !ok {
+		return "", false
+	}
+	if !currentAccess(c).Has(workspaces.PermMembersManage) {
+		redirectMembers(c, "Your role does not allow managing members")
+		return "", false
+	}
+	return wsID, true
+}
+
+func (h *Handlers) membersWorkspaceID(c *gin.Context) (string, bool) {
+	if h.Workspaces == nil {
+		c.String(http.StatusInternalServerError, "workspaces service is not configured")
+		return "", false
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return "", false
+	}
+	return wsID, true
+}
+
+func currentRole(c *gin.Context) workspaces.Role {
+	v, _ := c.Get(workspaces.CtxWorkspaceRoleKey)
+	switch r := v.(type) {
+	case workspaces.Role:
+		return r
+	case string:
+		return workspaces.Role(r)
+	default:
+		return ""
+	}
+}
+
+func memberErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, workspaces.ErrUserNotFound):
+		return "No user with this email is registered; send an invitation instead"
+	case errors.Is(err, workspaces.ErrAlreadyMember):
+		return "This user is already a member"
+	case errors.Is(err, workspaces.ErrInvalidRole):
+		return "Role must be owner, member or viewer"
+	case errors.Is(err, workspaces.ErrLastOwner):
+		return "The workspace must keep at least one owner"
+	case errors.Is(err, workspaces.ErrCannotSelfDemote):
+		return "You cannot change your own owner role; transfer ownership instead"
+	case errors.Is(err, workspaces.ErrTransferNotFound):
+		return "There is no pending ownership transfer"
+	case errors.Is(err, workspaces.ErrInvalidTransferTarget):
+		return "Pick another member who is not an owner yet"
+	case errors.Is(err, workspaces.ErrNotTransferTarget):
+		return "This ownership transfer is addressed to another member"
+	case errors.Is(err, workspaces.ErrOwnerRoleRequired):
+		return "Only owners can grant the owner role or manage other owners"
+	case errors.Is(err, workspaces.ErrInvalidName):
+		return "Role name is required (up to 50 characters)"
+	case errors.Is(err, workspaces.ErrInvalidPermission):
+		return "Unknown permission"
+	case errors.Is(err, workspaces.ErrInvalidCategories):
+		return "Pick categories from this workspace"
+	case errors.Is(err, workspaces.ErrInvalidTxTypes):
+		return "Transaction types must be income or expense"
+	case errors.Is(err, workspaces.ErrCustomRoleExists):
+		return "A role with this name already exists"
+	case errors.Is(err, workspaces.ErrCustomRoleNotFound):
+		return "Role not found"
+	case errors.Is(err, workspaces.ErrCannotAssignToOwner):
+		return "Owners always have full access; custom roles apply to other members"
+	case errors.Is(err, pgx.ErrNoRows):
+		return "Member not found"
+	default:
+		return "Something went wrong, please try again"
+	}
+}
+
+func redirectMembers(c *gin.Context, flash string) {
+	c.Redirect(http.StatusSeeOther, "/app/members?flash="+url.QueryEscape(flash))
+}
+
+type transferVM struct {
+	FromEmail string
+	ToEmail   string
+	ForMe     bool
+}
+
+func (h *Handlers) pendingTransferVM(c *gin.Context, wsID, userID string, emails map[string]string) *transferVM {
+	t, err := h.Workspaces.PendingTransfer(c.Request.Context(), wsID)
+	if err != nil {
+		return nil
+	}
+	return &transferVM{
+		FromEmail: emails[t.FromUserID],
+		ToEmail:   emails[t.ToUserID],
+		ForMe:     t.ToUserID == userID,
+	}
+}
+
+func (h *Handlers) PostNominateOwner(c *gin.Context) {
+	wsID, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	actorID := c.GetString(auth.CtxUserIDKey)
+	targetID := strings.TrimSpace(c.PostForm("user_id"))
+
+	if _, err := h.Workspaces.NominateOwner(c.Request.Context(), wsID, actorID, targetID); err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Ownership transfer requested; waiting for the member to accept")
+}
+
+func (h *Handlers) PostCancelTransfer(c *gin.Context) {
+	wsID, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Workspaces.CancelTransfer(c.Request.Context(), wsID); err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Ownership transfer cancelled")
+}
+
+func (h *Handlers) PostAcceptTransfer(c *gin.Context) {
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	userID := c.GetString(auth.CtxUserIDKey)
+
+	if err := h.Workspaces.AcceptTransfer(c.Request.Context(), wsID, userID); err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "You are now an owner of this workspace")
+}
+
+func (h *Handlers) PostDeclineTransfer(c *gin.Context) {
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	userID := c.GetString(auth.CtxUserIDKey)
+
+	if err := h.Workspaces.DeclineTransfer(c.Request.Context(), wsID, userID); err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Ownership transfer declined")
+}
@@ -0,0 +1,257 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+// stubWorkspaces records member writes and fails them with err. Methods the
+// tests do not override panic through the nil embedded interface.
+type stubWorkspaces struct {
+	WorkspacesService
+	err   error
+	calls []string
+}
+
+func (s *stubWorkspaces) AddMemberByEmail(_ context.Context, _, _, email string, role workspaces.Role) error {
+	s.calls = append(s.calls, "add "+email+" "+string(role))
+	return s.err
+}
+
+func (s *stubWorkspaces) UpdateMemberRole(_ context.Context, _, _, target string, role workspaces.Role) error {
+	s.calls = append(s.calls, "role "+target+" "+string(role))
+	return s.err
+}
+
+func (s *stubWorkspaces) RemoveMember(_ context.Context, _, _, target string) error {
+	s.calls = append(s.calls, "remove "+target)
+	return s.err
+}
+
+type stubInvitations struct {
+	InvitationsService
+	err     error
+	created workspaces.CreatedInvitation
+	pending []workspaces.Invitation
+	calls   []string
+}
+
+func (s *stubInvitations) Create(_ context.Context, _, _, email string, role workspaces.Role) (workspaces.CreatedInvitation, error) {
+	s.calls = append(s.calls, "invite "+email+" "+string(role))
+	return s.created, s.err
+}
+
+func (s *stubInvitations) Revoke(_ context.Context, _, id string) error {
+	s.calls = append(s.calls, "revoke "+id)
+	return s.err
+}
+
+func (s *stubInvitations) ListPending(context.Context, string) ([]workspaces.Invitation, error) {
+	s.calls = append(s.calls, "list")
+	return s.pending, s.err
+}
+
+// membersRequest builds a POST form request as the workspace middleware
+// would leave it for a member holding access.
+func membersRequest(access workspaces.Access, params gin.Params, form url.Values) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Params = params
+	c.Set(auth.CtxUserIDKey, "u1")
+	c.Set(workspaces.CtxWorkspaceIDKey, "ws1")
+	c.Set(workspaces.CtxWorkspaceRoleKey, access.Role)
+	c.Set(workspaces.CtxAccessKey, access)
+	return c, w
+}
+
+// membersFlash returns the flash message of a redirect to the members page.
+func membersFlash(t *testing.T, c *gin.Context, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	c.Writer.WriteHeaderNow()
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusSeeOther)
+	}
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil || loc.Path != "/app/members" {
+		t.Fatalf("Location = %q, want /app/members", w.Header().Get("Location"))
+	}
+	return loc.Query().Get("flash")
+}
+
+func TestMembersWritesRequireManager(t *testing.T) {
+	target := gin.Params{{Key: "userId", Value: "u2"}, {Key: "id", Value: "inv1"}}
+	form := url.Values{"email": {"a@example.com"}, "role": {"viewer"}}
+	custom := workspaces.Access{Role: workspaces.RoleMember, Permissions: []workspaces.Permission{workspaces.PermTxRead, workspaces.PermTxWrite}}
+
+	actions := map[string]func(h *Handlers, c *gin.Context){
+		"add member":        (*Handlers).PostAddMember,
+		"change role":       (*Handlers).PostUpdateMemberRole,
+		"remove member":     (*Handlers).PostRemoveMember,
+		"create invitation": (*Handlers).PostCreateInvitation,
+		"revoke invitation": (*Handlers).PostRevokeInvitation,
+	}
+	for _, access := range []workspaces.Access{workspaces.NewPresetAccess(workspaces.RoleMember), workspaces.NewPresetAccess(workspaces.RoleViewer), custom} {
+		for name, action := range actions {
+			t.Run(string(access.Role)+"/"+name, func(t *testing.T) {
+				ws, inv := &stubWorkspaces{}, &stubInvitations{}
+				h := &Handlers{Workspaces: ws, Invitations: inv}
+				c, w := membersRequest(access, target, form)
+
+				action(h, c)
+
+				if got := membersFlash(t, c, w); got != "Your role does not allow managing members" {
+					t.Errorf("flash = %q", got)
+				}
+				if len(ws.calls) > 0 || len(inv.calls) > 0 {
+					t.Errorf("service called for a non-manager: %v %v", ws.calls, inv.calls)
+				}
+			})
+		}
+	}
+}
+
+func TestMembersWritesByManager(t *testing.T) {
+	manager := workspaces.Access{Role: workspaces.RoleMember, Permissions: []workspaces.Permission{workspaces.PermMembersManage}}
+	tests := []struct {
+		name      string
+		action    func(h *Handlers, c *gin.Context)
+		target    string
+		form      url.Values
+		err       error
+		wantCall  string
+		wantFlash string
+	}{
+		{name: "add", action: (*Handlers).PostAddMember, form: url.Values{"email": {" a@example.com "}, "role": {"viewer"}}, wantCall: "add a@example.com viewer", wantFlash: "Member added"},
+		{name: "add without email", action: (*Handlers).PostAddMember, form: url.Values{"role": {"viewer"}}, wantFlash: "Email is required"},
+		{name: "add unknown user", action: (*Handlers).PostAddMember, form: url.Values{"email": {"a@example.com"}, "role": {"member"}}, err: workspaces.ErrUserNotFound, wantCall: "add a@example.com member", wantFlash: "No user with this email is registered; send an invitation instead"},
+		{name: "owner demotes themselves", action: (*Handlers).PostUpdateMemberRole, target: "u1", form: url.Values{"role": {"member"}}, err: workspaces.ErrCannotSelfDemote, wantCall: "role u1 member", wantFlash: "You cannot change your own owner role; transfer ownership instead"},
+		{name: "promote without owner role", action: (*Handlers).PostUpdateMemberRole, form: url.Values{"role": {"owner"}}, err: workspaces.ErrOwnerRoleRequired, wantCall: "role u2 owner", wantFlash: "Only owners can grant the owner role or manage other owners"},
+		{name: "role updated", action: (*Handlers).PostUpdateMemberRole, form: url.Values{"role": {"viewer"}}, wantCall: "role u2 viewer", wantFlash: "Role updated"},
+		{name: "remove last owner", action: (*Handlers).PostRemoveMember, err: workspaces.ErrLastOwner, wantCall: "remove u2", wantFlash: "The workspace must keep at least one owner"},
+		{name: "remove gone member", action: (*Handlers).PostRemoveMember, err: pgx.ErrNoRows, wantCall: "remove u2", wantFlash: "Member not found"},
+		{name: "removed", action: (*Handlers).PostRemoveMember, wantCall: "remove u2", wantFlash: "Member removed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target
+			if target == "" {
+				target = "u2"
+			}
+			ws := &stubWorkspaces{err: tt.err}
+			h := &Handlers{Workspaces: ws}
+			c, w := membersRequest(manager, gin.Params{{Key: "userId", Value: target}}, tt.form)
+
+			tt.action(h, c)
+
+			if got := membersFlash(t, c, w); got != tt.wantFlash {
+				t.Errorf("flash = %q, want %q", got, tt.wantFlash)
+			}
+			if tt.wantCall == "" && len(ws.calls) > 0 {
+				t.Errorf("calls = %v, want none", ws.calls)
+			}
+			if tt.wantCall != "" && (len(ws.calls) != 1 || ws.calls[0] != tt.wantCall) {
+				t.Errorf("calls = %v, want [%s]", ws.calls, tt.wantCall)
+			}
+		})
+	}
+}
+
+func TestPendingInvitationsOnlyForManagers(t *testing.T) {
+	inv := &stubInvitations{pending: []workspaces.Invitation{{ID: "inv1", Email: "a@example.com", Role: workspaces.RoleViewer}}}
+	h := &Handlers{Workspaces: &stubWorkspaces{}, Invitations: inv}
+
+	c, _ := membersRequest(workspaces.NewPresetAccess(workspaces.RoleMember), nil, nil)
+	if got := h.pendingInvitations(c, "ws1"); got != nil || len(inv.calls) > 0 {
+		t.Errorf("non-manager saw invitations %v (calls %v)", got, inv.calls)
+	}
+
+	c, _ = membersRequest(workspaces.NewPresetAccess(workspaces.RoleOwner), nil, nil)
+	got := h.pendingInvitations(c, "ws1")
+	if len(got) != 1 || got[0].ID != "inv1" || got[0].Email != "a@example.com" || got[0].Role != "viewer" {
+		t.Errorf("owner invitations = %+v", got)
+	}
+}
+
+func TestMemberErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: workspaces.ErrAlreadyMember, want: "This user is already a member"},
+		{err: workspaces.ErrInvalidRole, want: "Role must be owner, member or viewer"},
+		{err: workspaces.ErrCannotSelfDemote, want: "You cannot change your own owner role; transfer ownership instead"},
+		{err: fmt.Errorf("update: %w", workspaces.ErrLastOwner), want: "The workspace must keep at least one owner"},
+		{err: workspaces.ErrInvalidTxTypes, want: "Transaction types must be income or expense"},
+		{err: workspaces.ErrCannotAssignToOwner, want: "Owners always have full access; custom roles apply to other members"},
+		{err: errors.New("connection reset"), want: "Something went wrong, please try again"},
+	}
+	for _, tt := range tests {
+		if got := memberErrorMessage(tt.err); got != tt.want {
+			t.Errorf("memberErrorMessage(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+
+	if got := invitationErrorMessage(workspaces.ErrInvalidEmail); got != "Enter a valid email address" {
+		t.Errorf("invitationErrorMessage(ErrInvalidEmail) = %q", got)
+	}
+	if got := invitationErrorMessage(workspaces.ErrAlreadyMember); got != "This user is already a member" {
+		t.Errorf("invitationErrorMessage should fall back to member messages, got %q", got)
+	}
+}
+
+func TestPostCreateInvitationKeepsTokenOutOfURL(t *testing.T) {
+	owner := workspaces.NewPresetAccess(workspaces.RoleOwner)
+	form := url.Values{"email": {"a@example.com"}, "role": {"member"}}
+
+	t.Run("dev token", func(t *testing.T) {
+		inv := &stubInvitations{created: workspaces.CreatedInvitation{
+			Invitation: workspaces.Invitation{Email: "a@example.com"},
+			Token:      "secret-token",
+			EmailSent:  true,
+		}}
+		c, w := membersRequest(owner, nil, form)
+
+		(&Handlers{Workspaces: &stubWorkspaces{}, Invitations: inv}).PostCreateInvitation(c)
+
+		if got := membersFlash(t, c, w); got != "Invitation sent to a@example.com. Dev link below" {
+			t.Errorf("flash = %q", got)
+		}
+		if loc := w.Header().Get("Location"); strings.Contains(loc, "secret-token") {
+			t.Errorf("token leaked into the redirect URL %q", loc)
+		}
+		cookie := w.Header().Get("Set-Cookie")
+		if !strings.HasPrefix(cookie, NewInviteLinkCookie+"=") || !strings.Contains(cookie, url.QueryEscape("/invite?token=secret-token")) ||
+			!strings.Contains(cookie, "Path=/app/members") || !strings.Contains(cookie, "HttpOnly") {
+			t.Errorf("Set-Cookie = %q, want the dev link in the members-page cookie", cookie)
+		}
+	})
+
+	t.Run("prod", func(t *testing.T) {
+		inv := &stubInvitations{created: workspaces.CreatedInvitation{Invitation: workspaces.Invitation{Email: "a@example.com"}}}
+		c, w := membersRequest(owner, nil, form)
+
+		(&Handlers{Workspaces: &stubWorkspaces{}, Invitations: inv}).PostCreateInvitation(c)
+
+		if got := membersFlash(t, c, w); got != "Invitation created, but the email could not be sent" {
+			t.Errorf("flash = %q", got)
+		}
+		if cookie := w.Header().Get("Set-Cookie"); cookie != "" {
+			t.Errorf("Set-Cookie = %q, want none without a token", cookie)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package web
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+)
+
+type categoryOptionVM struct {
+	ID   string
+	Name string
+}
+
+func (h *Handlers) customRoleVMs(c *gin.Context, wsID string) []customRoleVM {
+	roles, err := h.Workspaces.ListCustomRoles(c.Request.Context(), wsID)
+	if err != nil {
+		return nil
+	}
+
+	names := map[string]string{}
+	if h.Categories != nil {
+		if cats, err := h.Categories.List(c.Request.Context(), wsID); err == nil {
+			for _, cat := range cats {
+				names[cat.ID] = cat.Name
+			}
+		}
+	}
+
+	out := make([]customRoleVM, 0, len(roles))
+	for _, r := range roles {
+		perms := make([]string, 0, len(r.Permissions))
+		for _, p := range r.Permissions {
+			perms = append(perms, string(p))
+		}
+		cats := make([]string, 0, len(r.CategoryIDs))
+		for _, id := range r.CategoryIDs {
+			if n, ok := names[id]; ok {
+				cats = append(cats, n)
+			}
+		}
+		out = append(out, customRoleVM{
+			ID:          r.ID,
+			Name:        r.Name,
+			Permissions: strings.Join(perms, ", "),
+			Categories:  strings.Join(cats, ", "),
+			TxTypes:     strings.Join(r.TxTypes, ", "),
+		})
+	}
+	return out
+}
+
+func (h *Handlers) roleCategoryOptions(c *gin.Context, wsID string, isOwner bool) []categoryOptionVM {
+	if !isOwner || h.Categories == nil {
+		return nil
+	}
+	cats, err := h.Categories.List(c.Request.Context(), wsID)
+	if err != nil {
+		return nil
+	}
+	out := make([]categoryOptionVM, 0, len(cats))
+	for _, cat := range cats {
+		out = append(out, categoryOptionVM{ID: cat.ID, Name: cat.Name})
+	}
+	return out
+}
+
+func (h *Handlers) PostCreateCustomRole(c *gin.Context) {
+	wsID, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	_, err := h.Workspaces.CreateCustomRole(c.Request.Context(), wsID,
+		c.PostForm("name"), c.PostFormArray("permissions"), c.PostFormArray("category_ids"), c.PostFormArray("tx_types"))
+	if err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Role created")
+}
+
+func (h *Handlers) PostDeleteCustomRole(c *gin.Context) {
+	wsID, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Workspaces.DeleteCustomRole(c.Request.Context(), wsID, strings.TrimSpace(c.Param("id"))); err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Role deleted; its members fell back to their built-in role")
+}
+
+func (h *Handlers) PostAssignCustomRole(c *gin.Context) {
+	wsID, ok := h.requireOwner(c)
+	if !ok {
+		return
+	}
+
+	var roleID *string
+	if v := strings.TrimSpace(c.PostForm("role_id")); v != "" {
+		roleID = &v
+	}
+
+	targetID := strings.TrimSpace(c.Param("userId"))
+	if err := h.Workspaces.AssignCustomRole(c.Request.Context(), wsID, c.GetString(auth.CtxUserIDKey), targetID, roleID); err != nil {
+		redirectMembers(c, memberErrorMessage(err))
+		return
+	}
+	redirectMembers(c, "Custom role updated")
+}
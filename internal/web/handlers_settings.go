@@ -0,0 +1,158 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+func (h *Handlers) GetSettingsPage(c *gin.Context) {
+	h.renderSettings(c, c.Query("flash"), "")
+}
+
+func (h *Handlers) renderSettings(c *gin.Context, flash, deleteToken string) {
+	h.render(c, "app/settings.html", gin.H{
+		"Title":       "Workspace settings",
+		"BodyClass":   "app-dark",
+		"Flash":       flash,
+		"Workspace":   workspaceFromContext(c),
+		"IsOwner":     currentRole(c) == workspaces.RoleOwner,
+		"DeleteToken": deleteToken,
+		"GraceDays":   int(workspaces.DeletionGracePeriod.Hours() / 24),
+	})
+}
+
+func (h *Handlers) PostUpdateSettings(c *gin.Context) {
+	wsID, ok := h.requireSettingsOwner(c)
+	if !ok {
+		return
+	}
+
+	name := c.PostForm("name")
+	currency := c.PostForm("default_currency")
+	timezone := strings.TrimSpace(c.PostForm("timezone"))
+
+	if timezone != "" {
+		if _, err := tzx.Normalize(timezone); err != nil {
+			redirectSettings(c, "Unknown timezone (use e.g. Europe/Kyiv)")
+			return
+		}
+	}
+
+	if err := h.Workspaces.UpdateSettings(c.Request.Context(), wsID, &name, &currency); err != nil {
+		redirectSettings(c, settingsErrorMessage(err))
+		return
+	}
+	if timezone != "" {
+		if err := h.Workspaces.UpdateTimezone(c.Request.Context(), wsID, timezone); err != nil {
+			redirectSettings(c, settingsErrorMessage(err))
+			return
+		}
+	}
+	redirectSettings(c, "Settings saved")
+}
+
+func (h *Handlers) PostRequestDeletion(c *gin.Context) {
+	wsID, ok := h.requireSettingsOwner(c)
+	if !ok {
+		return
+	}
+
+	token, _, err := h.Workspaces.RequestDeletion(c.Request.Context(), wsID)
+	if err != nil {
+		redirectSettings(c, settingsErrorMessage(err))
+		return
+	}
+	h.renderSettings(c, "Confirm below to delete this workspace", token)
+}
+
+func (h *Handlers) PostConfirmDeletion(c *gin.Context) {
+	wsID, ok := h.requireSettingsOwner(c)
+	if !ok {
+		return
+	}
+
+	purgeAt, err := h.Workspaces.ConfirmDeletion(c.Request.Context(), wsID, c.PostForm("confirm_token"))
+	if err != nil {
+		redirectSettings(c, settingsErrorMessage(err))
+		return
+	}
+
+	clearCurrentWorkspaceCookie(c, h.CookieCfg)
+	flash := "Workspace deleted. You can restore it until " + purgeAt.In(workspaces.GetLocation(c)).Format("2006-01-02")
+	c.Redirect(http.StatusSeeOther, "/app/workspaces?flash="+url.QueryEscape(flash))
+}
+
+func (h *Handlers) PostLeaveWorkspace(c *gin.Context) {
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	userID := c.GetString(auth.CtxUserIDKey)
+
+	if err := h.Workspaces.Leave(c.Request.Context(), wsID, userID); err != nil {
+		redirectSettings(c, settingsErrorMessage(err))
+		return
+	}
+
+	clearCurrentWorkspaceCookie(c, h.CookieCfg)
+	c.Redirect(http.StatusSeeOther, "/app/workspaces?flash="+url.QueryEscape("You left the workspace"))
+}
+
+func (h *Handlers) PostRestoreWorkspace(c *gin.Context) {
+	if h.Workspaces == nil {
+		c.String(http.StatusInternalServerError, "workspaces service is not configured")
+		return
+	}
+
+	userID := c.GetString(auth.CtxUserIDKey)
+	wsID := strings.TrimSpace(c.Param("id"))
+
+	flash := "Workspace restored"
+	if err := h.Workspaces.Restore(c.Request.Context(), wsID, userID); err != nil {
+		flash = "Could not restore workspace"
+		if errors.Is(err, pgx.ErrNoRows) {
+			flash = "Workspace not found or its grace period has ended"
+		}
+	}
+	c.Redirect(http.StatusSeeOther, "/app/workspaces?flash="+url.QueryEscape(flash))
+}
+
+func (h *Handlers) requireSettingsOwner(c *gin.Context) (string, bool) {
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return "", false
+	}
+	if currentRole(c) != workspaces.RoleOwner {
+		redirectSettings(c, "Only owners can change workspace settings")
+		return "", false
+	}
+	return wsID, true
+}
+
+func settingsErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, workspaces.ErrInvalidName):
+		return "Name is required (up to 100 characters)"
+	case errors.Is(err, workspaces.ErrInvalidCurrency):
+		return "Currency must be a 3-letter code, e.g. UAH"
+	case errors.Is(err, tzx.ErrInvalidTimezone):
+		return "Unknown timezone (use e.g. Europe/Kyiv)"
+	case errors.Is(err, workspaces.ErrInvalidDeletionToken):
+		return "Deletion confirmation expired, please try again"
+	case errors.Is(err, workspaces.ErrLastOwner):
+		return "You are the last owner; transfer ownership or delete the workspace instead"
+	default:
+		return memberErrorMessage(err)
+	}
+}
+
+func redirectSettings(c *gin.Context, flash string) {
+	c.Redirect(http.StatusSeeOther, "/app/settings?flash="+url.QueryEscape(flash))
+}
@@ -0,0 +1,232 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type stubSettings struct {
+	WorkspacesService
+	err     error
+	purgeAt time.Time
+	calls   []string
+}
+
+func (s *stubSettings) UpdateSettings(_ context.Context, _ string, name, currency *string) error {
+	s.calls = append(s.calls, "settings "+*name+" "+*currency)
+	return s.err
+}
+
+func (s *stubSettings) UpdateTimezone(_ context.Context, _, tz string) error {
+	s.calls = append(s.calls, "timezone "+tz)
+	return s.err
+}
+
+func (s *stubSettings) RequestDeletion(context.Context, string) (string, time.Time, error) {
+	s.calls = append(s.calls, "request deletion")
+	return "token", time.Time{}, s.err
+}
+
+func (s *stubSettings) ConfirmDeletion(_ context.Context, _, token string) (time.Time, error) {
+	s.calls = append(s.calls, "confirm "+token)
+	return s.purgeAt, s.err
+}
+
+func (s *stubSettings) Leave(_ context.Context, _, userID string) error {
+	s.calls = append(s.calls, "leave "+userID)
+	return s.err
+}
+
+// redirectTarget returns the path and flash message of a 303 redirect.
+func redirectTarget(t *testing.T, c *gin.Context, w *httptest.ResponseRecorder) (string, string) {
+	t.Helper()
+	c.Writer.WriteHeaderNow()
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusSeeOther)
+	}
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("Location = %q: %v", w.Header().Get("Location"), err)
+	}
+	return loc.Path, loc.Query().Get("flash")
+}
+
+func clearsCurrentWorkspace(w *httptest.ResponseRecorder) bool {
+	for _, v := range w.Header().Values("Set-Cookie") {
+		if strings.HasPrefix(v, CurrentWorkspaceCookie+"=;") && strings.Contains(v, "Max-Age=0") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSettingsWritesRequireOwner(t *testing.T) {
+	form := url.Values{"name": {"Home"}, "default_currency": {"UAH"}, "confirm_token": {"token"}}
+	actions := map[string]func(h *Handlers, c *gin.Context){
+		"update":         (*Handlers).PostUpdateSettings,
+		"request delete": (*Handlers).PostRequestDeletion,
+		"confirm delete": (*Handlers).PostConfirmDeletion,
+	}
+	// members:manage does not extend to workspace settings.
+	manager := workspaces.Access{Role: workspaces.RoleMember, Permissions: []workspaces.Permission{workspaces.PermMembersManage}}
+
+	for _, access := range []workspaces.Access{workspaces.NewPresetAccess(workspaces.RoleMember), workspaces.NewPresetAccess(workspaces.RoleViewer), manager} {
+		for name, action := range actions {
+			t.Run(string(access.Role)+"/"+name, func(t *testing.T) {
+				ws := &stubSettings{}
+				c, w := membersRequest(access, nil, form)
+
+				action(&Handlers{Workspaces: ws}, c)
+
+				path, flash := redirectTarget(t, c, w)
+				if path != "/app/settings" || flash != "Only owners can change workspace settings" {
+					t.Errorf("redirect = %s %q", path, flash)
+				}
+				if len(ws.calls) > 0 {
+					t.Errorf("service called for a non-owner: %v", ws.calls)
+				}
+			})
+		}
+	}
+}
+
+func TestPostUpdateSettings(t *testing.T) {
+	owner := workspaces.NewPresetAccess(workspaces.RoleOwner)
+	tests := []struct {
+		name      string
+		form      url.Values
+		err       error
+		wantCalls []string
+		wantFlash string
+	}{
+		{
+			name:      "rename and change currency",
+			form:      url.Values{"name": {"Home"}, "default_currency": {"usd"}},
+			wantCalls: []string{"settings Home usd"},
+			wantFlash: "Settings saved",
+		},
+		{
+			name:      "with timezone",
+			form:      url.Values{"name": {"Home"}, "default_currency": {"UAH"}, "timezone": {"Europe/Kyiv"}},
+			wantCalls: []string{"settings Home UAH", "timezone Europe/Kyiv"},
+			wantFlash: "Settings saved",
+		},
+		{
+			name:      "unknown timezone",
+			form:      url.Values{"name": {"Home"}, "default_currency": {"UAH"}, "timezone": {"Mars/Olympus"}},
+			wantFlash: "Unknown timezone (use e.g. Europe/Kyiv)",
+		},
+		{
+			name:      "invalid name",
+			form:      url.Values{"name": {""}, "default_currency": {"UAH"}},
+			err:       workspaces.ErrInvalidName,
+			wantCalls: []string{"settings  UAH"},
+			wantFlash: "Name is required (up to 100 characters)",
+		},
+		{
+			name:      "invalid currency",
+			form:      url.Values{"name": {"Home"}, "default_currency": {"dollars"}},
+			err:       workspaces.ErrInvalidCurrency,
+			wantCalls: []string{"settings Home dollars"},
+			wantFlash: "Currency must be a 3-letter code, e.g. UAH",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &stubSettings{err: tt.err}
+			c, w := membersRequest(owner, nil, tt.form)
+
+			(&Handlers{Workspaces: ws}).PostUpdateSettings(c)
+
+			path, flash := redirectTarget(t, c, w)
+			if path != "/app/settings" || flash != tt.wantFlash {
+				t.Errorf("redirect = %s %q, want /app/settings %q", path, flash, tt.wantFlash)
+			}
+			if strings.Join(ws.calls, "; ") != strings.Join(tt.wantCalls, "; ") {
+				t.Errorf("calls = %v, want %v", ws.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPostConfirmDeletion(t *testing.T) {
+	owner := workspaces.NewPresetAccess(workspaces.RoleOwner)
+
+	t.Run("expired or wrong token", func(t *testing.T) {
+		ws := &stubSettings{err: workspaces.ErrInvalidDeletionToken}
+		c, w := membersRequest(owner, nil, url.Values{"confirm_token": {"stale"}})
+
+		(&Handlers{Workspaces: ws}).PostConfirmDeletion(c)
+
+		path, flash := redirectTarget(t, c, w)
+		if path != "/app/settings" || flash != "Deletion confirmation expired, please try again" {
+			t.Errorf("redirect = %s %q", path, flash)
+		}
+		if clearsCurrentWorkspace(w) {
+			t.Error("current workspace cookie cleared although nothing was deleted")
+		}
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		ws := &stubSettings{purgeAt: time.Date(2024, 6, 9, 23, 30, 0, 0, time.UTC)}
+		c, w := membersRequest(owner, nil, url.Values{"confirm_token": {"token"}})
+		c.Set(workspaces.CtxLocationKey, time.FixedZone("UTC+3", 3*60*60))
+
+		(&Handlers{Workspaces: ws}).PostConfirmDeletion(c)
+
+		path, flash := redirectTarget(t, c, w)
+		if path != "/app/workspaces" || flash != "Workspace deleted. You can restore it until 2024-06-10" {
+			t.Errorf("redirect = %s %q", path, flash)
+		}
+		if len(ws.calls) != 1 || ws.calls[0] != "confirm token" {
+			t.Errorf("calls = %v", ws.calls)
+		}
+		if !clearsCurrentWorkspace(w) {
+			t.Error("current workspace cookie not cleared")
+		}
+	})
+}
+
+func TestPostLeaveWorkspace(t *testing.T) {
+	t.Run("last owner", func(t *testing.T) {
+		ws := &stubSettings{err: workspaces.ErrLastOwner}
+		c, w := membersRequest(workspaces.NewPresetAccess(workspaces.RoleOwner), nil, nil)
+
+		(&Handlers{Workspaces: ws}).PostLeaveWorkspace(c)
+
+		path, flash := redirectTarget(t, c, w)
+		if path != "/app/settings" || flash != "You are the last owner; transfer ownership or delete the workspace instead" {
+			t.Errorf("redirect = %s %q", path, flash)
+		}
+		if clearsCurrentWorkspace(w) {
+			t.Error("current workspace cookie cleared although the owner stayed")
+		}
+	})
+
+	t.Run("viewer leaves", func(t *testing.T) {
+		ws := &stubSettings{}
+		c, w := membersRequest(workspaces.NewPresetAccess(workspaces.RoleViewer), nil, nil)
+
+		(&Handlers{Workspaces: ws}).PostLeaveWorkspace(c)
+
+		path, flash := redirectTarget(t, c, w)
+		if path != "/app/workspaces" || flash != "You left the workspace" {
+			t.Errorf("redirect = %s %q", path, flash)
+		}
+		if len(ws.calls) != 1 || ws.calls[0] != "leave u1" {
+			t.Errorf("calls = %v", ws.calls)
+		}
+		if !clearsCurrentWorkspace(w) {
+			t.Error("current workspace cookie not cleared")
+		}
+	})
+}
@@ -1,8 +1,12 @@
 package web
 
 import (
+	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -10,19 +14,78 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/categories"
 	"github.com/skelbigo/FinanceTracker/internal/transactions"
 	"github.com/skelbigo/FinanceTracker/internal/workspaces"
 )
 
 type txFiltersVM struct {
-	From       string
-	To         string
-	Type       string
-	CategoryID string
-	Q          string
-	Sort       string
-	Limit      int
-	Offset     int
+	From          string
+	To            string
+	Type          string
+	CategoryIDs   []string
+	Uncategorized bool
+	AmountMin     string
+	AmountMax     string
+	Currency      string
+	Tags          string
+	TagsMatch     string
+	UserID        string
+	CreatedFrom   string
+	CreatedTo     string
+	UpdatedFrom   string
+	UpdatedTo     string
+	Q             string
+	Sort          string
+	Limit         int
+	Offset        int
+	Cursor        string
+}
+
+// Advanced reports whether any filter behind "More filters" is set, so the
+// section starts open.
+func (vm txFiltersVM) Advanced() bool {
+	return vm.Uncategorized || vm.AmountMin != "" || vm.AmountMax != "" || vm.Currency != "" || vm.Tags != "" ||
+		vm.UserID != "" || vm.CreatedFrom != "" || vm.CreatedTo != "" || vm.UpdatedFrom != "" || vm.UpdatedTo != ""
+}
+
+// values encodes the filters (not the page position) as form values, e.g.
+// for the export link.
+func (vm txFiltersVM) values() url.Values {
+	v := url.Values{}
+	set := func(key, val string) {
+		if val != "" {
+			v.Set(key, val)
+		}
+	}
+	set("from", vm.From)
+	set("to", vm.To)
+	set("type", vm.Type)
+	for _, id := range vm.CategoryIDs {
+		v.Add("category_id", id)
+	}
+	if vm.Uncategorized {
+		v.Set("uncategorized", "on")
+	}
+	set("amount_min", vm.AmountMin)
+	set("amount_max", vm.AmountMax)
+	set("currency", vm.Currency)
+	set("tags", vm.Tags)
+	set("tags_match", vm.TagsMatch)
+	set("user_id", vm.UserID)
+	set("created_from", vm.CreatedFrom)
+	set("created_to", vm.CreatedTo)
+	set("updated_from", vm.UpdatedFrom)
+	set("updated_to", vm.UpdatedTo)
+	set("q", vm.Q)
+	set("sort", vm.Sort)
+	return v
+}
+
+type filterOptionVM struct {
+	ID       string
+	Name     string
+	Selected bool
 }
 
 type txRowVM struct {
@@ -34,6 +97,7 @@ type txRowVM struct {
 	Currency string
 	Note     string
 	Tags     string
+	Snippet  template.HTML
 	CSRF     string
 }
 
@@ -46,6 +110,13 @@ type txRowEditVM struct {
 	Currency   string
 	Note       string
 	Tags       string
+	Splits     []splitVM
+}
+
+type splitVM struct {
+	Category string
+	Amount   string
+	Note     string
 }
 
 type txPaginationVM struct {
@@ -53,6 +124,8 @@ type txPaginationVM struct {
 	ShowNext   bool
 	PrevOffset int
 	NextOffset int
+	PrevCursor string
+	NextCursor string
 	Info       string
 }
 
@@ -73,8 +146,24 @@ func (h *Handlers) GetTransactionsPage(c *gin.Context) {
 		c.String(http.StatusInternalServerError, "could not list categories")
 		return
 	}
+	active := make([]categories.Category, 0, len(cats))
+	for _, cat := range cats {
+		if !cat.Archived() {
+			active = append(active, cat)
+		}
+	}
 
-	filters := readTxFiltersFromQuery(c)
+	viewID := strings.TrimSpace(c.Query("view_id"))
+	filters, err := h.readTxFiltersWithView(c, wsID)
+	if err != nil {
+		msg, ok := viewErrorMessage(err)
+		if !ok {
+			c.String(http.StatusInternalServerError, "could not load view")
+			return
+		}
+		redirectTransactions(c, "", msg)
+		return
+	}
 	if filters.Sort == "" {
 		filters.Sort = "occurred_at_desc"
 	}
@@ -82,14 +171,42 @@ func (h *Handlers) GetTransactionsPage(c *gin.Context) {
 		filters.Limit = 20
 	}
 
+	catOptions := make([]filterOptionVM, 0, len(cats))
+	for _, cat := range cats {
+		catOptions = append(catOptions, filterOptionVM{ID: cat.ID, Name: cat.Name, Selected: slices.Contains(filters.CategoryIDs, cat.ID)})
+	}
+	var memberOptions []filterOptionVM
+	if h.Workspaces != nil {
+		members, err := h.Workspaces.ListMembers(c.Request.Context(), wsID)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "could not list members")
+			return
+		}
+		for _, m := range members {
+			memberOptions = append(memberOptions, filterOptionVM{ID: m.UserID, Name: m.Email, Selected: m.UserID == filters.UserID})
+		}
+	}
+
+	views, err := h.viewOptions(c, wsID, viewID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list views")
+		return
+	}
+
 	data := gin.H{
-		"Title":           "Transactions",
-		"BodyClass":       "app-dark",
-		"Flash":           c.Query("flash"),
-		"Workspace":       workspaceFromContext(c),
-		"Categories":      cats,
-		"Filters":         filters,
-		"DefaultCurrency": "UAH",
+		"Title":            "Transactions",
+		"BodyClass":        "app-dark",
+		"Flash":            c.Query("flash"),
+		"Workspace":        workspaceFromContext(c),
+		"Categories":       cats,
+		"FormCategories":   active,
+		"FilterCategories": catOptions,
+		"Members":          memberOptions,
+		"Filters":          filters,
+		"Views":            views,
+		"ViewID":           viewID,
+		"CanShareViews":    currentAccess(c).Has(workspaces.PermTxWrite),
+		"DefaultCurrency":  "UAH",
 	}
 
 	h.render(c, "app/transactions.html", data)
@@ -108,8 +225,9 @@ func (h *Handlers) GetTransactionsTable(c *gin.Context) {
 	}
 
 	filtersVM := readTxFiltersFromQuery(c)
+	loc := workspaces.GetLocation(c)
 
-	f, errList := buildTxListFilter(filtersVM)
+	f, errList := buildTxListFilter(filtersVM, loc)
 	if errList != nil {
 		c.Status(http.StatusBadRequest)
 		h.renderPartial(c, "tx_form_errors", gin.H{
@@ -140,20 +258,16 @@ func (h *Handlers) GetTransactionsTable(c *gin.Context) {
 	}
 	rows := make([]txRowVM, 0, len(result.Items))
 	for _, item := range result.Items {
-		rows = append(rows, txRowVM{
-			ID:       item.ID,
-			Occurred: item.OccurredAt.Format("2006-01-02"),
-			Type:     string(item.Type),
-			Category: categoryName(item.CategoryID, catNames),
-			Amount:   formatMinor(item.AmountMinor),
-			Currency: item.Currency,
-			Note:     optionalString(item.Note),
-			Tags:     strings.Join(item.Tags, ", "),
-			CSRF:     csrf,
-		})
+		rows = append(rows, newTxRowVM(item, catNames, loc, csrf))
 	}
 
-	p := buildPagination(filtersVM.Offset, result.Limit, len(result.Items), result.HasNext)
+	p := buildPagination(result.Offset, result.Limit, len(result.Items), result.HasNext)
+	p.ShowPrev = result.HasPrev
+	p.PrevCursor = result.PrevCursor
+	p.NextCursor = result.NextCursor
+	if f.Cursor != nil && len(result.Items) > 0 {
+		p.Info = fmt.Sprintf("Showing %d", len(result.Items))
+	}
 
 	filtersVM.Limit = result.Limit
 	filtersVM.Offset = result.Offset
@@ -162,9 +276,39 @@ func (h *Handlers) GetTransactionsTable(c *gin.Context) {
 		"Items":      rows,
 		"Pagination": p,
 		"Filters":    filtersVM,
+		"ExportURL":  template.URL("/app/transactions/export?" + filtersVM.values().Encode()),
 	})
 }
 
+// GetTransactionsExport downloads the transactions matching the filters bar
+// as CSV.
+func (h *Handlers) GetTransactionsExport(c *gin.Context) {
+	if h.Transactions == nil {
+		c.String(http.StatusInternalServerError, "transactions service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	loc := workspaces.GetLocation(c)
+	f, err := buildTxListFilter(readTxFiltersFromQuery(c), loc)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	f.Cursor = nil
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="transactions.csv"`)
+	if err := h.Transactions.WriteCSV(c.Request.Context(), wsID, f, loc, c.Writer); err != nil && !c.Writer.Written() {
+		c.String(http.StatusInternalServerError, "could not export transactions")
+	}
+}
+
 func (h *Handlers) PostCreateTransaction(c *gin.Context) {
 	if h.Categories == nil || h.Transactions == nil {
 		c.String(http.StatusInternalServerError, "categories/transactions service is not configured")
@@ -183,6 +327,7 @@ func (h *Handlers) PostCreateTransaction(c *gin.Context) {
 		return
 	}
 
+	loc := workspaces.GetLocation(c)
 	var errs []string
 
 	typ := transactions.NormalizeType(c.PostForm("type"))
@@ -200,15 +345,22 @@ func (h *Handlers) PostCreateTransaction(c *gin.Context) {
 		errs = append(errs, "Currency must be 3 uppercase letters (e.g. UAH)")
 	}
 
-	occurredAt, err := transactions.ParseOccurredAt(c.PostForm("occurred_at"))
+	occurredAt, err := transactions.ParseOccurredAtIn(c.PostForm("occurred_at"), loc)
 	if err != nil {
 		errs = append(errs, "Occurred at must be a valid date")
 	}
 
+	access := currentAccess(c)
+	if transactions.ValidateType(typ) && !access.CanUseTxType(string(typ)) {
+		errs = append(errs, fmt.Sprintf("Your role cannot record %s transactions", typ))
+	}
+
 	catRaw := strings.TrimSpace(c.PostForm("category_id"))
 	catID, err := transactions.NormalizeOptionalUUID(&catRaw)
 	if err != nil {
 		errs = append(errs, "Category id is invalid")
+	} else if !access.CanUseCategory(catID) {
+		errs = append(errs, "Your role cannot record transactions in this category")
 	}
 
 	noteRaw := strings.TrimSpace(c.PostForm("note"))
@@ -236,6 +388,11 @@ func (h *Handlers) PostCreateTransaction(c *gin.Context) {
 		Note:        note,
 		Tags:        tags,
 	})
+	if errors.Is(err, transactions.ErrCategoryArchived) {
+		c.Status(http.StatusUnprocessableEntity)
+		h.renderPartial(c, "tx_form_errors", gin.H{"Errors": []string{"This category is archived"}})
+		return
+	}
 	if err != nil {
 		c.String(http.StatusInternalServerError, "could not create transaction")
 		return
@@ -251,19 +408,7 @@ func (h *Handlers) PostCreateTransaction(c *gin.Context) {
 	if csrf == "" {
 		csrf = GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
 	}
-	row := txRowVM{
-		ID:       out.ID,
-		Occurred: out.OccurredAt.Format("2006-01-02"),
-		Type:     string(out.Type),
-		Category: categoryName(out.CategoryID, catNames),
-		Amount:   formatMinor(out.AmountMinor),
-		Currency: out.Currency,
-		Note:     optionalString(out.Note),
-		Tags:     strings.Join(out.Tags, ", "),
-		CSRF:     csrf,
-	}
-
-	h.renderPartial(c, "tx_create_response", gin.H{"Row": row})
+	h.renderPartial(c, "tx_create_response", gin.H{"Row": newTxRowVM(out, catNames, loc, csrf)})
 }
 
 func (h *Handlers) GetTransactionEdit(c *gin.Context) {
@@ -289,6 +434,11 @@ func (h *Handlers) GetTransactionEdit(c *gin.Context) {
 		c.String(http.StatusNotFound, "not found")
 		return
 	}
+	if !canWriteTx(c, tx) {
+		c.String(http.StatusForbidden, "your role does not allow editing this transaction")
+		return
+	}
+	loc := workspaces.GetLocation(c)
 
 	cats, err := h.Categories.List(c.Request.Context(), wsID)
 	if err != nil {
@@ -303,18 +453,20 @@ func (h *Handlers) GetTransactionEdit(c *gin.Context) {
 
 	row := txRowEditVM{
 		ID:         tx.ID,
-		Occurred:   tx.OccurredAt.Format("2006-01-02"),
+		Occurred:   tx.OccurredAt.In(loc).Format("2006-01-02"),
 		Type:       string(tx.Type),
 		CategoryID: catID,
 		Amount:     formatMinor(tx.AmountMinor),
 		Currency:   tx.Currency,
 		Note:       optionalString(tx.Note),
 		Tags:       strings.Join(tx.Tags, ", "),
+		Splits:     splitVMs(tx.Splits, categoryNames(cats)),
 	}
 
 	h.renderPartial(c, "tx_row_edit", gin.H{
-		"Row":        row,
-		"Categories": cats,
+		"Row":         row,
+		"Categories":  editCategories(cats, catID),
+		"Attachments": h.txAttachments(c, wsID, tx.ID, ""),
 	})
 }
 
@@ -335,7 +487,12 @@ func (h *Handlers) PostUpdateTransaction(c *gin.Context) {
 		c.String(http.StatusBadRequest, "missing id")
 		return
 	}
+	existing, ok := h.loadWritableTx(c, wsID, txID)
+	if !ok {
+		return
+	}
 
+	loc := workspaces.GetLocation(c)
 	var errs []string
 
 	typ := transactions.NormalizeType(c.PostForm("type"))
@@ -353,14 +510,14 @@ func (h *Handlers) PostUpdateTransaction(c *gin.Context) {
 		errs = append(errs, "Currency must be 3 uppercase letters (e.g. UAH)")
 	}
 
-	occurredAt, err := transactions.ParseOccurredAt(c.PostForm("occurred_at"))
+	occurredAt, err := transactions.ParseOccurredAtIn(c.PostForm("occurred_at"), loc)
 	if err != nil {
 		errs = append(errs, "Occurred at must be a valid date")
 	}
 
 	catRaw := strings.TrimSpace(c.PostForm("category_id"))
-	catIDPtr, err := transactions.NormalizeOptionalUUID(&catRaw)
-	if err != nil {
+	catIDPtr, catErr := transactions.NormalizeOptionalUUID(&catRaw)
+	if catErr != nil {
 		errs = append(errs, "Category id is invalid")
 	}
 
@@ -372,13 +529,35 @@ func (h *Handlers) PostUpdateTransaction(c *gin.Context) {
 		errs = append(errs, err.Error())
 	}
 
+	// Split lines are kept as they are unless the member removes the split;
+	// they must still add up to the amount.
+	splits := existing.Splits
+	if c.PostForm("clear_splits") != "" {
+		splits = nil
+	}
+	if len(splits) > 0 && minor != existing.AmountMinor {
+		errs = append(errs, "This transaction is split across categories: keep the amount or remove the split")
+	}
+
+	// A split keeps its categories on the lines, so the lines are what a
+	// category-limited role is checked against.
+	access := currentAccess(c)
+	switch {
+	case !transactions.ValidateType(typ), catErr != nil:
+		// already reported above
+	case !access.CanUseTxType(string(typ)):
+		errs = append(errs, fmt.Sprintf("Your role cannot record %s transactions", typ))
+	case !transactions.CanUseCategories(access, typ, catIDPtr, splits):
+		errs = append(errs, "Your role cannot record transactions in this category")
+	}
+
 	cats, errCats := h.Categories.List(c.Request.Context(), wsID)
 	if errCats != nil {
 		c.String(http.StatusInternalServerError, "could not list categories")
 		return
 	}
 
-	if len(errs) > 0 {
+	renderErrors := func(errs []string) {
 		catID := ""
 		if catIDPtr != nil {
 			catID = *catIDPtr
@@ -392,17 +571,22 @@ func (h *Handlers) PostUpdateTransaction(c *gin.Context) {
 			Currency:   strings.TrimSpace(c.PostForm("currency")),
 			Note:       optionalString(note),
 			Tags:       strings.TrimSpace(c.PostForm("tags")),
+			Splits:     splitVMs(existing.Splits, categoryNames(cats)),
 		}
 		c.Status(http.StatusUnprocessableEntity)
 		h.renderPartial(c, "tx_update_error", gin.H{
-			"Errors":     errs,
-			"Row":        row,
-			"Categories": cats,
+			"Errors":      errs,
+			"Row":         row,
+			"Categories":  editCategories(cats, catID),
+			"Attachments": h.txAttachments(c, wsID, txID, ""),
 		})
+	}
+	if len(errs) > 0 {
+		renderErrors(errs)
 		return
 	}
 
-	out, err := h.Transactions.Update(c.Request.Context(), transactions.Transaction{
+	out, err := h.Transactions.Update(c.Request.Context(), c.GetString(auth.CtxUserIDKey), transactions.Transaction{
 		WorkspaceID: wsID,
 		ID:          txID,
 		CategoryID:  catIDPtr,
@@ -412,7 +596,12 @@ func (h *Handlers) PostUpdateTransaction(c *gin.Context) {
 		OccurredAt:  occurredAt,
 		Note:        note,
 		Tags:        tags,
+		Splits:      splits,
 	})
+	if errors.Is(err, transactions.ErrCategoryArchived) {
+		renderErrors([]string{"This category is archived"})
+		return
+	}
 	if err != nil {
 		c.String(http.StatusInternalServerError, "could not update transaction")
 		return
@@ -427,19 +616,7 @@ func (h *Handlers) PostUpdateTransaction(c *gin.Context) {
 	if csrf == "" {
 		csrf = GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
 	}
-	row := txRowVM{
-		ID:       out.ID,
-		Occurred: out.OccurredAt.Format("2006-01-02"),
-		Type:     string(out.Type),
-		Category: categoryName(out.CategoryID, catNames),
-		Amount:   formatMinor(out.AmountMinor),
-		Currency: out.Currency,
-		Note:     optionalString(out.Note),
-		Tags:     strings.Join(out.Tags, ", "),
-		CSRF:     csrf,
-	}
-
-	h.renderPartial(c, "tx_update_response", gin.H{"Row": row})
+	h.renderPartial(c, "tx_update_response", gin.H{"Row": newTxRowVM(out, catNames, loc, csrf)})
 }
 
 func (h *Handlers) PostDeleteTransaction(c *gin.Context) {
@@ -460,7 +637,11 @@ func (h *Handlers) PostDeleteTransaction(c *gin.Context) {
 		return
 	}
 
-	deleted, err := h.Transactions.Delete(c.Request.Context(), wsID, txID)
+	if !h.authorizeTxWrite(c, wsID, txID) {
+		return
+	}
+
+	deleted, err := h.Transactions.Delete(c.Request.Context(), c.GetString(auth.CtxUserIDKey), wsID, txID)
 	if err != nil {
 		c.String(http.StatusInternalServerError, "could not delete transaction")
 		return
@@ -470,7 +651,36 @@ func (h *Handlers) PostDeleteTransaction(c *gin.Context) {
 		return
 	}
 
-	h.renderPartial(c, "noop", gin.H{})
+	csrf := strings.TrimSpace(c.GetHeader("X-CSRF-Token"))
+	if csrf == "" {
+		csrf = GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
+	}
+	h.renderPartial(c, "tx_row_trashed", gin.H{"ID": txID, "CSRF": csrf})
+}
+
+// authorizeTxWrite loads the transaction and writes an error response unless
+// the current member may change it.
+func (h *Handlers) authorizeTxWrite(c *gin.Context, wsID, txID string) bool {
+	_, ok := h.loadWritableTx(c, wsID, txID)
+	return ok
+}
+
+func (h *Handlers) loadWritableTx(c *gin.Context, wsID, txID string) (transactions.Transaction, bool) {
+	tx, err := h.Transactions.GetByID(c.Request.Context(), wsID, txID)
+	if err != nil {
+		c.String(http.StatusNotFound, "not found")
+		return transactions.Transaction{}, false
+	}
+	if !canWriteTx(c, tx) {
+		c.String(http.StatusForbidden, "your role does not allow changing this transaction")
+		return transactions.Transaction{}, false
+	}
+	return tx, true
+}
+
+func canWriteTx(c *gin.Context, tx transactions.Transaction) bool {
+	access := currentAccess(c)
+	return access.CanWriteTx(c.GetString(auth.CtxUserIDKey), tx.UserID) && transactions.CanUseCategories(access, tx.Type, tx.CategoryID, tx.Splits)
 }
 
 func workspaceFromContext(c *gin.Context) any {
@@ -479,7 +689,18 @@ func workspaceFromContext(c *gin.Context) any {
 }
 
 func readTxFiltersFromQuery(c *gin.Context) txFiltersVM {
-	limit := parseIntDefault(firstNonEmpty(c.Query("limit"), c.Query("pageSize"), c.Query("page_size")), 20)
+	return readTxFilters(func(key string) string { return strings.Join(c.QueryArray(key), ",") })
+}
+
+func readTxFiltersFromForm(c *gin.Context) txFiltersVM {
+	return readTxFilters(func(key string) string { return strings.Join(c.PostFormArray(key), ",") })
+}
+
+// readTxFilters reads the filters bar through get, which looks fields up in
+// the query string or, for forms that include the bar, the posted form.
+// Repeated fields come joined with commas.
+func readTxFilters(get func(string) string) txFiltersVM {
+	limit := parseIntDefault(firstNonEmpty(get("limit"), get("pageSize"), get("page_size")), 20)
 	if limit <= 0 {
 		limit = 20
 	}
@@ -487,78 +708,165 @@ func readTxFiltersFromQuery(c *gin.Context) txFiltersVM {
 		limit = 200
 	}
 
-	offset := parseIntDefault(c.Query("offset"), 0)
+	offset := parseIntDefault(get("offset"), 0)
 	if offset < 0 {
 		offset = 0
 	}
 
-	if c.Query("offset") == "" {
-		page := parseIntDefault(c.Query("page"), 1)
+	if get("offset") == "" {
+		page := parseIntDefault(get("page"), 1)
 		if page < 1 {
 			page = 1
 		}
 		offset = (page - 1) * limit
 	}
 
+	var catIDs []string
+	for _, id := range strings.Split(get("category_id"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			catIDs = append(catIDs, id)
+		}
+	}
+
 	return txFiltersVM{
-		From:       strings.TrimSpace(c.Query("from")),
-		To:         strings.TrimSpace(c.Query("to")),
-		Type:       strings.TrimSpace(c.Query("type")),
-		CategoryID: strings.TrimSpace(c.Query("category_id")),
-		Q:          strings.TrimSpace(firstNonEmpty(c.Query("q"), c.Query("search"))),
-		Sort:       strings.TrimSpace(c.Query("sort")),
-		Limit:      limit,
-		Offset:     offset,
+		From:          strings.TrimSpace(get("from")),
+		To:            strings.TrimSpace(get("to")),
+		Type:          strings.TrimSpace(get("type")),
+		CategoryIDs:   catIDs,
+		Uncategorized: get("uncategorized") != "",
+		AmountMin:     strings.TrimSpace(get("amount_min")),
+		AmountMax:     strings.TrimSpace(get("amount_max")),
+		Currency:      strings.TrimSpace(get("currency")),
+		Tags:          strings.TrimSpace(get("tags")),
+		TagsMatch:     strings.TrimSpace(get("tags_match")),
+		UserID:        strings.TrimSpace(get("user_id")),
+		CreatedFrom:   strings.TrimSpace(get("created_from")),
+		CreatedTo:     strings.TrimSpace(get("created_to")),
+		UpdatedFrom:   strings.TrimSpace(get("updated_from")),
+		UpdatedTo:     strings.TrimSpace(get("updated_to")),
+		Q:             strings.TrimSpace(firstNonEmpty(get("q"), get("search"))),
+		Sort:          strings.TrimSpace(get("sort")),
+		Limit:         limit,
+		Offset:        offset,
+		Cursor:        strings.TrimSpace(get("cursor")),
 	}
 }
 
-func buildTxListFilter(vm txFiltersVM) (transactions.ListFilter, error) {
-	var f transactions.ListFilter
+// buildTxListFilter parses the filters bar.
+func buildTxListFilter(vm txFiltersVM, loc *time.Location) (transactions.ListFilter, error) {
+	values, err := txFilterParams(vm, loc)
+	if err != nil {
+		return transactions.ListFilter{}, err
+	}
 
-	if vm.From != "" {
-		t, err := transactions.ParseOccurredAt(vm.From)
+	f, ferr := transactions.ParseListFilter(func(key string) string { return strings.Join(values[key], ",") }, loc)
+	if ferr != nil {
+		return transactions.ListFilter{}, ferr
+	}
+
+	f.Limit = vm.Limit
+	f.Offset = vm.Offset
+	f.Sort = transactions.NormalizeSort(vm.Sort)
+
+	if vm.Cursor != "" {
+		cur, err := transactions.DecodeCursor(vm.Cursor, f.Sort)
 		if err != nil {
-			return transactions.ListFilter{}, fmt.Errorf("invalid from date")
+			return transactions.ListFilter{}, fmt.Errorf("invalid page cursor")
 		}
-		f.From = &t
+		f.Cursor = &cur
 	}
-	if vm.To != "" {
-		t, err := transactions.ParseOccurredAt(vm.To)
-		if err != nil {
-			return transactions.ListFilter{}, fmt.Errorf("invalid to date")
+	return f, nil
+}
+
+// txFilterParams turns the filters bar into list parameters as the API takes
+// them. Amounts are entered as decimals and date-only upper bounds include
+// the whole day.
+func txFilterParams(vm txFiltersVM, loc *time.Location) (url.Values, error) {
+	values := vm.values()
+	values.Del("sort")
+	for _, key := range []string{"amount_min", "amount_max"} {
+		if raw := values.Get(key); raw != "" {
+			minor, err := transactions.ParseAmountMinor(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s", strings.ReplaceAll(key, "_", " "))
+			}
+			values.Set(key, strconv.FormatInt(minor, 10))
 		}
-		if len(vm.To) == 10 {
-			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+	}
+	for _, key := range []string{"to", "created_to", "updated_to"} {
+		if raw := values.Get(key); len(raw) == 10 {
+			t, err := time.ParseInLocation("2006-01-02", raw, loc)
+			if err == nil {
+				values.Set(key, t.AddDate(0, 0, 1).Add(-time.Second).Format(time.RFC3339))
+			}
 		}
-		f.To = &t
 	}
+	return values, nil
+}
 
-	if vm.Type != "" {
-		typ := transactions.NormalizeType(vm.Type)
-		if !transactions.ValidateType(typ) {
-			return transactions.ListFilter{}, fmt.Errorf("invalid type")
-		}
-		f.Type = &typ
+func newTxRowVM(tx transactions.Transaction, catNames map[string]string, loc *time.Location, csrf string) txRowVM {
+	vm := txRowVM{
+		ID:       tx.ID,
+		Occurred: tx.OccurredAt.In(loc).Format("2006-01-02"),
+		Type:     string(tx.Type),
+		Category: txCategoryLabel(tx, catNames),
+		Amount:   formatMinor(tx.AmountMinor),
+		Currency: tx.Currency,
+		Note:     optionalString(tx.Note),
+		Tags:     strings.Join(tx.Tags, ", "),
+		CSRF:     csrf,
+	}
+	// The snippet is escaped by the transactions package; only its <mark>
+	// tags are markup.
+	if tx.Search != nil && tx.Search.Snippet != "" {
+		vm.Snippet = template.HTML(tx.Search.Snippet)
+	}
+	return vm
+}
+
+// txCategoryLabel names the category of a transaction, or lists the line
+// categories of a split one.
+func txCategoryLabel(tx transactions.Transaction, catNames map[string]string) string {
+	if len(tx.Splits) == 0 {
+		return categoryName(tx.CategoryID, catNames)
+	}
+	names := make([]string, 0, len(tx.Splits))
+	for _, sp := range tx.Splits {
+		names = append(names, categoryName(sp.CategoryID, catNames))
 	}
+	return "Split: " + strings.Join(names, ", ")
+}
 
-	if vm.CategoryID != "" {
-		cat := vm.CategoryID
-		catID, err := transactions.NormalizeOptionalUUID(&cat)
-		if err != nil {
-			return transactions.ListFilter{}, fmt.Errorf("invalid category_id")
-		}
-		f.CategoryID = catID
+func splitVMs(splits []transactions.Split, catNames map[string]string) []splitVM {
+	out := make([]splitVM, 0, len(splits))
+	for _, sp := range splits {
+		out = append(out, splitVM{
+			Category: categoryName(sp.CategoryID, catNames),
+			Amount:   formatMinor(sp.AmountMinor),
+			Note:     optionalString(sp.Note),
+		})
 	}
+	return out
+}
 
-	if vm.Q != "" {
-		q := vm.Q
-		f.Search = &q
+// editCategories returns the categories a transaction can be moved to: the
+// active ones plus selectedID, which may have been archived since.
+func editCategories(cats []categories.Category, selectedID string) []categories.Category {
+	out := make([]categories.Category, 0, len(cats))
+	for _, cat := range cats {
+		if !cat.Archived() || cat.ID == selectedID {
+			out = append(out, cat)
+		}
 	}
+	return out
+}
 
-	f.Limit = vm.Limit
-	f.Offset = vm.Offset
-	f.Sort = vm.Sort
-	return f, nil
+func categoryNames(cats []categories.Category) map[string]string {
+	out := make(map[string]string, len(cats))
+	for _, cat := range cats {
+		out[cat.ID] = cat.Name
+	}
+	return out
 }
 
 func categoryName(catID *string, names map[string]string) string {
@@ -579,9 +887,12 @@ func optionalString(s *string) string {
 }
 
 func formatMinor(minor int64) string {
-	whole := minor / 100
-	frac := minor % 100
-	return fmt.Sprintf("%d.%02d", whole, frac)
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
 }
 
 func buildPagination(offset, limit, got int, hasNext bool) txPaginationVM {
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type trashRowVM struct {
+	txRowVM
+	DeletedAt  string
+	CanRestore bool
+}
+
+const trashPageSize = 20
+
+func (h *Handlers) GetTransactionsTrashPage(c *gin.Context) {
+	if h.Categories == nil || h.Transactions == nil {
+		c.String(http.StatusInternalServerError, "categories/transactions service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	offset := parseIntDefault(c.Query("offset"), 0)
+	result, err := h.Transactions.ListDeleted(c.Request.Context(), wsID, trashPageSize, offset)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list trash")
+		return
+	}
+
+	cats, err := h.Categories.List(c.Request.Context(), wsID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list categories")
+		return
+	}
+	catNames := map[string]string{}
+	for _, cat := range cats {
+		catNames[cat.ID] = cat.Name
+	}
+
+	loc := workspaces.GetLocation(c)
+	rows := make([]trashRowVM, 0, len(result.Items))
+	for _, item := range result.Items {
+		row := trashRowVM{
+			txRowVM:    newTxRowVM(item, catNames, loc, ""),
+			CanRestore: canWriteTx(c, item),
+		}
+		if item.DeletedAt != nil {
+			row.DeletedAt = item.DeletedAt.In(loc).Format("2006-01-02 15:04")
+		}
+		rows = append(rows, row)
+	}
+
+	h.render(c, "app/transactions_trash.html", gin.H{
+		"Title":         "Trash",
+		"BodyClass":     "app-dark",
+		"Flash":         c.Query("flash"),
+		"Workspace":     workspaceFromContext(c),
+		"Items":         rows,
+		"Pagination":    buildPagination(result.Offset, result.Limit, len(result.Items), result.HasNext),
+		"RetentionDays": int(h.Transactions.TrashRetention().Hours() / 24),
+	})
+}
+
+// PostRestoreTransaction takes a transaction out of the trash. htmx requests
+// (the "Undo" button on the transactions table) get the restored row back;
+// plain form posts from the trash page are redirected.
+func (h *Handlers) PostRestoreTransaction(c *gin.Context) {
+	if h.Transactions == nil {
+		c.String(http.StatusInternalServerError, "transactions service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	txID := strings.TrimSpace(c.Param("id"))
+	if txID == "" {
+		c.String(http.StatusBadRequest, "missing id")
+		return
+	}
+
+	trashed, err := h.Transactions.GetDeletedByID(c.Request.Context(), wsID, txID)
+	if err != nil {
+		c.String(http.StatusNotFound, "not found")
+		return
+	}
+	if !canWriteTx(c, trashed) {
+		c.String(http.StatusForbidden, "your role does not allow restoring this transaction")
+		return
+	}
+
+	out, err := h.Transactions.Restore(c.Request.Context(), c.GetString(auth.CtxUserIDKey), wsID, txID)
+	if errors.Is(err, transactions.ErrNotInTrash) {
+		c.String(http.StatusConflict, "transaction was already restored or purged")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not restore transaction")
+		return
+	}
+
+	if c.GetHeader("HX-Request") != "true" {
+		c.Redirect(http.StatusSeeOther, "/app/transactions/trash?flash="+url.QueryEscape("Transaction restored"))
+		return
+	}
+
+	catNames := map[string]string{}
+	if h.Categories != nil {
+		cats, _ := h.Categories.List(c.Request.Context(), wsID)
+		for _, cat := range cats {
+			catNames[cat.ID] = cat.Name
+		}
+	}
+
+	csrf := strings.TrimSpace(c.GetHeader("X-CSRF-Token"))
+	if csrf == "" {
+		csrf = GenerateCSRF(h.CSRFSecret, h.CSRFTTL)
+	}
+	h.renderPartial(c, "tx_restore_response", gin.H{"Row": newTxRowVM(out, catNames, workspaces.GetLocation(c), csrf)})
+}
@@ -0,0 +1,184 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/savedviews"
+	"github.com/skelbigo/FinanceTracker/internal/transactions"
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+type viewOptionVM struct {
+	ID       string
+	Name     string
+	Shared   bool
+	Selected bool
+}
+
+// viewOptions lists the saved views the user can open.
+func (h *Handlers) viewOptions(c *gin.Context, wsID, selected string) ([]viewOptionVM, error) {
+	if h.SavedViews == nil {
+		return nil, nil
+	}
+	views, err := h.SavedViews.List(c.Request.Context(), wsID, c.GetString(auth.CtxUserIDKey))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]viewOptionVM, 0, len(views))
+	for _, v := range views {
+		out = append(out, viewOptionVM{ID: v.ID, Name: v.Name, Shared: v.Shared, Selected: v.ID == selected})
+	}
+	return out, nil
+}
+
+// readTxFiltersWithView fills the filters bar from the saved view named by
+// view_id, with explicit query parameters taking precedence. Stored amounts
+// are minor units and upper bounds timestamps, so they are turned back into
+// what the inputs show.
+func (h *Handlers) readTxFiltersWithView(c *gin.Context, wsID string) (txFiltersVM, error) {
+	viewID := strings.TrimSpace(c.Query("view_id"))
+	if viewID == "" || h.SavedViews == nil {
+		return readTxFiltersFromQuery(c), nil
+	}
+	params, err := h.SavedViews.ViewParams(c.Request.Context(), wsID, c.GetString(auth.CtxUserIDKey), viewID)
+	if err != nil {
+		return txFiltersVM{}, err
+	}
+
+	loc := workspaces.GetLocation(c)
+	return readTxFilters(func(key string) string {
+		if v := strings.Join(c.QueryArray(key), ","); v != "" {
+			return v
+		}
+		v := params[key]
+		switch key {
+		case "amount_min", "amount_max":
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return formatMinor(n)
+			}
+		case "from", "to", "created_from", "created_to", "updated_from", "updated_to":
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t.In(loc).Format("2006-01-02")
+			}
+		}
+		return v
+	}), nil
+}
+
+// PostCreateView saves the filters bar as a view and opens it.
+func (h *Handlers) PostCreateView(c *gin.Context) {
+	if h.SavedViews == nil {
+		c.String(http.StatusInternalServerError, "saved views service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	filters := readTxFiltersFromForm(c)
+	params, err := txFilterParams(filters, workspaces.GetLocation(c))
+	if err != nil {
+		c.Status(http.StatusUnprocessableEntity)
+		h.renderPartial(c, "tx_form_errors", gin.H{"Errors": []string{err.Error()}})
+		return
+	}
+	in := savedviews.Input{
+		Name:    c.PostForm("view_name"),
+		Shared:  c.PostForm("view_shared") != "",
+		Filters: map[string]string{},
+		Sort:    filters.Sort,
+	}
+	for key, vals := range params {
+		in.Filters[key] = strings.Join(vals, ",")
+	}
+
+	v, err := h.SavedViews.Create(c.Request.Context(), wsID, c.GetString(auth.CtxUserIDKey), in,
+		currentAccess(c).Has(workspaces.PermTxWrite))
+	if err != nil {
+		msg, ok := viewErrorMessage(err)
+		if !ok {
+			c.String(http.StatusInternalServerError, "could not save view")
+			return
+		}
+		c.Status(http.StatusUnprocessableEntity)
+		h.renderPartial(c, "tx_form_errors", gin.H{"Errors": []string{msg}})
+		return
+	}
+
+	redirectTransactions(c, v.ID, "View saved")
+}
+
+func (h *Handlers) PostDeleteView(c *gin.Context) {
+	if h.SavedViews == nil {
+		c.String(http.StatusInternalServerError, "saved views service is not configured")
+		return
+	}
+
+	wsID := c.GetString(workspaces.CtxWorkspaceIDKey)
+	if wsID == "" {
+		c.String(http.StatusInternalServerError, "workspace not set")
+		return
+	}
+
+	err := h.SavedViews.Delete(c.Request.Context(), wsID, c.GetString(auth.CtxUserIDKey), c.Param("viewId"),
+		currentAccess(c).Has(workspaces.PermTxWrite))
+	if err != nil {
+		msg, ok := viewErrorMessage(err)
+		if !ok {
+			c.String(http.StatusInternalServerError, "could not delete view")
+			return
+		}
+		redirectTransactions(c, "", msg)
+		return
+	}
+	redirectTransactions(c, "", "View deleted")
+}
+
+// redirectTransactions reloads the transactions page, through HX-Redirect
+// for htmx requests.
+func redirectTransactions(c *gin.Context, viewID, flash string) {
+	q := url.Values{}
+	if viewID != "" {
+		q.Set("view_id", viewID)
+	}
+	q.Set("flash", flash)
+	target := "/app/transactions?" + q.Encode()
+
+	if c.GetHeader("HX-Request") != "" {
+		c.Header("HX-Redirect", target)
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.Redirect(http.StatusSeeOther, target)
+}
+
+func viewErrorMessage(err error) (string, bool) {
+	var ferr *transactions.FilterError
+	switch {
+	case errors.As(err, &ferr):
+		return "Invalid filter: " + ferr.Msg, true
+	case errors.Is(err, savedviews.ErrInvalidName):
+		return "View name is required (at most 100 characters)", true
+	case errors.Is(err, savedviews.ErrInvalidSort):
+		return "Invalid sort", true
+	case errors.Is(err, savedviews.ErrViewExists):
+		return "A view with this name already exists", true
+	case errors.Is(err, savedviews.ErrViewNotFound):
+		return "View not found", true
+	case errors.Is(err, savedviews.ErrSharedDenied):
+		return "Your role cannot manage shared views", true
+	default:
+		return "", false
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
 )
 
 func (h *Handlers) GetWorkspacesPage(c *gin.Context) {
@@ -31,6 +32,12 @@ func (h *Handlers) GetWorkspacesPage(c *gin.Context) {
 		return
 	}
 
+	deleted, err := h.Workspaces.ListDeleted(c.Request.Context(), userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not list workspaces")
+		return
+	}
+
 	current, _ := c.Cookie(CurrentWorkspaceCookie)
 
 	h.render(c, "app/workspaces.html", gin.H{
@@ -38,6 +45,7 @@ func (h *Handlers) GetWorkspacesPage(c *gin.Context) {
 		"BodyClass": "app-dark",
 		"Flash":     c.Query("flash"),
 		"Items":     items,
+		"Deleted":   deleted,
 		"CurrentID": current,
 	})
 }
@@ -60,11 +68,14 @@ func (h *Handlers) PostCreateWorkspace(c *gin.Context) {
 	if currency == "" {
 		currency = "UAH"
 	}
+	timezone := strings.TrimSpace(c.PostForm("timezone"))
 
-	w, _, err := h.Workspaces.CreateWorkspace(c.Request.Context(), userID, name, currency)
+	w, _, err := h.Workspaces.CreateWorkspace(c.Request.Context(), userID, name, currency, timezone)
 	if err != nil {
 		flash := "Could not create workspace"
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, tzx.ErrInvalidTimezone) {
+			flash = "Unknown timezone (use e.g. Europe/Kyiv)"
+		} else if errors.Is(err, pgx.ErrNoRows) {
 			flash = "Invalid user"
 		} else if err.Error() != "" {
 			flash = err.Error()
@@ -32,6 +32,9 @@ func (h *Handlers) RequireWorkspace() gin.HandlerFunc {
 			if _, perr := uuid.Parse(wsID); perr == nil {
 				w, role, gerr := h.Workspaces.GetWorkspace(c.Request.Context(), wsID, userID)
 				if gerr == nil {
+					if !h.setWorkspaceLocation(c, wsID, userID) || !h.setWorkspaceAccess(c, wsID, userID) {
+						return
+					}
 					c.Set(workspaces.CtxWorkspaceIDKey, wsID)
 					c.Set(workspaces.CtxWorkspaceRoleKey, role)
 					c.Set("workspace", w)
@@ -71,6 +74,10 @@ func (h *Handlers) RequireWorkspace() gin.HandlerFunc {
 			return
 		}
 
+		if !h.setWorkspaceLocation(c, pickedID, userID) || !h.setWorkspaceAccess(c, pickedID, userID) {
+			return
+		}
+
 		setCurrentWorkspaceCookie(c, h.CookieCfg, pickedID)
 		c.Set(workspaces.CtxWorkspaceIDKey, pickedID)
 		c.Set(workspaces.CtxWorkspaceRoleKey, role)
@@ -79,3 +86,52 @@ func (h *Handlers) RequireWorkspace() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func (h *Handlers) setWorkspaceLocation(c *gin.Context, workspaceID, userID string) bool {
+	loc, err := h.Workspaces.EffectiveLocation(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not resolve workspace timezone")
+		c.Abort()
+		return false
+	}
+	c.Set(workspaces.CtxLocationKey, loc)
+	return true
+}
+
+func (h *Handlers) setWorkspaceAccess(c *gin.Context, workspaceID, userID string) bool {
+	access, err := h.Workspaces.Access(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "could not resolve workspace permissions")
+		c.Abort()
+		return false
+	}
+	c.Set(workspaces.CtxAccessKey, access)
+	return true
+}
+
+// RequirePermission rejects requests from members whose role lacks perm.
+func (h *Handlers) RequirePermission(perm workspaces.Permission) gin.HandlerFunc {
+	return h.RequireAnyPermission(perm)
+}
+
+// RequireAnyPermission rejects requests from members whose role grants none
+// of perms.
+func (h *Handlers) RequireAnyPermission(perms ...workspaces.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !currentAccess(c).HasAny(perms...) {
+			c.String(http.StatusForbidden, "your role does not allow this action")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentAccess returns the access resolved by RequireWorkspace, falling back
+// to the built-in preset of the current role.
+func currentAccess(c *gin.Context) workspaces.Access {
+	if a, ok := workspaces.GetAccess(c); ok {
+		return a
+	}
+	return workspaces.NewPresetAccess(currentRole(c))
+}
@@ -4,8 +4,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/skelbigo/FinanceTracker/internal/analytics"
+	"github.com/skelbigo/FinanceTracker/internal/attachments"
 	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/budgets"
 	"github.com/skelbigo/FinanceTracker/internal/categories"
+	"github.com/skelbigo/FinanceTracker/internal/savedviews"
 	"github.com/skelbigo/FinanceTracker/internal/transactions"
 	"github.com/skelbigo/FinanceTracker/internal/workspaces"
 )
@@ -14,9 +18,14 @@ type Handlers struct {
 	R *Renderer
 
 	Auth         *auth.Service
-	Workspaces   *workspaces.Service
+	Workspaces   WorkspacesService
+	Invitations  InvitationsService
 	Categories   *categories.Service
 	Transactions *transactions.Service
+	Attachments  *attachments.Service
+	Budgets      *budgets.Service
+	Analytics    *analytics.Service
+	SavedViews   *savedviews.Service
 	JWTM         *auth.JWTManager
 
 	CookieCfg  CookieConfig
@@ -44,20 +53,80 @@ func RegisterRoutes(router *gin.Engine, h *Handlers) {
 
 	webGroup.POST("/logout", h.PostLogout)
 
+	webGroup.GET("/invite", h.GetInvite)
+	webGroup.GET("/join/:token", h.GetJoin)
+	webGroup.POST("/join/:token", RequireAuth(h.JWTM, h.Auth, h.CookieCfg, h.AccessTTL, h.RefreshTTL), h.PostJoin)
+
 	app := webGroup.Group("/app")
 	app.Use(RequireAuth(h.JWTM, h.Auth, h.CookieCfg, h.AccessTTL, h.RefreshTTL))
-	app.GET("", h.GetDashboard)
 
 	app.GET("/workspaces", h.GetWorkspacesPage)
 	app.POST("/workspaces", h.PostCreateWorkspace)
+	app.POST("/workspaces/:id/restore", h.PostRestoreWorkspace)
+	app.POST("/invitations/accept", h.PostAcceptInvite)
 
 	withWS := app.Group("")
 	withWS.Use(h.RequireWorkspace())
-	withWS.GET("/transactions", h.GetTransactionsPage)
-	withWS.GET("/transactions/table", h.GetTransactionsTable)
-	withWS.POST("/transactions", h.PostCreateTransaction)
-	withWS.GET("/transactions/:id/edit", h.GetTransactionEdit)
-	withWS.POST("/transactions/:id/update", h.PostUpdateTransaction)
-	withWS.POST("/transactions/:id/delete", h.PostDeleteTransaction)
+	withWS.GET("", h.GetDashboard)
+
+	canRead := h.RequirePermission(workspaces.PermTxRead)
+	canWriteTx := h.RequirePermission(workspaces.PermTxWriteOwn)
+	canWriteBudgets := h.RequirePermission(workspaces.PermBudgetsWrite)
+	canWriteCategories := h.RequirePermission(workspaces.PermCategoriesWrite)
+	canExport := h.RequirePermission(workspaces.PermExport)
+	// Listing categories is needed both to read and to record transactions,
+	// matching GET /workspaces/:id/categories in the API.
+	canListCategories := h.RequireAnyPermission(workspaces.PermTxRead, workspaces.PermTxWriteOwn)
+
+	withWS.GET("/dashboard/summary", canRead, h.GetDashboardSummary)
+	withWS.GET("/dashboard/budgets", canRead, h.GetDashboardBudgets)
+	withWS.GET("/dashboard/recent", canRead, h.GetDashboardRecent)
+	withWS.GET("/dashboard/sparkline", canRead, h.GetDashboardSparkline)
+	withWS.GET("/transactions", canRead, h.GetTransactionsPage)
+	withWS.GET("/transactions/table", canRead, h.GetTransactionsTable)
+	withWS.GET("/transactions/trash", canRead, h.GetTransactionsTrashPage)
+	withWS.GET("/transactions/export", canExport, h.GetTransactionsExport)
+	withWS.POST("/transactions", canWriteTx, h.PostCreateTransaction)
+	withWS.POST("/transactions/bulk", canWriteTx, h.PostBulkTransactions)
+	withWS.GET("/transactions/:id/edit", canWriteTx, h.GetTransactionEdit)
+	withWS.POST("/transactions/:id/update", canWriteTx, h.PostUpdateTransaction)
+	withWS.POST("/transactions/:id/delete", canWriteTx, h.PostDeleteTransaction)
+	withWS.POST("/transactions/:id/restore", canWriteTx, h.PostRestoreTransaction)
+	withWS.GET("/transactions/:id/attachments/:attachmentId", canRead, h.GetTransactionAttachment)
+	withWS.POST("/transactions/:id/attachments", canWriteTx, h.PostUploadTransactionAttachment)
+	withWS.POST("/transactions/:id/attachments/:attachmentId/delete", canWriteTx, h.PostDeleteTransactionAttachment)
+	withWS.POST("/views", canRead, h.PostCreateView)
+	withWS.POST("/views/:viewId/delete", canRead, h.PostDeleteView)
+	withWS.GET("/budgets", canRead, h.GetBudgetsPage)
+	withWS.POST("/budgets", canWriteBudgets, h.PostUpsertBudget)
+	withWS.GET("/categories", canListCategories, h.GetCategoriesPage)
+	withWS.POST("/categories", canWriteCategories, h.PostCreateCategory)
+	withWS.GET("/categories/:id/edit", canWriteCategories, h.GetCategoryEdit)
+	withWS.GET("/categories/:id/row", canListCategories, h.GetCategoryRow)
+	withWS.POST("/categories/:id/update", canWriteCategories, h.PostRenameCategory)
+	withWS.POST("/categories/:id/archive", canWriteCategories, h.PostArchiveCategory)
+	withWS.POST("/categories/:id/unarchive", canWriteCategories, h.PostUnarchiveCategory)
+	withWS.GET("/members", h.GetMembersPage)
+	withWS.POST("/members", h.PostAddMember)
+	withWS.POST("/members/:userId/role", h.PostUpdateMemberRole)
+	withWS.POST("/members/:userId/custom-role", h.PostAssignCustomRole)
+	withWS.POST("/members/:userId/remove", h.PostRemoveMember)
+	withWS.POST("/members/invitations", h.PostCreateInvitation)
+	withWS.POST("/members/invitations/:id/revoke", h.PostRevokeInvitation)
+	withWS.POST("/members/transfer", h.PostNominateOwner)
+	withWS.POST("/members/transfer/cancel", h.PostCancelTransfer)
+	withWS.POST("/members/transfer/accept", h.PostAcceptTransfer)
+	withWS.POST("/members/transfer/decline", h.PostDeclineTransfer)
+	withWS.POST("/members/links", h.PostCreateInviteLink)
+	withWS.POST("/members/links/:id/revoke", h.PostRevokeInviteLink)
+	withWS.POST("/members/roles", h.PostCreateCustomRole)
+	withWS.POST("/members/roles/:id/delete", h.PostDeleteCustomRole)
+	withWS.GET("/settings", h.GetSettingsPage)
+	withWS.POST("/settings", h.PostUpdateSettings)
+	withWS.POST("/settings/delete/request", h.PostRequestDeletion)
+	withWS.POST("/settings/delete", h.PostConfirmDeletion)
+	withWS.POST("/settings/leave", h.PostLeaveWorkspace)
+	withWS.GET("/analytics", canRead, h.GetAnalyticsPage)
+	withWS.GET("/analytics/charts", canRead, h.GetAnalyticsCharts)
 	_ = withWS
 }
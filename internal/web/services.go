@@ -0,0 +1,62 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	"github.com/skelbigo/FinanceTracker/internal/workspaces"
+)
+
+// WorkspacesService is the part of *workspaces.Service the web handlers use.
+type WorkspacesService interface {
+	CreateWorkspace(ctx context.Context, creatorID, name, currency, timezone string) (workspaces.Workspace, workspaces.Role, error)
+	ListMyWorkspaces(ctx context.Context, userID string) ([]workspaces.WorkspaceListItem, error)
+	GetWorkspace(ctx context.Context, workspaceID, userID string) (workspaces.Workspace, workspaces.Role, error)
+	Access(ctx context.Context, workspaceID, userID string) (workspaces.Access, error)
+	EffectiveLocation(ctx context.Context, workspaceID, userID string) (*time.Location, error)
+	UpdateTimezone(ctx context.Context, workspaceID, timezone string) error
+
+	ListMembers(ctx context.Context, workspaceID string) ([]workspaces.MemberInfo, error)
+	AddMemberByEmail(ctx context.Context, workspaceID, actorUserID, email string, role workspaces.Role) error
+	UpdateMemberRole(ctx context.Context, workspaceID, actorUserID, targetUserID string, newRole workspaces.Role) error
+	RemoveMember(ctx context.Context, workspaceID, actorUserID, targetUserID string) error
+
+	UpdateSettings(ctx context.Context, workspaceID string, name, defaultCurrency *string) error
+	RequestDeletion(ctx context.Context, workspaceID string) (string, time.Time, error)
+	ConfirmDeletion(ctx context.Context, workspaceID, token string) (time.Time, error)
+	Restore(ctx context.Context, workspaceID, userID string) error
+	ListDeleted(ctx context.Context, userID string) ([]workspaces.DeletedWorkspace, error)
+	Leave(ctx context.Context, workspaceID, userID string) error
+
+	NominateOwner(ctx context.Context, workspaceID, fromUserID, toUserID string) (workspaces.OwnershipTransfer, error)
+	PendingTransfer(ctx context.Context, workspaceID string) (workspaces.OwnershipTransfer, error)
+	CancelTransfer(ctx context.Context, workspaceID string) error
+	DeclineTransfer(ctx context.Context, workspaceID, userID string) error
+	AcceptTransfer(ctx context.Context, workspaceID, userID string) error
+
+	ListCustomRoles(ctx context.Context, workspaceID string) ([]workspaces.CustomRole, error)
+	CreateCustomRole(ctx context.Context, workspaceID, name string, perms, categoryIDs, txTypes []string) (workspaces.CustomRole, error)
+	DeleteCustomRole(ctx context.Context, workspaceID, roleID string) error
+	AssignCustomRole(ctx context.Context, workspaceID, actorUserID, userID string, roleID *string) error
+}
+
+// InvitationsService is the part of *workspaces.InvitationService the web
+// handlers use.
+type InvitationsService interface {
+	Create(ctx context.Context, workspaceID, inviterID, email string, role workspaces.Role) (workspaces.CreatedInvitation, error)
+	ListPending(ctx context.Context, workspaceID string) ([]workspaces.Invitation, error)
+	Revoke(ctx context.Context, workspaceID, invitationID string) error
+	Preview(ctx context.Context, token string) (workspaces.InvitationPreview, error)
+	Accept(ctx context.Context, token, userID string) (string, error)
+
+	CreateLink(ctx context.Context, workspaceID, creatorID string, role workspaces.Role, maxUses *int, expiresIn time.Duration) (workspaces.CreatedInviteLink, error)
+	ListLinks(ctx context.Context, workspaceID string) ([]workspaces.InviteLink, error)
+	RevokeLink(ctx context.Context, workspaceID, linkID string) error
+	PreviewLink(ctx context.Context, token string) (workspaces.InviteLinkPreview, error)
+	Join(ctx context.Context, token, userID string) (string, error)
+}
+
+var (
+	_ WorkspacesService  = (*workspaces.Service)(nil)
+	_ InvitationsService = (*workspaces.InvitationService)(nil)
+)
@@ -8,4 +8,30 @@ var (
 	ErrLastOwner        = errors.New("cannot remove last owner")
 	ErrCannotSelfDemote = errors.New("owner cannot slf demote")
 	ErrInvalidRole      = errors.New("invalid role")
+	ErrInvalidName      = errors.New("invalid name")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+
+	ErrInvalidDeletionToken = errors.New("deletion token is invalid or expired")
+
+	ErrInvalidPermission   = errors.New("invalid permission")
+	ErrCustomRoleNotFound  = errors.New("custom role not found")
+	ErrCustomRoleExists    = errors.New("custom role with this name already exists")
+	ErrOwnerRoleRequired   = errors.New("only owners can grant or change the owner role")
+	ErrCannotAssignToOwner = errors.New("custom roles cannot be assigned to owners")
+	ErrInvalidCategories   = errors.New("invalid category restriction")
+	ErrInvalidTxTypes      = errors.New("invalid transaction type restriction")
+
+	ErrTransferNotFound      = errors.New("ownership transfer not found")
+	ErrInvalidTransferTarget = errors.New("ownership can only be transferred to another non-owner member")
+	ErrNotTransferTarget     = errors.New("ownership transfer is addressed to another member")
+
+	ErrInvalidEmail            = errors.New("invalid email")
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvalidInvitation       = errors.New("invitation is invalid or expired")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to a different email")
+
+	ErrInviteLinkNotFound = errors.New("invite link not found")
+	ErrInvalidInviteLink  = errors.New("invite link is invalid, expired or used up")
+	ErrInvalidMaxUses     = errors.New("invalid max uses")
+	ErrInvalidExpiry      = errors.New("invalid expiry")
 )
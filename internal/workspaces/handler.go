@@ -3,9 +3,11 @@ package workspaces
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/skelbigo/FinanceTracker/internal/auth"
 	"github.com/skelbigo/FinanceTracker/internal/httpx"
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
 	"net/http"
 	"strings"
 )
@@ -30,20 +32,40 @@ func (h *Handler) RegisterRoutes(r gin.IRouter) {
 
 	g.POST("", h.CreateWorkspace)
 	g.GET("", h.ListMyWorkspaces)
+	g.GET("/deleted", h.ListDeletedWorkspaces)
 
 	wsg := g.Group("/:id")
 
 	wsg.GET("", RequireWorkspaceRole(h.repo, RoleViewer), h.GetWorkspace)
+	wsg.PATCH("", RequireWorkspaceRole(h.repo, RoleOwner), h.UpdateWorkspace)
+	wsg.POST("/deletion", RequireWorkspaceRole(h.repo, RoleOwner), h.RequestDeletion)
+	wsg.DELETE("", RequireWorkspaceRole(h.repo, RoleOwner), h.DeleteWorkspace)
+	wsg.POST("/restore", h.RestoreWorkspace)
+	wsg.POST("/leave", RequireWorkspaceRole(h.repo, RoleViewer), h.LeaveWorkspace)
 	wsg.GET("/members", RequireWorkspaceRole(h.repo, RoleViewer), h.ListMembers)
 
-	wsg.POST("/members", RequireWorkspaceRole(h.repo, RoleOwner), h.AddMember)
-	wsg.PATCH("/members/:userId", RequireWorkspaceRole(h.repo, RoleOwner), h.UpdateMemberRole)
-	wsg.DELETE("/members/:userId", RequireWorkspaceRole(h.repo, RoleOwner), h.RemoveMember)
+	wsg.POST("/members", RequirePermission(h.repo, PermMembersManage), h.AddMember)
+	wsg.PATCH("/members/:userId", RequirePermission(h.repo, PermMembersManage), h.UpdateMemberRole)
+	wsg.DELETE("/members/:userId", RequirePermission(h.repo, PermMembersManage), h.RemoveMember)
+
+	h.registerOwnershipRoutes(wsg)
+	h.registerRoleRoutes(wsg)
 }
 
 type createWorkspaceReq struct {
 	Name            string `json:"name" binding:"required"`
 	DefaultCurrency string `json:"default_currency"`
+	Timezone        string `json:"timezone"`
+}
+
+type updateWorkspaceReq struct {
+	Name            *string `json:"name"`
+	DefaultCurrency *string `json:"default_currency"`
+	Timezone        *string `json:"timezone"`
+}
+
+type deleteWorkspaceReq struct {
+	ConfirmToken string `json:"confirm_token" binding:"required"`
 }
 
 type addMemberReq struct {
@@ -74,8 +96,13 @@ func (h *Handler) CreateWorkspace(c *gin.Context) {
 		return
 	}
 
-	w, role, err := h.svc.CreateWorkspace(c.Request.Context(), creatorID, strings.TrimSpace(req.Name), strings.TrimSpace(req.DefaultCurrency))
+	w, role, err := h.svc.CreateWorkspace(c.Request.Context(), creatorID, strings.TrimSpace(req.Name),
+		strings.TrimSpace(req.DefaultCurrency), strings.TrimSpace(req.Timezone))
 	if err != nil {
+		if errors.Is(err, tzx.ErrInvalidTimezone) {
+			httpx.Unprocessable(c, "invalid timezone", map[string]string{"timezone": "IANA name like Europe/Kyiv"})
+			return
+		}
 		httpx.Internal(c)
 		return
 	}
@@ -125,6 +152,68 @@ func (h *Handler) GetWorkspace(c *gin.Context) {
 	})
 }
 
+func (h *Handler) UpdateWorkspace(c *gin.Context) {
+	userID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	workspaceID := c.Param("id")
+
+	var req updateWorkspaceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	if req.Timezone != nil {
+		if _, err := tzx.Normalize(*req.Timezone); err != nil {
+			httpx.Unprocessable(c, "invalid timezone", map[string]string{"timezone": "IANA name like Europe/Kyiv"})
+			return
+		}
+	}
+
+	if err := h.svc.UpdateSettings(c.Request.Context(), workspaceID, req.Name, req.DefaultCurrency); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidName):
+			httpx.Unprocessable(c, "invalid name", map[string]string{"name": "required, up to 100 characters"})
+		case errors.Is(err, ErrInvalidCurrency):
+			httpx.Unprocessable(c, "invalid currency", map[string]string{"default_currency": "3-letter code, e.g. UAH"})
+		case errors.Is(err, pgx.ErrNoRows):
+			httpx.Error(c, http.StatusNotFound, "workspace not found", nil)
+		default:
+			httpx.Internal(c)
+		}
+		return
+	}
+
+	if req.Timezone != nil {
+		if err := h.svc.UpdateTimezone(c.Request.Context(), workspaceID, *req.Timezone); err != nil {
+			switch {
+			case errors.Is(err, tzx.ErrInvalidTimezone):
+				httpx.Unprocessable(c, "invalid timezone", map[string]string{"timezone": "IANA name like Europe/Kyiv"})
+			case errors.Is(err, pgx.ErrNoRows):
+				httpx.Error(c, http.StatusNotFound, "workspace not found", nil)
+			default:
+				httpx.Internal(c)
+			}
+			return
+		}
+	}
+
+	w, role, err := h.svc.GetWorkspace(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workspace": w,
+		"role":      role,
+	})
+}
+
 func (h *Handler) ListMembers(c *gin.Context) {
 	workspaceID := c.Param("id")
 
@@ -138,6 +227,12 @@ func (h *Handler) ListMembers(c *gin.Context) {
 }
 
 func (h *Handler) AddMember(c *gin.Context) {
+	actorID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
 	workspaceID := c.Param("id")
 
 	var req addMemberReq
@@ -147,7 +242,7 @@ func (h *Handler) AddMember(c *gin.Context) {
 	}
 
 	role := Role(strings.TrimSpace(req.Role))
-	err := h.svc.AddMemberByEmail(c.Request.Context(), workspaceID, req.Email, role)
+	err := h.svc.AddMemberByEmail(c.Request.Context(), workspaceID, actorID, req.Email, role)
 	switch {
 	case err == nil:
 		c.Status(http.StatusCreated)
@@ -157,6 +252,8 @@ func (h *Handler) AddMember(c *gin.Context) {
 		httpx.Conflict(c, "user already a member")
 	case errors.Is(err, ErrInvalidRole):
 		httpx.Unprocessable(c, "invalid role", map[string]string{"role": "owner|member|viewer"})
+	case errors.Is(err, ErrOwnerRoleRequired):
+		httpx.Error(c, http.StatusForbidden, "only owners can grant the owner role", nil)
 	default:
 		httpx.Internal(c)
 	}
@@ -190,9 +287,11 @@ func (h *Handler) UpdateMemberRole(c *gin.Context) {
 		case errors.Is(err, pgx.ErrNoRows):
 			httpx.Error(c, http.StatusNotFound, "user not found", nil)
 		case errors.Is(err, ErrCannotSelfDemote):
-			httpx.Conflict(c, "owner cannot change own role on mvp")
+			httpx.Conflict(c, "owner cannot change own role; use ownership transfer")
 		case errors.Is(err, ErrLastOwner):
 			httpx.Conflict(c, "cannot demote last owner")
+		case errors.Is(err, ErrOwnerRoleRequired):
+			httpx.Error(c, http.StatusForbidden, "only owners can change owner roles", nil)
 		default:
 			httpx.Internal(c)
 		}
@@ -219,6 +318,109 @@ func (h *Handler) RemoveMember(c *gin.Context) {
 			httpx.Error(c, http.StatusNotFound, "member not found", nil)
 		case errors.Is(err, ErrLastOwner):
 			httpx.Conflict(c, "cannot remove last owner")
+		case errors.Is(err, ErrOwnerRoleRequired):
+			httpx.Error(c, http.StatusForbidden, "only owners can remove owners", nil)
+		default:
+			httpx.Internal(c)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) RequestDeletion(c *gin.Context) {
+	token, expiresAt, err := h.svc.RequestDeletion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(c, http.StatusNotFound, "workspace not found", nil)
+			return
+		}
+		httpx.Internal(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"confirm_token": token,
+		"expires_at":    expiresAt,
+	})
+}
+
+func (h *Handler) DeleteWorkspace(c *gin.Context) {
+	var req deleteWorkspaceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", map[string]string{"confirm_token": "required, from POST /workspaces/:id/deletion"})
+		return
+	}
+
+	purgeAt, err := h.svc.ConfirmDeletion(c.Request.Context(), c.Param("id"), req.ConfirmToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidDeletionToken) {
+			httpx.Unprocessable(c, "invalid confirmation token", map[string]string{"confirm_token": "request a new one, tokens expire after 15 minutes"})
+			return
+		}
+		httpx.Internal(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purge_at": purgeAt})
+}
+
+func (h *Handler) RestoreWorkspace(c *gin.Context) {
+	userID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	workspaceID := c.Param("id")
+	if _, err := uuid.Parse(workspaceID); err != nil {
+		httpx.BadRequest(c, "invalid workspace id", nil)
+		return
+	}
+
+	if err := h.svc.Restore(c.Request.Context(), workspaceID, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(c, http.StatusNotFound, "deleted workspace not found", nil)
+			return
+		}
+		httpx.Internal(c)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListDeletedWorkspaces(c *gin.Context) {
+	userID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	items, err := h.svc.ListDeleted(c.Request.Context(), userID)
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspaces": items})
+}
+
+func (h *Handler) LeaveWorkspace(c *gin.Context) {
+	userID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	err := h.svc.Leave(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrLastOwner):
+			httpx.Conflict(c, "last owner cannot leave; transfer ownership or delete the workspace")
+		case errors.Is(err, pgx.ErrNoRows):
+			httpx.Error(c, http.StatusNotFound, "member not found", nil)
 		default:
 			httpx.Internal(c)
 		}
@@ -0,0 +1,255 @@
+package workspaces
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/httpx"
+)
+
+type InvitationHandler struct {
+	svc  *InvitationService
+	mw   gin.HandlerFunc
+	repo RoleProvider
+}
+
+func NewInvitationHandler(svc *InvitationService, authMW gin.HandlerFunc, repo RoleProvider) *InvitationHandler {
+	return &InvitationHandler{svc: svc, mw: authMW, repo: repo}
+}
+
+func (h *InvitationHandler) RegisterRoutes(r gin.IRouter) {
+	ws := r.Group("/workspaces/:id/invitations")
+	ws.Use(h.mw, RequirePermission(h.repo, PermMembersManage))
+	ws.POST("", h.CreateInvitation)
+	ws.GET("", h.ListInvitations)
+	ws.DELETE("/:inviteId", h.RevokeInvitation)
+
+	inv := r.Group("/invitations")
+	inv.GET("/preview", h.PreviewInvitation)
+	inv.POST("/accept", h.mw, h.AcceptInvitation)
+
+	links := r.Group("/workspaces/:id/invite-links")
+	links.Use(h.mw, RequirePermission(h.repo, PermMembersManage))
+	links.POST("", h.CreateInviteLink)
+	links.GET("", h.ListInviteLinks)
+	links.DELETE("/:linkId", h.RevokeInviteLink)
+
+	join := r.Group("/invite-links/:token")
+	join.GET("", h.PreviewInviteLink)
+	join.POST("/join", h.mw, h.JoinInviteLink)
+}
+
+type createInvitationReq struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type acceptInvitationReq struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type createInviteLinkReq struct {
+	Role           string `json:"role" binding:"required"`
+	MaxUses        *int   `json:"max_uses"`
+	ExpiresInHours *int   `json:"expires_in_hours"`
+}
+
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	inviterID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	var req createInvitationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	role := Role(strings.TrimSpace(req.Role))
+	inv, err := h.svc.Create(c.Request.Context(), c.Param("id"), inviterID, req.Email, role)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, gin.H{"invitation": inv})
+	case errors.Is(err, ErrInvalidEmail):
+		httpx.Unprocessable(c, "invalid email", map[string]string{"email": "valid email address"})
+	case errors.Is(err, ErrInvalidRole):
+		httpx.Unprocessable(c, "invalid role", map[string]string{"role": "owner|member|viewer"})
+	case errors.Is(err, ErrAlreadyMember):
+		httpx.Conflict(c, "user already a member")
+	case errors.Is(err, ErrOwnerRoleRequired):
+		httpx.Error(c, http.StatusForbidden, "only owners can invite owners", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
+
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	items, err := h.svc.ListPending(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": items})
+}
+
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	inviteID := c.Param("inviteId")
+	if _, err := uuid.Parse(inviteID); err != nil {
+		httpx.BadRequest(c, "invalid invitation id", nil)
+		return
+	}
+
+	err := h.svc.Revoke(c.Request.Context(), c.Param("id"), inviteID)
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case errors.Is(err, ErrInvitationNotFound):
+		httpx.Error(c, http.StatusNotFound, "invitation not found", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
+
+func (h *InvitationHandler) PreviewInvitation(c *gin.Context) {
+	p, err := h.svc.Preview(c.Request.Context(), c.Query("token"))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"invitation": p})
+	case errors.Is(err, ErrInvalidInvitation):
+		httpx.Error(c, http.StatusNotFound, "invitation is invalid or expired", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
+
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	userID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	var req acceptInvitationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	workspaceID, err := h.svc.Accept(c.Request.Context(), req.Token, userID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"workspace_id": workspaceID})
+	case errors.Is(err, ErrInvalidInvitation):
+		httpx.Error(c, http.StatusNotFound, "invitation is invalid or expired", nil)
+	case errors.Is(err, ErrInvitationEmailMismatch):
+		httpx.Error(c, http.StatusForbidden, "invitation was sent to a different email", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
+
+func (h *InvitationHandler) CreateInviteLink(c *gin.Context) {
+	creatorID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	var req createInviteLinkReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	var expiresIn time.Duration
+	if req.ExpiresInHours != nil {
+		if *req.ExpiresInHours <= 0 {
+			writeInviteLinkErr(c, ErrInvalidExpiry)
+			return
+		}
+		expiresIn = time.Duration(*req.ExpiresInHours) * time.Hour
+	}
+
+	role := Role(strings.TrimSpace(req.Role))
+	link, err := h.svc.CreateLink(c.Request.Context(), c.Param("id"), creatorID, role, req.MaxUses, expiresIn)
+	if err != nil {
+		writeInviteLinkErr(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"invite_link": link})
+}
+
+func (h *InvitationHandler) ListInviteLinks(c *gin.Context) {
+	items, err := h.svc.ListLinks(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invite_links": items})
+}
+
+func (h *InvitationHandler) RevokeInviteLink(c *gin.Context) {
+	linkID := c.Param("linkId")
+	if _, err := uuid.Parse(linkID); err != nil {
+		httpx.BadRequest(c, "invalid invite link id", nil)
+		return
+	}
+
+	if err := h.svc.RevokeLink(c.Request.Context(), c.Param("id"), linkID); err != nil {
+		writeInviteLinkErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *InvitationHandler) PreviewInviteLink(c *gin.Context) {
+	p, err := h.svc.PreviewLink(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		writeInviteLinkErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invite_link": p})
+}
+
+func (h *InvitationHandler) JoinInviteLink(c *gin.Context) {
+	userID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	workspaceID, err := h.svc.Join(c.Request.Context(), c.Param("token"), userID)
+	if err != nil {
+		writeInviteLinkErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"workspace_id": workspaceID})
+}
+
+func writeInviteLinkErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRole):
+		httpx.Unprocessable(c, "invalid role", map[string]string{"role": "member|viewer"})
+	case errors.Is(err, ErrInvalidMaxUses):
+		httpx.Unprocessable(c, "invalid max uses", map[string]string{"max_uses": "optional int, 1..1000"})
+	case errors.Is(err, ErrInvalidExpiry):
+		httpx.Unprocessable(c, "invalid expiry", map[string]string{"expires_in_hours": "optional int, 1..720"})
+	case errors.Is(err, ErrInviteLinkNotFound):
+		httpx.Error(c, http.StatusNotFound, "invite link not found", nil)
+	case errors.Is(err, ErrInvalidInviteLink):
+		httpx.Error(c, http.StatusNotFound, "invite link is invalid, expired or used up", nil)
+	case errors.Is(err, ErrAlreadyMember):
+		httpx.Conflict(c, "user already a member")
+	case errors.Is(err, ErrOwnerRoleRequired):
+		httpx.Error(c, http.StatusForbidden, "only owners can create owner invite links", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
@@ -0,0 +1,211 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/skelbigo/FinanceTracker/internal/audit"
+)
+
+const invitationColumns = `id::text, workspace_id::text, email, role, invited_by::text, expires_at, created_at`
+
+func scanInvitation(row pgx.Row) (Invitation, error) {
+	var inv Invitation
+	var role string
+	if err := row.Scan(&inv.ID, &inv.WorkspaceID, &inv.Email, &role, &inv.InvitedBy, &inv.ExpiresAt, &inv.CreatedAt); err != nil {
+		return Invitation{}, err
+	}
+	inv.Role = Role(role)
+	return inv, nil
+}
+
+// CreateInvitation stores a new pending invitation and revokes any earlier
+// pending invitation for the same email, so only the latest link works.
+func (r *Repo) CreateInvitation(ctx context.Context, workspaceID, email string, role Role, invitedBy, tokenHash string, expiresAt time.Time) (Invitation, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Invitation{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+UPDATE workspace_invitations
+SET revoked_at = now()
+WHERE workspace_id = $1::uuid
+  AND email = $2
+  AND accepted_at IS NULL
+  AND revoked_at IS NULL
+`, workspaceID, email); err != nil {
+		return Invitation{}, err
+	}
+
+	inv, err := scanInvitation(tx.QueryRow(ctx, `
+INSERT INTO workspace_invitations (workspace_id, email, role, token_hash, invited_by, expires_at)
+VALUES ($1::uuid, $2, $3, $4, $5::uuid, $6)
+RETURNING `+invitationColumns, workspaceID, email, string(role), tokenHash, invitedBy, expiresAt))
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Invitation{}, err
+	}
+	return inv, nil
+}
+
+func (r *Repo) ListPendingInvitations(ctx context.Context, workspaceID string) ([]Invitation, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT `+invitationColumns+`
+FROM workspace_invitations
+WHERE workspace_id = $1::uuid
+  AND accepted_at IS NULL
+  AND revoked_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC
+`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) RevokeInvitation(ctx context.Context, workspaceID, invitationID string) error {
+	ct, err := r.pool.Exec(ctx, `
+UPDATE workspace_invitations
+SET revoked_at = now()
+WHERE id = $2::uuid
+  AND workspace_id = $1::uuid
+  AND accepted_at IS NULL
+  AND revoked_at IS NULL
+`, workspaceID, invitationID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (r *Repo) GetPendingInvitationByHash(ctx context.Context, tokenHash string) (InvitationPreview, error) {
+	const q = `
+SELECT i.workspace_id::text, w.name, i.email, i.role, i.expires_at
+FROM workspace_invitations i
+JOIN workspaces w ON w.id = i.workspace_id
+WHERE i.token_hash = $1
+  AND w.deleted_at IS NULL
+  AND i.accepted_at IS NULL
+  AND i.revoked_at IS NULL
+  AND i.expires_at > now()
+`
+	var p InvitationPreview
+	var role string
+	err := r.pool.QueryRow(ctx, q, tokenHash).Scan(&p.WorkspaceID, &p.WorkspaceName, &p.Email, &role, &p.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return InvitationPreview{}, ErrInvalidInvitation
+		}
+		return InvitationPreview{}, err
+	}
+	p.Role = Role(role)
+	return p, nil
+}
+
+// pendingInvitation is the locked invitation row checked by AcceptInvitation.
+type pendingInvitation struct {
+	Email      string
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// acceptableBy returns ErrInvalidInvitation for used, revoked or expired
+// invitations and ErrInvitationEmailMismatch when userEmail is not the invitee.
+func (inv pendingInvitation) acceptableBy(userEmail string, now time.Time) error {
+	if inv.AcceptedAt != nil || inv.RevokedAt != nil || !now.Before(inv.ExpiresAt) {
+		return ErrInvalidInvitation
+	}
+	if !strings.EqualFold(strings.TrimSpace(userEmail), inv.Email) {
+		return ErrInvitationEmailMismatch
+	}
+	return nil
+}
+
+// AcceptInvitation consumes a pending invitation on behalf of userID and adds
+// the membership. An existing membership is kept as-is.
+func (r *Repo) AcceptInvitation(ctx context.Context, tokenHash, userID string) (string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id, workspaceID, role string
+	var inv pendingInvitation
+	err = tx.QueryRow(ctx, `
+SELECT id::text, workspace_id::text, email, role, expires_at, accepted_at, revoked_at
+FROM workspace_invitations
+WHERE token_hash = $1
+  AND workspace_id IN (SELECT id FROM workspaces WHERE deleted_at IS NULL)
+FOR UPDATE
+`, tokenHash).Scan(&id, &workspaceID, &inv.Email, &role, &inv.ExpiresAt, &inv.AcceptedAt, &inv.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrInvalidInvitation
+		}
+		return "", err
+	}
+
+	var userEmail string
+	if err := tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1::uuid`, userID).Scan(&userEmail); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
+		return "", err
+	}
+	if err := inv.acceptableBy(userEmail, time.Now()); err != nil {
+		return "", err
+	}
+
+	ct, err := tx.Exec(ctx, `
+INSERT INTO workspaces_members (workspace_id, user_id, role)
+VALUES ($1::uuid, $2::uuid, $3)
+ON CONFLICT DO NOTHING
+`, workspaceID, userID, role)
+	if err != nil {
+		return "", err
+	}
+	if ct.RowsAffected() > 0 {
+		if err := recordMember(ctx, tx, workspaceID, userID, userID, audit.ActionCreate,
+			nil, &memberSnapshot{UserID: userID, Role: Role(role)}); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE workspace_invitations
+SET accepted_at = now(), accepted_by = $2::uuid
+WHERE id = $1::uuid
+`, id, userID); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return workspaceID, nil
+}
@@ -0,0 +1,142 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/mailer"
+)
+
+type InvitationService struct {
+	repo        *Repo
+	mailer      mailer.Mailer
+	ttl         time.Duration
+	baseURL     string
+	returnToken bool
+}
+
+func NewInvitationService(repo *Repo, m mailer.Mailer, ttl time.Duration, baseURL string, returnToken bool) *InvitationService {
+	return &InvitationService{
+		repo:        repo,
+		mailer:      m,
+		ttl:         ttl,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		returnToken: returnToken,
+	}
+}
+
+type CreatedInvitation struct {
+	Invitation
+	// Token is only exposed outside prod, mirroring password reset.
+	Token     string `json:"token,omitempty"`
+	EmailSent bool   `json:"email_sent"`
+}
+
+func normalizeInviteEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+func (s *InvitationService) Create(ctx context.Context, workspaceID, inviterID, email string, role Role) (CreatedInvitation, error) {
+	email, err := normalizeInviteEmail(email)
+	if err != nil {
+		return CreatedInvitation{}, err
+	}
+	if role != RoleOwner && role != RoleMember && role != RoleViewer {
+		return CreatedInvitation{}, ErrInvalidRole
+	}
+	if role == RoleOwner {
+		if err := requireOwner(ctx, s.repo, workspaceID, inviterID); err != nil {
+			return CreatedInvitation{}, err
+		}
+	}
+
+	if userID, err := s.repo.FindUserIDByEmail(ctx, email); err == nil {
+		existing, err := s.repo.GetUserRole(ctx, workspaceID, userID)
+		if err != nil {
+			return CreatedInvitation{}, err
+		}
+		if existing != "" {
+			return CreatedInvitation{}, ErrAlreadyMember
+		}
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return CreatedInvitation{}, err
+	}
+
+	ws, _, err := s.repo.GetWorkspaceWithRole(ctx, workspaceID, inviterID)
+	if err != nil {
+		return CreatedInvitation{}, err
+	}
+
+	token, err := auth.GenerateResetToken()
+	if err != nil {
+		return CreatedInvitation{}, err
+	}
+	inv, err := s.repo.CreateInvitation(ctx, workspaceID, email, role, inviterID, auth.HashResetToken(token), time.Now().Add(s.ttl))
+	if err != nil {
+		return CreatedInvitation{}, err
+	}
+
+	out := CreatedInvitation{Invitation: inv}
+	if err := s.mailer.Send(ctx, s.invitationMessage(ws, inv, token)); err != nil {
+		log.Printf("invitation %s: send email: %v", inv.ID, err)
+	} else {
+		out.EmailSent = true
+	}
+	if s.returnToken {
+		out.Token = token
+	}
+	return out, nil
+}
+
+func (s *InvitationService) invitationMessage(ws Workspace, inv Invitation, token string) mailer.Message {
+	link := s.baseURL + "/invite?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf(
+		"You have been invited to join the workspace %q on FinanceTracker as %s.\n\n"+
+			"Open the link below to accept the invitation. If you don't have an account yet, register with this email address.\n\n"+
+			"%s\n\nThe link expires on %s.\n",
+		ws.Name, inv.Role, link, inv.ExpiresAt.UTC().Format("2006-01-02 15:04 MST"),
+	)
+	return mailer.Message{
+		To:      inv.Email,
+		Subject: "Invitation to " + ws.Name,
+		Body:    body,
+	}
+}
+
+func (s *InvitationService) ListPending(ctx context.Context, workspaceID string) ([]Invitation, error) {
+	return s.repo.ListPendingInvitations(ctx, workspaceID)
+}
+
+func (s *InvitationService) Revoke(ctx context.Context, workspaceID, invitationID string) error {
+	return s.repo.RevokeInvitation(ctx, workspaceID, invitationID)
+}
+
+func (s *InvitationService) Preview(ctx context.Context, token string) (InvitationPreview, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return InvitationPreview{}, ErrInvalidInvitation
+	}
+	return s.repo.GetPendingInvitationByHash(ctx, auth.HashResetToken(token))
+}
+
+// Accept adds userID to the invited workspace and returns its id. The user's
+// email must match the address the invitation was sent to.
+func (s *InvitationService) Accept(ctx context.Context, token, userID string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", ErrInvalidInvitation
+	}
+	return s.repo.AcceptInvitation(ctx, auth.HashResetToken(token), userID)
+}
@@ -0,0 +1,63 @@
+package workspaces
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNormalizeInviteEmail(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "alice@example.com", want: "alice@example.com"},
+		{in: "  Alice@Example.COM ", want: "alice@example.com"},
+		{in: "", wantErr: true},
+		{in: "alice", wantErr: true},
+		{in: "Alice <alice@example.com>", wantErr: true},
+		{in: "alice@example.com, bob@example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeInviteEmail(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidEmail) {
+				t.Errorf("normalizeInviteEmail(%q) err = %v, want ErrInvalidEmail", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeInviteEmail(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestPendingInvitationAcceptableBy(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	pending := pendingInvitation{Email: "alice@example.com", ExpiresAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name      string
+		inv       pendingInvitation
+		userEmail string
+		want      error
+	}{
+		{name: "matching email", inv: pending, userEmail: "alice@example.com"},
+		{name: "email case and spaces ignored", inv: pending, userEmail: " Alice@Example.com "},
+		{name: "different email", inv: pending, userEmail: "bob@example.com", want: ErrInvitationEmailMismatch},
+		{name: "expired", inv: pendingInvitation{Email: pending.Email, ExpiresAt: earlier}, userEmail: pending.Email, want: ErrInvalidInvitation},
+		{name: "expires now", inv: pendingInvitation{Email: pending.Email, ExpiresAt: now}, userEmail: pending.Email, want: ErrInvalidInvitation},
+		{name: "already accepted", inv: pendingInvitation{Email: pending.Email, ExpiresAt: pending.ExpiresAt, AcceptedAt: &earlier}, userEmail: pending.Email, want: ErrInvalidInvitation},
+		{name: "revoked", inv: pendingInvitation{Email: pending.Email, ExpiresAt: pending.ExpiresAt, RevokedAt: &earlier}, userEmail: pending.Email, want: ErrInvalidInvitation},
+		{name: "reuse by another user", inv: pendingInvitation{Email: pending.Email, ExpiresAt: pending.ExpiresAt, AcceptedAt: &earlier}, userEmail: "bob@example.com", want: ErrInvalidInvitation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.inv.acceptableBy(tt.userEmail, now); !errors.Is(err, tt.want) {
+				t.Errorf("acceptableBy = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
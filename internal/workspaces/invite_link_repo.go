@@ -0,0 +1,156 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const inviteLinkColumns = `id::text, workspace_id::text, role, max_uses, use_count, created_by::text, expires_at, created_at`
+
+// inviteLinkUsable filters links that can still be redeemed.
+const inviteLinkUsable = `revoked_at IS NULL
+  AND expires_at > now()
+  AND (max_uses IS NULL OR use_count < max_uses)`
+
+func scanInviteLink(row pgx.Row) (InviteLink, error) {
+	var l InviteLink
+	var role string
+	if err := row.Scan(&l.ID, &l.WorkspaceID, &role, &l.MaxUses, &l.UseCount, &l.CreatedBy, &l.ExpiresAt, &l.CreatedAt); err != nil {
+		return InviteLink{}, err
+	}
+	l.Role = Role(role)
+	return l, nil
+}
+
+func (r *Repo) CreateInviteLink(ctx context.Context, workspaceID string, role Role, createdBy, tokenHash string, maxUses *int, expiresAt time.Time) (InviteLink, error) {
+	return scanInviteLink(r.pool.QueryRow(ctx, `
+INSERT INTO workspace_invite_links (workspace_id, role, token_hash, max_uses, created_by, expires_at)
+VALUES ($1::uuid, $2, $3, $4, $5::uuid, $6)
+RETURNING `+inviteLinkColumns, workspaceID, string(role), tokenHash, maxUses, createdBy, expiresAt))
+}
+
+func (r *Repo) ListInviteLinks(ctx context.Context, workspaceID string) ([]InviteLink, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT `+inviteLinkColumns+`
+FROM workspace_invite_links
+WHERE workspace_id = $1::uuid
+  AND `+inviteLinkUsable+`
+ORDER BY created_at DESC
+`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []InviteLink{}
+	for rows.Next() {
+		l, err := scanInviteLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) RevokeInviteLink(ctx context.Context, workspaceID, linkID string) error {
+	ct, err := r.pool.Exec(ctx, `
+UPDATE workspace_invite_links
+SET revoked_at = now()
+WHERE id = $2::uuid
+  AND workspace_id = $1::uuid
+  AND revoked_at IS NULL
+`, workspaceID, linkID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrInviteLinkNotFound
+	}
+	return nil
+}
+
+func (r *Repo) GetInviteLinkByHash(ctx context.Context, tokenHash string) (InviteLinkPreview, error) {
+	var p InviteLinkPreview
+	var role string
+	err := r.pool.QueryRow(ctx, `
+SELECT l.workspace_id::text, w.name, l.role, l.expires_at
+FROM workspace_invite_links l
+JOIN workspaces w ON w.id = l.workspace_id
+WHERE l.token_hash = $1
+  AND w.deleted_at IS NULL
+  AND `+inviteLinkUsable, tokenHash).Scan(&p.WorkspaceID, &p.WorkspaceName, &role, &p.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return InviteLinkPreview{}, ErrInvalidInviteLink
+		}
+		return InviteLinkPreview{}, err
+	}
+	p.Role = Role(role)
+	return p, nil
+}
+
+// inviteLinkState is the locked link row checked by RedeemInviteLink. It
+// mirrors inviteLinkUsable.
+type inviteLinkState struct {
+	MaxUses   *int
+	UseCount  int
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+func (l inviteLinkState) redeemable(now time.Time) bool {
+	if l.RevokedAt != nil || !now.Before(l.ExpiresAt) {
+		return false
+	}
+	return l.MaxUses == nil || l.UseCount < *l.MaxUses
+}
+
+// RedeemInviteLink adds userID to the link's workspace and counts the use.
+// Existing members get ErrAlreadyMember and do not consume a use.
+func (r *Repo) RedeemInviteLink(ctx context.Context, tokenHash, userID string) (string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id, workspaceID, role string
+	var state inviteLinkState
+	err = tx.QueryRow(ctx, `
+SELECT id::text, workspace_id::text, role, max_uses, use_count, expires_at, revoked_at
+FROM workspace_invite_links
+WHERE token_hash = $1
+  AND workspace_id IN (SELECT id FROM workspaces WHERE deleted_at IS NULL)
+FOR UPDATE
+`, tokenHash).Scan(&id, &workspaceID, &role, &state.MaxUses, &state.UseCount, &state.ExpiresAt, &state.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrInvalidInviteLink
+		}
+		return "", err
+	}
+	if !state.redeemable(time.Now()) {
+		return "", ErrInvalidInviteLink
+	}
+
+	if err := addMember(ctx, tx, userID, workspaceID, userID, Role(role)); err != nil {
+		return workspaceID, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE workspace_invite_links
+SET use_count = use_count + 1
+WHERE id = $1::uuid
+`, id); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return workspaceID, nil
+}
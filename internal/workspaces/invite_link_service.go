@@ -0,0 +1,78 @@
+package workspaces
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+)
+
+const (
+	maxInviteLinkUses   = 1000
+	maxInviteLinkExpiry = 30 * 24 * time.Hour
+)
+
+type CreatedInviteLink struct {
+	InviteLink
+	// URL carries the raw token; it is only available right after creation.
+	URL string `json:"url"`
+}
+
+// CreateLink creates a shareable join link. A nil maxUses means unlimited;
+// a zero expiresIn falls back to the invitation TTL.
+func (s *InvitationService) CreateLink(ctx context.Context, workspaceID, creatorID string, role Role, maxUses *int, expiresIn time.Duration) (CreatedInviteLink, error) {
+	// Links are anonymous, so they never grant ownership.
+	if role != RoleMember && role != RoleViewer {
+		return CreatedInviteLink{}, ErrInvalidRole
+	}
+	if maxUses != nil && (*maxUses < 1 || *maxUses > maxInviteLinkUses) {
+		return CreatedInviteLink{}, ErrInvalidMaxUses
+	}
+	if expiresIn == 0 {
+		expiresIn = s.ttl
+	}
+	if expiresIn < time.Hour || expiresIn > maxInviteLinkExpiry {
+		return CreatedInviteLink{}, ErrInvalidExpiry
+	}
+
+	token, err := auth.GenerateResetToken()
+	if err != nil {
+		return CreatedInviteLink{}, err
+	}
+	link, err := s.repo.CreateInviteLink(ctx, workspaceID, role, creatorID, auth.HashResetToken(token), maxUses, time.Now().Add(expiresIn))
+	if err != nil {
+		return CreatedInviteLink{}, err
+	}
+	return CreatedInviteLink{
+		InviteLink: link,
+		URL:        s.baseURL + "/join/" + url.PathEscape(token),
+	}, nil
+}
+
+func (s *InvitationService) ListLinks(ctx context.Context, workspaceID string) ([]InviteLink, error) {
+	return s.repo.ListInviteLinks(ctx, workspaceID)
+}
+
+func (s *InvitationService) RevokeLink(ctx context.Context, workspaceID, linkID string) error {
+	return s.repo.RevokeInviteLink(ctx, workspaceID, linkID)
+}
+
+func (s *InvitationService) PreviewLink(ctx context.Context, token string) (InviteLinkPreview, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return InviteLinkPreview{}, ErrInvalidInviteLink
+	}
+	return s.repo.GetInviteLinkByHash(ctx, auth.HashResetToken(token))
+}
+
+// Join redeems a link for userID and returns the workspace id, which is also
+// set alongside ErrAlreadyMember so callers can switch to it.
+func (s *InvitationService) Join(ctx context.Context, token, userID string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", ErrInvalidInviteLink
+	}
+	return s.repo.RedeemInviteLink(ctx, auth.HashResetToken(token), userID)
+}
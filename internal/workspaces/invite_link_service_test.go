@@ -0,0 +1,63 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateLink_Validation(t *testing.T) {
+	s := &InvitationService{ttl: 7 * 24 * time.Hour}
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name      string
+		role      Role
+		maxUses   *int
+		expiresIn time.Duration
+		want      error
+	}{
+		{name: "owner role", role: RoleOwner, want: ErrInvalidRole},
+		{name: "unknown role", role: "admin", want: ErrInvalidRole},
+		{name: "zero max uses", role: RoleMember, maxUses: intPtr(0), want: ErrInvalidMaxUses},
+		{name: "too many max uses", role: RoleViewer, maxUses: intPtr(maxInviteLinkUses + 1), want: ErrInvalidMaxUses},
+		{name: "expiry too short", role: RoleViewer, expiresIn: time.Minute, want: ErrInvalidExpiry},
+		{name: "expiry too long", role: RoleViewer, expiresIn: maxInviteLinkExpiry + time.Hour, want: ErrInvalidExpiry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateLink(context.Background(), "ws", "creator", tt.role, tt.maxUses, tt.expiresIn)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CreateLink err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestInviteLinkStateRedeemable(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+	two := 2
+
+	tests := []struct {
+		name  string
+		state inviteLinkState
+		want  bool
+	}{
+		{name: "unlimited", state: inviteLinkState{UseCount: 500, ExpiresAt: later}, want: true},
+		{name: "uses left", state: inviteLinkState{MaxUses: &two, UseCount: 1, ExpiresAt: later}, want: true},
+		{name: "used up", state: inviteLinkState{MaxUses: &two, UseCount: 2, ExpiresAt: later}, want: false},
+		{name: "expired", state: inviteLinkState{ExpiresAt: earlier}, want: false},
+		{name: "expires now", state: inviteLinkState{ExpiresAt: now}, want: false},
+		{name: "revoked", state: inviteLinkState{ExpiresAt: later, RevokedAt: &earlier}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.redeemable(now); got != tt.want {
+				t.Errorf("redeemable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,17 +3,21 @@ package workspaces
 import (
 	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/skelbigo/FinanceTracker/internal/auth"
 	"github.com/skelbigo/FinanceTracker/internal/httpx"
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
 )
 
 const (
 	CtxWorkspaceIDKey   = "workspace_id"
 	CtxWorkspaceRoleKey = "workspace_role"
+	CtxLocationKey      = "workspace_location"
 )
 
 type RoleProvider interface {
@@ -21,6 +25,12 @@ type RoleProvider interface {
 	WorkspaceExists(ctx context.Context, workspaceID string) (bool, error)
 }
 
+// TimezoneProvider is optionally implemented by a RoleProvider; when present,
+// RequireWorkspaceRole also resolves the caller's effective time zone.
+type TimezoneProvider interface {
+	EffectiveTimezone(ctx context.Context, workspaceID, userID string) (string, error)
+}
+
 func roleRank(r Role) int {
 	switch r {
 	case RoleViewer:
@@ -56,87 +66,170 @@ func GetWorkspaceID(c *gin.Context) (string, bool) {
 	return id, ok
 }
 
+// GetLocation returns the time zone resolved for the current request, UTC if none.
+func GetLocation(c *gin.Context) *time.Location {
+	v, ok := c.Get(CtxLocationKey)
+	if !ok {
+		return time.UTC
+	}
+	loc, ok := v.(*time.Location)
+	if !ok || loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// AccessProvider is optionally implemented by a RoleProvider; when present,
+// custom roles are taken into account, otherwise built-in presets apply.
+type AccessProvider interface {
+	GetMemberAccess(ctx context.Context, workspaceID, userID string) (Access, error)
+}
+
 func RequireWorkspaceRole(repo RoleProvider, minRole Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if repo == nil {
-			httpx.Internal(c)
-			c.Abort()
+		access, ok := resolveMembership(c, repo, map[string]string{"required": string(minRole)})
+		if !ok {
 			return
 		}
 
-		v, exists := c.Get(auth.CtxUserIDKey)
-		userID, ok := v.(string)
-		if !exists || !ok || userID == "" {
-			httpx.Unauthorized(c, "invalid token")
+		if !RoleAtLeast(access.Role, minRole) {
+			httpx.Error(c, http.StatusForbidden, "insufficient role", map[string]string{
+				"required": string(minRole),
+				"actual":   string(access.Role),
+			})
 			c.Abort()
 			return
 		}
 
-		workspaceID := c.Param("id")
-		if workspaceID == "" {
-			workspaceID = c.Param("workspaceId")
-		}
-		if workspaceID == "" {
-			httpx.BadRequest(c, "invalid workspace id", map[string]string{"id": "required"})
-			c.Abort()
+		c.Next()
+	}
+}
+
+// RequirePermission lets the request through if the caller's built-in role
+// or custom role grants perm.
+func RequirePermission(repo RoleProvider, perm Permission) gin.HandlerFunc {
+	return RequireAnyPermission(repo, perm)
+}
+
+// RequireAnyPermission lets the request through if the caller holds at least
+// one of perms.
+func RequireAnyPermission(repo RoleProvider, perms ...Permission) gin.HandlerFunc {
+	names := make([]string, 0, len(perms))
+	for _, p := range perms {
+		names = append(names, string(p))
+	}
+	required := strings.Join(names, "|")
+
+	return func(c *gin.Context) {
+		access, ok := resolveMembership(c, repo, map[string]string{"required": required})
+		if !ok {
 			return
 		}
 
-		if _, err := uuid.Parse(workspaceID); err != nil {
-			httpx.BadRequest(c, "invalid workspace id", map[string]string{"id": "must be uuid"})
+		if !access.HasAny(perms...) {
+			httpx.Error(c, http.StatusForbidden, "missing permission", map[string]string{
+				"required": required,
+			})
 			c.Abort()
 			return
 		}
 
-		roleStr, err := repo.GetUserRole(c.Request.Context(), workspaceID, userID)
+		c.Next()
+	}
+}
+
+// resolveMembership validates the workspace id, loads the caller's access and
+// stores workspace id, role, access and location in the context. It writes
+// the error response and aborts when it returns false.
+func resolveMembership(c *gin.Context, repo RoleProvider, forbiddenDetails map[string]string) (Access, bool) {
+	if repo == nil {
+		httpx.Internal(c)
+		c.Abort()
+		return Access{}, false
+	}
+
+	v, exists := c.Get(auth.CtxUserIDKey)
+	userID, ok := v.(string)
+	if !exists || !ok || userID == "" {
+		httpx.Unauthorized(c, "invalid token")
+		c.Abort()
+		return Access{}, false
+	}
+
+	workspaceID := c.Param("id")
+	if workspaceID == "" {
+		workspaceID = c.Param("workspaceId")
+	}
+	if workspaceID == "" {
+		httpx.BadRequest(c, "invalid workspace id", map[string]string{"id": "required"})
+		c.Abort()
+		return Access{}, false
+	}
+
+	if _, err := uuid.Parse(workspaceID); err != nil {
+		httpx.BadRequest(c, "invalid workspace id", map[string]string{"id": "must be uuid"})
+		c.Abort()
+		return Access{}, false
+	}
+
+	var access Access
+	if ap, ok := repo.(AccessProvider); ok {
+		a, err := ap.GetMemberAccess(c.Request.Context(), workspaceID, userID)
 		if err != nil {
 			httpx.Internal(c)
 			c.Abort()
-			return
+			return Access{}, false
 		}
-
-		if roleStr == "" {
-			exists, err := repo.WorkspaceExists(c.Request.Context(), workspaceID)
-			if err != nil {
-				httpx.Internal(c)
-				c.Abort()
-				return
-			}
-			if !exists {
-				httpx.Error(c, http.StatusNotFound, "workspace not found", nil)
-				c.Abort()
-				return
-			}
-
-			httpx.Error(c, http.StatusForbidden, "not a workspace member", map[string]string{
-				"required": string(minRole),
-			})
+		access = a
+	} else {
+		roleStr, err := repo.GetUserRole(c.Request.Context(), workspaceID, userID)
+		if err != nil {
+			httpx.Internal(c)
 			c.Abort()
-			return
+			return Access{}, false
 		}
+		access = NewPresetAccess(Role(roleStr))
+	}
 
-		actual := Role(roleStr)
-		switch actual {
-		case RoleViewer, RoleMember, RoleOwner:
-			// valid role from DB
-		default:
+	if !access.IsMember() {
+		exists, err := repo.WorkspaceExists(c.Request.Context(), workspaceID)
+		if err != nil {
 			httpx.Internal(c)
 			c.Abort()
-			return
+			return Access{}, false
 		}
-
-		if !RoleAtLeast(actual, minRole) {
-			httpx.Error(c, http.StatusForbidden, "insufficient role", map[string]string{
-				"required": string(minRole),
-				"actual":   roleStr,
-			})
+		if !exists {
+			httpx.Error(c, http.StatusNotFound, "workspace not found", nil)
 			c.Abort()
-			return
+			return Access{}, false
 		}
 
-		c.Set(CtxWorkspaceIDKey, workspaceID)
-		c.Set(CtxWorkspaceRoleKey, actual)
+		httpx.Error(c, http.StatusForbidden, "not a workspace member", forbiddenDetails)
+		c.Abort()
+		return Access{}, false
+	}
 
-		c.Next()
+	switch access.Role {
+	case RoleViewer, RoleMember, RoleOwner:
+		// valid role from DB
+	default:
+		httpx.Internal(c)
+		c.Abort()
+		return Access{}, false
+	}
+
+	if tp, ok := repo.(TimezoneProvider); ok {
+		tz, err := tp.EffectiveTimezone(c.Request.Context(), workspaceID, userID)
+		if err != nil {
+			httpx.Internal(c)
+			c.Abort()
+			return Access{}, false
+		}
+		c.Set(CtxLocationKey, tzx.Load(tz))
 	}
+
+	c.Set(CtxWorkspaceIDKey, workspaceID)
+	c.Set(CtxWorkspaceRoleKey, access.Role)
+	c.Set(CtxAccessKey, access)
+	return access, true
 }
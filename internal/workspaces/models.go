@@ -6,10 +6,18 @@ type Workspace struct {
 	ID              string    `json:"id"`
 	Name            string    `json:"name"`
 	DefaultCurrency string    `json:"default_currency"`
+	Timezone        string    `json:"timezone"`
 	CreatedBy       string    `json:"created_by"`
 	CreatedAt       time.Time `json:"created_at"`
 }
 
+type DeletedWorkspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
 type Role string
 
 const (
@@ -33,9 +41,82 @@ type WorkspaceListItem struct {
 }
 
 type MemberInfo struct {
-	UserID    string    `json:"user_id"`
-	Email     string    `json:"email"`
-	Name      *string   `json:"name,omitempty"`
-	Role      Role      `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	UserID         string    `json:"user_id"`
+	Email          string    `json:"email"`
+	Name           *string   `json:"name,omitempty"`
+	Role           Role      `json:"role"`
+	CustomRoleID   *string   `json:"custom_role_id,omitempty"`
+	CustomRoleName *string   `json:"custom_role_name,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type Invitation struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Email       string    `json:"email"`
+	Role        Role      `json:"role"`
+	InvitedBy   *string   `json:"invited_by,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// InvitationPreview is what an invitee sees before accepting.
+type InvitationPreview struct {
+	WorkspaceID   string    `json:"workspace_id"`
+	WorkspaceName string    `json:"workspace_name"`
+	Email         string    `json:"email"`
+	Role          Role      `json:"role"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type InviteLink struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Role        Role      `json:"role"`
+	MaxUses     *int      `json:"max_uses,omitempty"`
+	UseCount    int       `json:"use_count"`
+	CreatedBy   *string   `json:"created_by,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type InviteLinkPreview struct {
+	WorkspaceID   string    `json:"workspace_id"`
+	WorkspaceName string    `json:"workspace_name"`
+	Role          Role      `json:"role"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type TransferStatus string
+
+const (
+	TransferPending   TransferStatus = "pending"
+	TransferAccepted  TransferStatus = "accepted"
+	TransferDeclined  TransferStatus = "declined"
+	TransferCancelled TransferStatus = "cancelled"
+)
+
+// OwnershipTransfer rows are kept after resolution as a record of who handed
+// ownership to whom.
+type OwnershipTransfer struct {
+	ID          string         `json:"id"`
+	WorkspaceID string         `json:"workspace_id"`
+	FromUserID  string         `json:"from_user_id"`
+	ToUserID    string         `json:"to_user_id"`
+	Status      TransferStatus `json:"status"`
+	CreatedAt   time.Time      `json:"created_at"`
+	ResolvedAt  *time.Time     `json:"resolved_at,omitempty"`
+}
+
+// CustomRole is a workspace-defined set of permissions assigned to
+// non-owner members in place of their built-in role preset.
+type CustomRole struct {
+	ID          string       `json:"id"`
+	WorkspaceID string       `json:"workspace_id"`
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+	CategoryIDs []string     `json:"category_ids"`
+	TxTypes     []string     `json:"tx_types"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
 }
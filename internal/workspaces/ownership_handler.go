@@ -0,0 +1,117 @@
+package workspaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/httpx"
+)
+
+type nominateOwnerReq struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+func (h *Handler) registerOwnershipRoutes(wsg gin.IRouter) {
+	g := wsg.Group("/ownership-transfer")
+	g.GET("", RequireWorkspaceRole(h.repo, RoleViewer), h.GetOwnershipTransfer)
+	g.GET("/history", RequireWorkspaceRole(h.repo, RoleOwner), h.ListOwnershipTransfers)
+	g.POST("", RequireWorkspaceRole(h.repo, RoleOwner), h.NominateOwner)
+	g.DELETE("", RequireWorkspaceRole(h.repo, RoleOwner), h.CancelOwnershipTransfer)
+	g.POST("/accept", RequireWorkspaceRole(h.repo, RoleViewer), h.AcceptOwnershipTransfer)
+	g.POST("/decline", RequireWorkspaceRole(h.repo, RoleViewer), h.DeclineOwnershipTransfer)
+}
+
+func (h *Handler) GetOwnershipTransfer(c *gin.Context) {
+	t, err := h.svc.PendingTransfer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeTransferErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"transfer": t})
+}
+
+func (h *Handler) ListOwnershipTransfers(c *gin.Context) {
+	items, err := h.svc.ListTransfers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"transfers": items})
+}
+
+func (h *Handler) NominateOwner(c *gin.Context) {
+	actorID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	var req nominateOwnerReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		httpx.BadRequest(c, "invalid user_id", nil)
+		return
+	}
+
+	t, err := h.svc.NominateOwner(c.Request.Context(), c.Param("id"), actorID, req.UserID)
+	if err != nil {
+		writeTransferErr(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"transfer": t})
+}
+
+func (h *Handler) CancelOwnershipTransfer(c *gin.Context) {
+	if err := h.svc.CancelTransfer(c.Request.Context(), c.Param("id")); err != nil {
+		writeTransferErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) AcceptOwnershipTransfer(c *gin.Context) {
+	userID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	if err := h.svc.AcceptTransfer(c.Request.Context(), c.Param("id"), userID); err != nil {
+		writeTransferErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) DeclineOwnershipTransfer(c *gin.Context) {
+	userID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	if err := h.svc.DeclineTransfer(c.Request.Context(), c.Param("id"), userID); err != nil {
+		writeTransferErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeTransferErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrTransferNotFound):
+		httpx.Error(c, http.StatusNotFound, "no pending ownership transfer", nil)
+	case errors.Is(err, ErrInvalidTransferTarget):
+		httpx.Unprocessable(c, "invalid transfer target", map[string]string{"user_id": "another member who is not an owner"})
+	case errors.Is(err, ErrNotTransferTarget):
+		httpx.Error(c, http.StatusForbidden, "ownership transfer is addressed to another member", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
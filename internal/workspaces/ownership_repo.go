@@ -0,0 +1,282 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/skelbigo/FinanceTracker/internal/audit"
+)
+
+const transferColumns = `id::text, workspace_id::text, from_user_id::text, to_user_id::text, status, created_at, resolved_at`
+
+func scanTransfer(row pgx.Row) (OwnershipTransfer, error) {
+	var t OwnershipTransfer
+	var status string
+	if err := row.Scan(&t.ID, &t.WorkspaceID, &t.FromUserID, &t.ToUserID, &status, &t.CreatedAt, &t.ResolvedAt); err != nil {
+		return OwnershipTransfer{}, err
+	}
+	t.Status = TransferStatus(status)
+	return t, nil
+}
+
+// pendingTransfer is a pending ownership transfer locked for resolving.
+type pendingTransfer struct {
+	ID         string
+	FromUserID string
+	ToUserID   string
+}
+
+// errStaleTransfer marks a nomination overtaken by a role change; it is
+// cancelled and reported as ErrTransferNotFound.
+var errStaleTransfer = errors.New("stale ownership transfer")
+
+// transferTarget checks that a member with role toRole ("" for a non-member)
+// may be nominated as the next owner by fromUserID.
+func transferTarget(fromUserID, toUserID string, toRole Role) error {
+	if toUserID == "" || toUserID == fromUserID || toRole == "" || toRole == RoleOwner {
+		return ErrInvalidTransferTarget
+	}
+	return nil
+}
+
+// resolvableBy checks that userID is the member the transfer was offered to.
+func (t pendingTransfer) resolvableBy(userID string) error {
+	if t.ToUserID != userID {
+		return ErrNotTransferTarget
+	}
+	return nil
+}
+
+// acceptable checks the nomination against the current roles of both sides
+// and returns the target's role, which the previous owner takes over. It is
+// stale once the nominating member is no longer an owner or the target has
+// left or become an owner.
+func (t pendingTransfer) acceptable(roles map[string]Role) (Role, error) {
+	toRole, ok := roles[t.ToUserID]
+	if roles[t.FromUserID] != RoleOwner || !ok || toRole == RoleOwner {
+		return "", errStaleTransfer
+	}
+	return toRole, nil
+}
+
+func lockPendingTransfer(ctx context.Context, tx pgx.Tx, workspaceID string) (pendingTransfer, error) {
+	var t pendingTransfer
+	err := tx.QueryRow(ctx, `
+SELECT id::text, from_user_id::text, to_user_id::text
+FROM workspace_ownership_transfers
+WHERE workspace_id = $1::uuid AND status = 'pending'
+FOR UPDATE
+`, workspaceID).Scan(&t.ID, &t.FromUserID, &t.ToUserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pendingTransfer{}, ErrTransferNotFound
+	}
+	return t, err
+}
+
+// CreateOwnershipTransfer nominates toUserID as the next owner, replacing any
+// pending nomination for the workspace.
+func (r *Repo) CreateOwnershipTransfer(ctx context.Context, workspaceID, fromUserID, toUserID string) (OwnershipTransfer, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Concurrent nominations queue on the workspace row, so each one sees
+	// the pending transfer of the one before and replaces it.
+	if _, err := tx.Exec(ctx, `
+SELECT 1 FROM workspaces WHERE id = $1::uuid FOR UPDATE
+`, workspaceID); err != nil {
+		return OwnershipTransfer{}, err
+	}
+
+	var role string
+	err = tx.QueryRow(ctx, `
+SELECT role FROM workspaces_members
+WHERE workspace_id = $1::uuid AND user_id = $2::uuid
+`, workspaceID, toUserID).Scan(&role)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return OwnershipTransfer{}, err
+	}
+	if err := transferTarget(fromUserID, toUserID, Role(role)); err != nil {
+		return OwnershipTransfer{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE workspace_ownership_transfers
+SET status = 'cancelled', resolved_at = now()
+WHERE workspace_id = $1::uuid AND status = 'pending'
+`, workspaceID); err != nil {
+		return OwnershipTransfer{}, err
+	}
+
+	t, err := scanTransfer(tx.QueryRow(ctx, `
+INSERT INTO workspace_ownership_transfers (workspace_id, from_user_id, to_user_id)
+VALUES ($1::uuid, $2::uuid, $3::uuid)
+RETURNING `+transferColumns, workspaceID, fromUserID, toUserID))
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return OwnershipTransfer{}, err
+	}
+	return t, nil
+}
+
+func (r *Repo) GetPendingTransfer(ctx context.Context, workspaceID string) (OwnershipTransfer, error) {
+	t, err := scanTransfer(r.pool.QueryRow(ctx, `
+SELECT `+transferColumns+`
+FROM workspace_ownership_transfers
+WHERE workspace_id = $1::uuid AND status = 'pending'
+`, workspaceID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OwnershipTransfer{}, ErrTransferNotFound
+		}
+		return OwnershipTransfer{}, err
+	}
+	return t, nil
+}
+
+// CloseTransfer cancels or declines the pending transfer. A non-empty
+// toUserID restricts it to transfers addressed to that user.
+func (r *Repo) CloseTransfer(ctx context.Context, workspaceID, toUserID string, status TransferStatus) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	t, err := lockPendingTransfer(ctx, tx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if toUserID != "" {
+		if err := t.resolvableBy(toUserID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE workspace_ownership_transfers
+SET status = $2, resolved_at = now()
+WHERE id = $1::uuid
+`, t.ID, string(status)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// AcceptTransfer swaps the roles of the nominating owner and userID in one
+// transaction and marks the transfer accepted.
+func (r *Repo) AcceptTransfer(ctx context.Context, workspaceID, userID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	t, err := lockPendingTransfer(ctx, tx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if err := t.resolvableBy(userID); err != nil {
+		return err
+	}
+	id, fromUserID, toUserID := t.ID, t.FromUserID, t.ToUserID
+
+	roles := map[string]Role{}
+	rows, err := tx.Query(ctx, `
+SELECT user_id::text, role FROM workspaces_members
+WHERE workspace_id = $1::uuid AND user_id = ANY($2::uuid[])
+FOR UPDATE
+`, workspaceID, []string{fromUserID, toUserID})
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var uid, role string
+		if err := rows.Scan(&uid, &role); err != nil {
+			rows.Close()
+			return err
+		}
+		roles[uid] = Role(role)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	toRole, err := t.acceptable(roles)
+	if errors.Is(err, errStaleTransfer) {
+		if _, err := tx.Exec(ctx, `
+UPDATE workspace_ownership_transfers
+SET status = 'cancelled', resolved_at = now()
+WHERE id = $1::uuid
+`, id); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		return ErrTransferNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE workspaces_members
+SET role = CASE WHEN user_id = $2::uuid THEN 'owner' ELSE $4 END
+WHERE workspace_id = $1::uuid AND user_id IN ($2::uuid, $3::uuid)
+`, workspaceID, toUserID, fromUserID, string(toRole)); err != nil {
+		return err
+	}
+
+	changes := []struct {
+		userID   string
+		from, to Role
+	}{
+		{toUserID, toRole, RoleOwner},
+		{fromUserID, RoleOwner, toRole},
+	}
+	for _, ch := range changes {
+		if err := recordMember(ctx, tx, workspaceID, userID, ch.userID, audit.ActionUpdate,
+			&memberSnapshot{UserID: ch.userID, Role: ch.from}, &memberSnapshot{UserID: ch.userID, Role: ch.to}); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE workspace_ownership_transfers
+SET status = 'accepted', to_role_before = $2, resolved_at = now()
+WHERE id = $1::uuid
+`, id, string(toRole)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *Repo) ListTransfers(ctx context.Context, workspaceID string) ([]OwnershipTransfer, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT `+transferColumns+`
+FROM workspace_ownership_transfers
+WHERE workspace_id = $1::uuid
+ORDER BY created_at DESC
+`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []OwnershipTransfer{}
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,81 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNominateOwner_Target(t *testing.T) {
+	s := &Service{}
+	for _, to := range []string{"", "  ", "u1"} {
+		if _, err := s.NominateOwner(context.Background(), "ws", "u1", to); !errors.Is(err, ErrInvalidTransferTarget) {
+			t.Errorf("NominateOwner(to=%q) err = %v, want ErrInvalidTransferTarget", to, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		to     string
+		toRole Role
+		want   error
+	}{
+		{name: "member", to: "u2", toRole: RoleMember},
+		{name: "viewer", to: "u2", toRole: RoleViewer},
+		{name: "self", to: "u1", toRole: RoleOwner, want: ErrInvalidTransferTarget},
+		{name: "another owner", to: "u2", toRole: RoleOwner, want: ErrInvalidTransferTarget},
+		{name: "not a member", to: "u2", want: ErrInvalidTransferTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := transferTarget("u1", tt.to, tt.toRole); !errors.Is(err, tt.want) {
+				t.Errorf("transferTarget err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPendingTransferResolvableBy(t *testing.T) {
+	tr := pendingTransfer{ID: "t1", FromUserID: "u1", ToUserID: "u2"}
+	tests := []struct {
+		name   string
+		userID string
+		want   error
+	}{
+		{name: "target", userID: "u2"},
+		{name: "nominating owner", userID: "u1", want: ErrNotTransferTarget},
+		{name: "other member", userID: "u3", want: ErrNotTransferTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tr.resolvableBy(tt.userID); !errors.Is(err, tt.want) {
+				t.Errorf("resolvableBy(%s) = %v, want %v", tt.userID, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPendingTransferAcceptable(t *testing.T) {
+	tr := pendingTransfer{ID: "t1", FromUserID: "u1", ToUserID: "u2"}
+	tests := []struct {
+		name     string
+		roles    map[string]Role
+		wantRole Role
+		want     error
+	}{
+		{name: "member target", roles: map[string]Role{"u1": RoleOwner, "u2": RoleMember}, wantRole: RoleMember},
+		{name: "viewer target", roles: map[string]Role{"u1": RoleOwner, "u2": RoleViewer}, wantRole: RoleViewer},
+		{name: "target promoted to owner", roles: map[string]Role{"u1": RoleOwner, "u2": RoleOwner}, want: errStaleTransfer},
+		{name: "target left", roles: map[string]Role{"u1": RoleOwner}, want: errStaleTransfer},
+		{name: "nominator demoted", roles: map[string]Role{"u1": RoleMember, "u2": RoleMember}, want: errStaleTransfer},
+		{name: "nominator left", roles: map[string]Role{"u2": RoleMember}, want: errStaleTransfer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, err := tr.acceptable(tt.roles)
+			if !errors.Is(err, tt.want) || role != tt.wantRole {
+				t.Errorf("acceptable = %q, %v; want %q, %v", role, err, tt.wantRole, tt.want)
+			}
+		})
+	}
+}
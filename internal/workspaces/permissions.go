@@ -0,0 +1,192 @@
+package workspaces
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const CtxAccessKey = "workspace_access"
+
+type Permission string
+
+const (
+	PermTxRead          Permission = "tx:read"
+	PermTxWriteOwn      Permission = "tx:write:own"
+	PermBudgetsWrite    Permission = "budgets:write" // also net-worth accounts, valuations and rates
+	PermCategoriesWrite Permission = "categories:write"
+	PermMembersManage   Permission = "members:manage"
+	PermExport          Permission = "export"
+
+	// PermTxWrite lets the owner and member presets edit anyone's
+	// transactions, as they always could. Custom roles cannot grant it.
+	PermTxWrite Permission = "tx:write"
+)
+
+// AllPermissions lists the permissions custom roles are composed from.
+var AllPermissions = []Permission{
+	PermTxRead,
+	PermTxWriteOwn,
+	PermBudgetsWrite,
+	PermCategoriesWrite,
+	PermMembersManage,
+	PermExport,
+}
+
+// PresetPermissions returns the permissions granted by a built-in role.
+func PresetPermissions(r Role) []Permission {
+	switch r {
+	case RoleOwner:
+		return append([]Permission{PermTxWrite}, AllPermissions...)
+	case RoleMember:
+		return []Permission{PermTxRead, PermTxWrite, PermBudgetsWrite, PermCategoriesWrite, PermExport}
+	case RoleViewer:
+		return []Permission{PermTxRead, PermExport}
+	default:
+		return nil
+	}
+}
+
+func ValidPermission(p Permission) bool {
+	for _, v := range AllPermissions {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizePermissions trims, validates and de-duplicates a permission list.
+func NormalizePermissions(in []string) ([]Permission, error) {
+	seen := map[Permission]struct{}{}
+	out := make([]Permission, 0, len(in))
+	for _, raw := range in {
+		p := Permission(strings.ToLower(strings.TrimSpace(raw)))
+		if p == "" {
+			continue
+		}
+		if !ValidPermission(p) {
+			return nil, ErrInvalidPermission
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+// Access is what a member may do in a workspace: either a built-in role
+// preset or the permissions of an assigned custom role.
+type Access struct {
+	Role           Role
+	CustomRoleID   *string
+	CustomRoleName string
+	Permissions    []Permission
+	// CategoryIDs, when non-empty, limits transaction writes to these categories.
+	CategoryIDs []string
+	// TxTypes, when non-empty, limits transaction writes to these types
+	// ("income", "expense").
+	TxTypes []string
+}
+
+func NewPresetAccess(r Role) Access {
+	return Access{Role: r, Permissions: PresetPermissions(r)}
+}
+
+func (a Access) IsMember() bool { return a.Role != "" }
+
+func (a Access) Has(p Permission) bool {
+	for _, v := range a.Permissions {
+		if v == p {
+			return true
+		}
+	}
+	// Full write access implies write access to one's own transactions.
+	if p == PermTxWriteOwn {
+		return a.Has(PermTxWrite)
+	}
+	return false
+}
+
+// HasAny reports whether the member holds at least one of perms.
+func (a Access) HasAny(perms ...Permission) bool {
+	for _, p := range perms {
+		if a.Has(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanWriteTx reports whether the member may change a transaction created by ownerID.
+func (a Access) CanWriteTx(userID, ownerID string) bool {
+	if a.Has(PermTxWrite) {
+		return true
+	}
+	return a.Has(PermTxWriteOwn) && userID == ownerID
+}
+
+// CanUseCategory reports whether a transaction write may target categoryID.
+func (a Access) CanUseCategory(categoryID *string) bool {
+	if len(a.CategoryIDs) == 0 {
+		return true
+	}
+	if categoryID == nil {
+		return false
+	}
+	for _, id := range a.CategoryIDs {
+		if id == *categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanUseTxType reports whether a transaction write may record txType.
+func (a Access) CanUseTxType(txType string) bool {
+	if len(a.TxTypes) == 0 {
+		return true
+	}
+	for _, t := range a.TxTypes {
+		if t == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTxTypes trims, validates and de-duplicates a transaction type
+// restriction. An empty result means every type is allowed.
+func NormalizeTxTypes(in []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(in))
+	for _, raw := range in {
+		t := strings.ToLower(strings.TrimSpace(raw))
+		if t == "" {
+			continue
+		}
+		if t != "income" && t != "expense" {
+			return nil, ErrInvalidTxTypes
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func GetAccess(c *gin.Context) (Access, bool) {
+	v, ok := c.Get(CtxAccessKey)
+	if !ok {
+		return Access{}, false
+	}
+	a, ok := v.(Access)
+	return a, ok
+}
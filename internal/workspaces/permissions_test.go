@@ -0,0 +1,133 @@
+package workspaces
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePermissions(t *testing.T) {
+	got, err := NormalizePermissions([]string{" Export ", "tx:read", "", "export", "budgets:write"})
+	if err != nil {
+		t.Fatalf("NormalizePermissions: %v", err)
+	}
+	want := []Permission{PermBudgetsWrite, PermExport, PermTxRead}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, in := range [][]string{{"tx:delete"}, {"tx:write"}, {"workspace:manage"}} {
+		if _, err := NormalizePermissions(in); !errors.Is(err, ErrInvalidPermission) {
+			t.Errorf("NormalizePermissions(%v) err = %v, want ErrInvalidPermission", in, err)
+		}
+	}
+}
+
+func TestAccessHas(t *testing.T) {
+	tests := []struct {
+		name   string
+		access Access
+		perm   Permission
+		want   bool
+	}{
+		{name: "owner writes tx", access: NewPresetAccess(RoleOwner), perm: PermTxWrite, want: true},
+		{name: "owner manages members", access: NewPresetAccess(RoleOwner), perm: PermMembersManage, want: true},
+		{name: "member writes own tx via tx:write", access: NewPresetAccess(RoleMember), perm: PermTxWriteOwn, want: true},
+		{name: "member cannot manage members", access: NewPresetAccess(RoleMember), perm: PermMembersManage, want: false},
+		{name: "viewer reads", access: NewPresetAccess(RoleViewer), perm: PermTxRead, want: true},
+		{name: "viewer cannot write own tx", access: NewPresetAccess(RoleViewer), perm: PermTxWriteOwn, want: false},
+		{name: "non-member", access: Access{}, perm: PermTxRead, want: false},
+		{name: "read-only custom role cannot write budgets or net worth", access: Access{Role: RoleMember, Permissions: []Permission{PermTxRead, PermExport}}, perm: PermBudgetsWrite, want: false},
+		{name: "own writes do not imply all writes", access: Access{Role: RoleViewer, Permissions: []Permission{PermTxWriteOwn}}, perm: PermTxWrite, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.access.Has(tt.perm); got != tt.want {
+				t.Errorf("Has(%s) = %v, want %v", tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessCanWriteTx(t *testing.T) {
+	own := Access{Role: RoleViewer, Permissions: []Permission{PermTxRead, PermTxWriteOwn}}
+	tests := []struct {
+		name    string
+		access  Access
+		ownerID string
+		want    bool
+	}{
+		{name: "member edits others", access: NewPresetAccess(RoleMember), ownerID: "u2", want: true},
+		{name: "own-only edits own", access: own, ownerID: "u1", want: true},
+		{name: "own-only cannot edit others", access: own, ownerID: "u2", want: false},
+		{name: "viewer cannot edit own", access: NewPresetAccess(RoleViewer), ownerID: "u1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.access.CanWriteTx("u1", tt.ownerID); got != tt.want {
+				t.Errorf("CanWriteTx = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessCanUseCategory(t *testing.T) {
+	food, rent := "food", "rent"
+	limited := Access{CategoryIDs: []string{food}}
+
+	if !(Access{}).CanUseCategory(nil) || !(Access{}).CanUseCategory(&rent) {
+		t.Error("unrestricted access should allow any category")
+	}
+	if !limited.CanUseCategory(&food) {
+		t.Error("restricted access should allow a listed category")
+	}
+	if limited.CanUseCategory(&rent) {
+		t.Error("restricted access should reject an unlisted category")
+	}
+	if limited.CanUseCategory(nil) {
+		t.Error("restricted access should reject uncategorized writes")
+	}
+}
+
+func TestAccessHasAny(t *testing.T) {
+	ownOnly := Access{Role: RoleViewer, Permissions: []Permission{PermTxWriteOwn}}
+	if !ownOnly.HasAny(PermTxRead, PermTxWriteOwn) {
+		t.Error("own-only writer should pass a read-or-write gate")
+	}
+	if (Access{Role: RoleViewer, Permissions: []Permission{PermExport}}).HasAny(PermTxRead, PermTxWriteOwn) {
+		t.Error("export-only role should not pass a read-or-write gate")
+	}
+	if !NewPresetAccess(RoleMember).HasAny(PermTxWriteOwn) {
+		t.Error("tx:write should satisfy tx:write:own in HasAny")
+	}
+}
+
+func TestAccessCanUseTxType(t *testing.T) {
+	if !(Access{}).CanUseTxType("income") || !(Access{}).CanUseTxType("expense") {
+		t.Error("unrestricted access should allow any type")
+	}
+	expenses := Access{TxTypes: []string{"expense"}}
+	if !expenses.CanUseTxType("expense") {
+		t.Error("restricted access should allow a listed type")
+	}
+	if expenses.CanUseTxType("income") {
+		t.Error("restricted access should reject an unlisted type")
+	}
+}
+
+func TestNormalizeTxTypes(t *testing.T) {
+	got, err := NormalizeTxTypes([]string{" Expense ", "", "income", "expense"})
+	if err != nil {
+		t.Fatalf("NormalizeTxTypes: %v", err)
+	}
+	if want := []string{"expense", "income"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got, err := NormalizeTxTypes(nil); err != nil || len(got) != 0 {
+		t.Errorf("NormalizeTxTypes(nil) = %v, %v; want empty", got, err)
+	}
+	if _, err := NormalizeTxTypes([]string{"transfer"}); !errors.Is(err, ErrInvalidTxTypes) {
+		t.Errorf("NormalizeTxTypes(transfer) err = %v, want ErrInvalidTxTypes", err)
+	}
+}
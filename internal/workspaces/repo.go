@@ -7,7 +7,10 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/skelbigo/FinanceTracker/internal/audit"
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
 	"strings"
+	"time"
 )
 
 type Repo struct {
@@ -18,7 +21,7 @@ func NewRepo(pool *pgxpool.Pool) *Repo {
 	return &Repo{pool: pool}
 }
 
-func (r *Repo) CreateWorkspaceWithOwner(ctx context.Context, createdBy, name, defaultCurrency string) (Workspace, error) {
+func (r *Repo) CreateWorkspaceWithOwner(ctx context.Context, createdBy, name, defaultCurrency, timezone string) (Workspace, error) {
 	name = strings.TrimSpace(name)
 	if name == "" {
 		return Workspace{}, errors.New("name is required")
@@ -27,6 +30,9 @@ func (r *Repo) CreateWorkspaceWithOwner(ctx context.Context, createdBy, name, de
 	if defaultCurrency == "" {
 		defaultCurrency = "UAH"
 	}
+	if timezone == "" {
+		timezone = tzx.Default
+	}
 
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
@@ -36,10 +42,10 @@ func (r *Repo) CreateWorkspaceWithOwner(ctx context.Context, createdBy, name, de
 
 	var w Workspace
 	err = tx.QueryRow(ctx, `
-INSERT INTO workspaces (name, default_currency, created_by)
-VALUES ($1, $2, $3::uuid)
-RETURNING id::text, name, default_currency, created_by::text, created_at`, name, defaultCurrency, createdBy).Scan(
-		&w.ID, &w.Name, &w.DefaultCurrency, &w.CreatedBy, &w.CreatedAt,
+INSERT INTO workspaces (name, default_currency, timezone, created_by)
+VALUES ($1, $2, $3, $4::uuid)
+RETURNING id::text, name, default_currency, timezone, created_by::text, created_at`, name, defaultCurrency, timezone, createdBy).Scan(
+		&w.ID, &w.Name, &w.DefaultCurrency, &w.Timezone, &w.CreatedBy, &w.CreatedAt,
 	)
 	if err != nil {
 		return Workspace{}, err
@@ -53,6 +59,11 @@ values ($1::uuid, $2::uuid, 'owner')
 		return Workspace{}, fmt.Errorf("insert owner: %w", err)
 	}
 
+	if err := recordMember(ctx, tx, w.ID, createdBy, createdBy, audit.ActionCreate,
+		nil, &memberSnapshot{UserID: createdBy, Role: RoleOwner}); err != nil {
+		return Workspace{}, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return Workspace{}, fmt.Errorf("commit: %w", err)
 	}
@@ -61,10 +72,11 @@ values ($1::uuid, $2::uuid, 'owner')
 
 func (r *Repo) GetUserRole(ctx context.Context, workspaceID, userID string) (string, error) {
 	const q = `
-SELECT role
-FROM workspaces_members
-WHERE workspace_id = $1::uuid
-AND user_id = $2::uuid
+SELECT wm.role
+FROM workspaces_members wm
+JOIN workspaces w ON w.id = wm.workspace_id AND w.deleted_at IS NULL
+WHERE wm.workspace_id = $1::uuid
+AND wm.user_id = $2::uuid
 `
 	var role string
 	err := r.pool.QueryRow(ctx, q, workspaceID, userID).Scan(&role)
@@ -83,6 +95,7 @@ SELECT w.id::text, w.name, wm.role, w.created_at
 FROM workspaces w 
 JOIN workspaces_members wm ON wm.workspace_id = w.id
 WHERE wm.user_id = $1::uuid
+AND w.deleted_at IS NULL
 ORDER BY w.created_at DESC
 `
 	rows, err := r.pool.Query(ctx, q, userID)
@@ -106,15 +119,16 @@ ORDER BY w.created_at DESC
 
 func (r *Repo) GetWorkspaceWithRole(ctx context.Context, workspaceID, userID string) (Workspace, Role, error) {
 	const q = `
-SELECT w.id::text, w.name, w.default_currency, w.created_by::text, w.created_at, wm.role
+SELECT w.id::text, w.name, w.default_currency, w.timezone, w.created_by::text, w.created_at, wm.role
 FROM workspaces w
 JOIN workspaces_members wm ON wm.workspace_id = w.id
 WHERE w.id = $1::uuid
 AND wm.user_id = $2::uuid
+AND w.deleted_at IS NULL
 `
 	var w Workspace
 	var role string
-	err := r.pool.QueryRow(ctx, q, workspaceID, userID).Scan(&w.ID, &w.Name, &w.DefaultCurrency, &w.CreatedBy, &w.CreatedAt, &role)
+	err := r.pool.QueryRow(ctx, q, workspaceID, userID).Scan(&w.ID, &w.Name, &w.DefaultCurrency, &w.Timezone, &w.CreatedBy, &w.CreatedAt, &role)
 	if err != nil {
 		return Workspace{}, "", err
 	}
@@ -123,9 +137,10 @@ AND wm.user_id = $2::uuid
 
 func (r *Repo) ListMembersInfo(ctx context.Context, workspaceID string) ([]MemberInfo, error) {
 	const q = `
-SELECT wm.user_id::text, u.email, u.name, wm.role, wm.created_at
+SELECT wm.user_id::text, u.email, u.name, wm.role, wm.custom_role_id::text, cr.name, wm.created_at
 FROM workspaces_members wm
 JOIN users u on u.id = wm.user_id
+LEFT JOIN workspace_roles cr ON cr.id = wm.custom_role_id
 WHERE wm.workspace_id = $1::uuid
 ORDER BY wm.created_at ASC
 `
@@ -139,7 +154,7 @@ ORDER BY wm.created_at ASC
 	for rows.Next() {
 		var m MemberInfo
 		var role string
-		if err := rows.Scan(&m.UserID, &m.Email, &m.Name, &role, &m.CreatedAt); err != nil {
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Name, &role, &m.CustomRoleID, &m.CustomRoleName, &m.CreatedAt); err != nil {
 			return nil, err
 		}
 		m.Role = Role(role)
@@ -162,12 +177,69 @@ func (r *Repo) FindUserIDByEmail(ctx context.Context, email string) (string, err
 	return id, nil
 }
 
-func (r *Repo) AddMemberByUserID(ctx context.Context, workspaceID, userID string, role Role) error {
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// memberSnapshot is the audited state of a membership row.
+type memberSnapshot struct {
+	UserID       string  `json:"user_id"`
+	Role         Role    `json:"role"`
+	CustomRoleID *string `json:"custom_role_id,omitempty"`
+}
+
+func recordMember(ctx context.Context, db execer, workspaceID, actorID, userID string, action audit.Action, before, after *memberSnapshot) error {
+	e := audit.Entry{
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		EntityType:  audit.EntityMember,
+		EntityID:    userID,
+		Action:      action,
+	}
+	if before != nil {
+		e.Before = before
+	}
+	if after != nil {
+		e.After = after
+	}
+	return audit.Record(ctx, db, e)
+}
+
+// lockMember loads and locks a membership row inside tx.
+func lockMember(ctx context.Context, tx pgx.Tx, workspaceID, userID string) (memberSnapshot, error) {
+	m := memberSnapshot{UserID: userID}
+	var role string
+	err := tx.QueryRow(ctx, `
+SELECT role, custom_role_id::text FROM workspaces_members
+WHERE workspace_id = $1::uuid AND user_id = $2::uuid
+FOR UPDATE
+`, workspaceID, userID).Scan(&role, &m.CustomRoleID)
+	if err != nil {
+		return memberSnapshot{}, err
+	}
+	m.Role = Role(role)
+	return m, nil
+}
+
+func (r *Repo) AddMemberByUserID(ctx context.Context, actorUserID, workspaceID, userID string, role Role) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := addMember(ctx, tx, actorUserID, workspaceID, userID, role); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func addMember(ctx context.Context, db execer, actorUserID, workspaceID, userID string, role Role) error {
 	const q = `
 INSERT INTO workspaces_members (workspace_id, user_id, role)
 VALUES ($1::uuid, $2::uuid, $3)
 `
-	_, err := r.pool.Exec(ctx, q, workspaceID, userID, string(role))
+	_, err := db.Exec(ctx, q, workspaceID, userID, string(role))
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -175,6 +247,23 @@ VALUES ($1::uuid, $2::uuid, $3)
 		}
 		return err
 	}
+	return recordMember(ctx, db, workspaceID, actorUserID, userID, audit.ActionCreate,
+		nil, &memberSnapshot{UserID: userID, Role: role})
+}
+
+// checkRoleChange applies the role-change rules, given how many owners the
+// workspace has: an owner cannot demote themselves and the last owner cannot
+// be demoted.
+func checkRoleChange(actorUserID, targetUserID string, current, newRole Role, owners int) error {
+	if current != RoleOwner || newRole == RoleOwner {
+		return nil
+	}
+	if actorUserID == targetUserID {
+		return ErrCannotSelfDemote
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
 	return nil
 }
 
@@ -185,31 +274,22 @@ func (r *Repo) UpdateMemberRoleSafe(ctx context.Context, workspaceID, actorUserI
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	var current string
-	err = tx.QueryRow(ctx, `
-SELECT role FROM workspaces_members
-WHERE workspace_id=$1 AND user_id=$2
-FOR UPDATE
-`, workspaceID, targetUserID).Scan(&current)
+	before, err := lockMember(ctx, tx, workspaceID, targetUserID)
 	if err != nil {
 		return err
 	}
 
-	if actorUserID == targetUserID && Role(current) == RoleOwner && newRole != RoleOwner {
-		return ErrCannotSelfDemote
-	}
-
-	if Role(current) == RoleOwner && newRole != RoleOwner {
-		var owners int
+	var owners int
+	if before.Role == RoleOwner && newRole != RoleOwner {
 		if err := tx.QueryRow(ctx, `
 SELECT count(*) FROM workspaces_members
 WHERE workspace_id = $1::uuid AND role = 'owner'
 `, workspaceID).Scan(&owners); err != nil {
 			return err
 		}
-		if owners <= 1 {
-			return ErrLastOwner
-		}
+	}
+	if err := checkRoleChange(actorUserID, targetUserID, before.Role, newRole, owners); err != nil {
+		return err
 	}
 
 	ct, err := tx.Exec(ctx, `
@@ -224,9 +304,24 @@ WHERE workspace_id = $1::uuid AND user_id = $2::uuid
 		return pgx.ErrNoRows
 	}
 
+	after := before
+	after.Role = newRole
+	if err := recordMember(ctx, tx, workspaceID, actorUserID, targetUserID, audit.ActionUpdate, &before, &after); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
+// checkRemoval keeps the last of a workspace's owners from being removed or
+// leaving.
+func checkRemoval(current Role, owners int) error {
+	if current == RoleOwner && owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
 func (r *Repo) RemoveMemberSafe(ctx context.Context, workspaceID, actorUserID, targetUserID string) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
@@ -234,27 +329,22 @@ func (r *Repo) RemoveMemberSafe(ctx context.Context, workspaceID, actorUserID, t
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	var current string
-	err = tx.QueryRow(ctx, `
-SELECT role FROM workspaces_members
-WHERE workspace_id=$1 AND user_id=$2
-FOR UPDATE
-`, workspaceID, targetUserID).Scan(&current)
+	before, err := lockMember(ctx, tx, workspaceID, targetUserID)
 	if err != nil {
 		return err
 	}
 
-	if Role(current) == RoleOwner {
-		var owners int
+	var owners int
+	if before.Role == RoleOwner {
 		if err := tx.QueryRow(ctx, `
 SELECT count(*) FROM workspaces_members
 WHERE workspace_id = $1::uuid AND role = 'owner'
 `, workspaceID).Scan(&owners); err != nil {
 			return err
 		}
-		if owners <= 1 {
-			return ErrLastOwner
-		}
+	}
+	if err := checkRemoval(before.Role, owners); err != nil {
+		return err
 	}
 
 	ct, err := tx.Exec(ctx, `
@@ -268,11 +358,15 @@ WHERE workspace_id = $1::uuid AND user_id = $2::uuid
 		return pgx.ErrNoRows
 	}
 
+	if err := recordMember(ctx, tx, workspaceID, actorUserID, targetUserID, audit.ActionDelete, &before, nil); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
 func (r *Repo) WorkspaceExists(ctx context.Context, workspaceID string) (bool, error) {
-	const q = `SELECT 1 FROM workspaces WHERE id = $1::uuid`
+	const q = `SELECT 1 FROM workspaces WHERE id = $1::uuid AND deleted_at IS NULL`
 	var one int
 	err := r.pool.QueryRow(ctx, q, workspaceID).Scan(&one)
 	if err != nil {
@@ -283,3 +377,190 @@ func (r *Repo) WorkspaceExists(ctx context.Context, workspaceID string) (bool, e
 	}
 	return true, nil
 }
+
+func (r *Repo) UpdateTimezone(ctx context.Context, workspaceID, timezone string) error {
+	const q = `
+UPDATE workspaces
+SET timezone = $2
+WHERE id = $1::uuid
+`
+	ct, err := r.pool.Exec(ctx, q, workspaceID, timezone)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// EffectiveTimezone returns the user's own timezone when set, otherwise the workspace one.
+func (r *Repo) EffectiveTimezone(ctx context.Context, workspaceID, userID string) (string, error) {
+	const q = `
+SELECT COALESCE(NULLIF(u.timezone, ''), w.timezone)
+FROM workspaces w
+JOIN users u ON u.id = $2::uuid
+WHERE w.id = $1::uuid
+`
+	var tz string
+	err := r.pool.QueryRow(ctx, q, workspaceID, userID).Scan(&tz)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tzx.Default, nil
+		}
+		return "", err
+	}
+	return tz, nil
+}
+
+func (r *Repo) UpdateSettings(ctx context.Context, workspaceID string, name, defaultCurrency *string) error {
+	const q = `
+UPDATE workspaces
+SET name = COALESCE($2, name),
+    default_currency = COALESCE($3, default_currency)
+WHERE id = $1::uuid AND deleted_at IS NULL
+`
+	ct, err := r.pool.Exec(ctx, q, workspaceID, name, defaultCurrency)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *Repo) SetDeletionToken(ctx context.Context, workspaceID, tokenHash string, expiresAt time.Time) error {
+	const q = `
+UPDATE workspaces
+SET deletion_token_hash = $2, deletion_token_expires_at = $3
+WHERE id = $1::uuid AND deleted_at IS NULL
+`
+	ct, err := r.pool.Exec(ctx, q, workspaceID, tokenHash, expiresAt)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// SoftDelete marks the workspace deleted if tokenHash matches an unexpired
+// deletion token, and returns the deletion time.
+// deletionRequest is the locked deletion state checked by SoftDelete.
+type deletionRequest struct {
+	TokenHash *string
+	ExpiresAt *time.Time
+	DeletedAt *time.Time
+}
+
+// confirmableWith returns ErrInvalidDeletionToken unless a deletion was
+// requested with tokenHash, has not expired and the workspace is still live.
+func (d deletionRequest) confirmableWith(tokenHash string, now time.Time) error {
+	if d.DeletedAt != nil || d.TokenHash == nil || d.ExpiresAt == nil {
+		return ErrInvalidDeletionToken
+	}
+	if *d.TokenHash != tokenHash || !now.Before(*d.ExpiresAt) {
+		return ErrInvalidDeletionToken
+	}
+	return nil
+}
+
+func (r *Repo) SoftDelete(ctx context.Context, workspaceID, tokenHash string) (time.Time, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var d deletionRequest
+	err = tx.QueryRow(ctx, `
+SELECT deletion_token_hash, deletion_token_expires_at, deleted_at
+FROM workspaces
+WHERE id = $1::uuid
+FOR UPDATE
+`, workspaceID).Scan(&d.TokenHash, &d.ExpiresAt, &d.DeletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrInvalidDeletionToken
+		}
+		return time.Time{}, err
+	}
+	if err := d.confirmableWith(tokenHash, time.Now()); err != nil {
+		return time.Time{}, err
+	}
+
+	var deletedAt time.Time
+	if err := tx.QueryRow(ctx, `
+UPDATE workspaces
+SET deleted_at = now(), deletion_token_hash = NULL, deletion_token_expires_at = NULL
+WHERE id = $1::uuid
+RETURNING deleted_at
+`, workspaceID).Scan(&deletedAt); err != nil {
+		return time.Time{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return time.Time{}, err
+	}
+	return deletedAt, nil
+}
+
+// Restore undoes a soft delete made after notBefore; only owners may restore.
+func (r *Repo) Restore(ctx context.Context, workspaceID, userID string, notBefore time.Time) error {
+	const q = `
+UPDATE workspaces w
+SET deleted_at = NULL
+FROM workspaces_members wm
+WHERE w.id = $1::uuid
+  AND w.deleted_at IS NOT NULL
+  AND w.deleted_at > $3
+  AND wm.workspace_id = w.id
+  AND wm.user_id = $2::uuid
+  AND wm.role = 'owner'
+`
+	ct, err := r.pool.Exec(ctx, q, workspaceID, userID, notBefore)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *Repo) ListDeletedOwned(ctx context.Context, userID string, notBefore time.Time) ([]DeletedWorkspace, error) {
+	const q = `
+SELECT w.id::text, w.name, w.deleted_at
+FROM workspaces w
+JOIN workspaces_members wm ON wm.workspace_id = w.id
+WHERE wm.user_id = $1::uuid
+  AND wm.role = 'owner'
+  AND w.deleted_at IS NOT NULL
+  AND w.deleted_at > $2
+ORDER BY w.deleted_at DESC
+`
+	rows, err := r.pool.Query(ctx, q, userID, notBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []DeletedWorkspace{}
+	for rows.Next() {
+		var d DeletedWorkspace
+		if err := rows.Scan(&d.ID, &d.Name, &d.DeletedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ct, err := r.pool.Exec(ctx, `DELETE FROM workspaces WHERE deleted_at IS NOT NULL AND deleted_at <= $1`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
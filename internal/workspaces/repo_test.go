@@ -0,0 +1,100 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/skelbigo/FinanceTracker/internal/audit"
+)
+
+type captureExec struct {
+	args []any
+}
+
+func (c *captureExec) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	c.args = args
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func TestRecordMember_Payload(t *testing.T) {
+	roleID := "role-1"
+	tests := []struct {
+		name       string
+		action     audit.Action
+		before     *memberSnapshot
+		after      *memberSnapshot
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name:      "join",
+			action:    audit.ActionCreate,
+			after:     &memberSnapshot{UserID: "u1", Role: RoleMember},
+			wantAfter: `{"user_id":"u1","role":"member"}`,
+		},
+		{
+			name:       "custom role assigned",
+			action:     audit.ActionUpdate,
+			before:     &memberSnapshot{UserID: "u1", Role: RoleViewer},
+			after:      &memberSnapshot{UserID: "u1", Role: RoleViewer, CustomRoleID: &roleID},
+			wantBefore: `{"user_id":"u1","role":"viewer"}`,
+			wantAfter:  `{"user_id":"u1","role":"viewer","custom_role_id":"role-1"}`,
+		},
+		{
+			name:       "removal",
+			action:     audit.ActionDelete,
+			before:     &memberSnapshot{UserID: "u1", Role: RoleMember},
+			wantBefore: `{"user_id":"u1","role":"member"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &captureExec{}
+			if err := recordMember(context.Background(), db, "ws", "actor", "u1", tt.action, tt.before, tt.after); err != nil {
+				t.Fatalf("recordMember: %v", err)
+			}
+			if db.args[2] != string(audit.EntityMember) || db.args[3] != "u1" || db.args[4] != string(tt.action) {
+				t.Errorf("identity args = %v", db.args[:5])
+			}
+			// A nil snapshot must be stored as NULL, not as JSON null.
+			for i, want := range map[int]string{5: tt.wantBefore, 6: tt.wantAfter} {
+				got := db.args[i].(*string)
+				switch {
+				case want == "" && got != nil:
+					t.Errorf("arg %d = %q, want NULL", i, *got)
+				case want != "" && (got == nil || *got != want):
+					t.Errorf("arg %d = %v, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckRoleChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   string
+		current Role
+		newRole Role
+		owners  int
+		want    error
+	}{
+		{name: "owner demotes themselves", actor: "u1", current: RoleOwner, newRole: RoleMember, owners: 2, want: ErrCannotSelfDemote},
+		{name: "sole owner demotes themselves", actor: "u1", current: RoleOwner, newRole: RoleViewer, owners: 1, want: ErrCannotSelfDemote},
+		{name: "owner keeps own role", actor: "u1", current: RoleOwner, newRole: RoleOwner, owners: 1},
+		{name: "demote another owner", actor: "u2", current: RoleOwner, newRole: RoleMember, owners: 2},
+		{name: "demote the last owner", actor: "u2", current: RoleOwner, newRole: RoleMember, owners: 1, want: ErrLastOwner},
+		{name: "promote a member", actor: "u2", current: RoleMember, newRole: RoleOwner},
+		{name: "member to viewer", actor: "u2", current: RoleMember, newRole: RoleViewer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkRoleChange(tt.actor, "u1", tt.current, tt.newRole, tt.owners); !errors.Is(err, tt.want) {
+				t.Errorf("checkRoleChange = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
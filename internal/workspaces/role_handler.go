@@ -0,0 +1,161 @@
+package workspaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/skelbigo/FinanceTracker/internal/httpx"
+)
+
+type customRoleReq struct {
+	Name        string   `json:"name" binding:"required"`
+	Permissions []string `json:"permissions"`
+	CategoryIDs []string `json:"category_ids"`
+	TxTypes     []string `json:"tx_types"`
+}
+
+type assignCustomRoleReq struct {
+	RoleID *string `json:"role_id"`
+}
+
+func (h *Handler) registerRoleRoutes(wsg gin.IRouter) {
+	wsg.GET("/permissions", RequireWorkspaceRole(h.repo, RoleViewer), h.GetMyPermissions)
+
+	g := wsg.Group("/roles")
+	g.GET("", RequireWorkspaceRole(h.repo, RoleViewer), h.ListCustomRoles)
+	// Only owners manage custom roles, so members:manage cannot be used to
+	// grant oneself or others more than a member preset.
+	g.POST("", RequireWorkspaceRole(h.repo, RoleOwner), h.CreateCustomRole)
+	g.PATCH("/:roleId", RequireWorkspaceRole(h.repo, RoleOwner), h.UpdateCustomRole)
+	g.DELETE("/:roleId", RequireWorkspaceRole(h.repo, RoleOwner), h.DeleteCustomRole)
+
+	wsg.PUT("/members/:userId/custom-role", RequireWorkspaceRole(h.repo, RoleOwner), h.AssignCustomRole)
+}
+
+func (h *Handler) GetMyPermissions(c *gin.Context) {
+	access, ok := GetAccess(c)
+	if !ok {
+		httpx.Internal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"role":             access.Role,
+		"custom_role_id":   access.CustomRoleID,
+		"custom_role_name": access.CustomRoleName,
+		"permissions":      access.Permissions,
+		"category_ids":     access.CategoryIDs,
+		"tx_types":         access.TxTypes,
+	})
+}
+
+func (h *Handler) ListCustomRoles(c *gin.Context) {
+	roles, err := h.svc.ListCustomRoles(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		httpx.Internal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": roles, "available_permissions": AllPermissions})
+}
+
+func (h *Handler) CreateCustomRole(c *gin.Context) {
+	var req customRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	cr, err := h.svc.CreateCustomRole(c.Request.Context(), c.Param("id"), req.Name, req.Permissions, req.CategoryIDs, req.TxTypes)
+	if err != nil {
+		writeCustomRoleErr(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"role": cr})
+}
+
+func (h *Handler) UpdateCustomRole(c *gin.Context) {
+	roleID := c.Param("roleId")
+	if _, err := uuid.Parse(roleID); err != nil {
+		httpx.BadRequest(c, "invalid role id", map[string]string{"roleId": "must be uuid"})
+		return
+	}
+
+	var req customRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", nil)
+		return
+	}
+
+	cr, err := h.svc.UpdateCustomRole(c.Request.Context(), c.Param("id"), roleID, req.Name, req.Permissions, req.CategoryIDs, req.TxTypes)
+	if err != nil {
+		writeCustomRoleErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"role": cr})
+}
+
+func (h *Handler) DeleteCustomRole(c *gin.Context) {
+	roleID := c.Param("roleId")
+	if _, err := uuid.Parse(roleID); err != nil {
+		httpx.BadRequest(c, "invalid role id", map[string]string{"roleId": "must be uuid"})
+		return
+	}
+
+	if err := h.svc.DeleteCustomRole(c.Request.Context(), c.Param("id"), roleID); err != nil {
+		writeCustomRoleErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) AssignCustomRole(c *gin.Context) {
+	actorID, ok := userIDFromCtx(c)
+	if !ok {
+		httpx.Unauthorized(c, "invalid token")
+		return
+	}
+
+	targetUserID := c.Param("userId")
+	if _, err := uuid.Parse(targetUserID); err != nil {
+		httpx.BadRequest(c, "invalid user id", map[string]string{"userId": "must be uuid"})
+		return
+	}
+
+	var req assignCustomRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.BadRequest(c, "invalid json", map[string]string{"role_id": "uuid or null"})
+		return
+	}
+
+	if err := h.svc.AssignCustomRole(c.Request.Context(), c.Param("id"), actorID, targetUserID, req.RoleID); err != nil {
+		writeCustomRoleErr(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeCustomRoleErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidName):
+		httpx.Unprocessable(c, "invalid name", map[string]string{"name": "required, up to 50 characters"})
+	case errors.Is(err, ErrInvalidPermission):
+		httpx.Unprocessable(c, "invalid permission", map[string]string{"permissions": "see available_permissions"})
+	case errors.Is(err, ErrInvalidCategories):
+		httpx.Unprocessable(c, "invalid categories", map[string]string{"category_ids": "ids of categories in this workspace"})
+	case errors.Is(err, ErrInvalidTxTypes):
+		httpx.Unprocessable(c, "invalid transaction types", map[string]string{"tx_types": "income|expense"})
+	case errors.Is(err, ErrCustomRoleExists):
+		httpx.Conflict(c, "role with this name already exists")
+	case errors.Is(err, ErrCustomRoleNotFound):
+		httpx.Error(c, http.StatusNotFound, "role not found", nil)
+	case errors.Is(err, ErrCannotAssignToOwner):
+		httpx.Conflict(c, "custom roles cannot be assigned to owners")
+	case errors.Is(err, pgx.ErrNoRows):
+		httpx.Error(c, http.StatusNotFound, "member not found", nil)
+	default:
+		httpx.Internal(c)
+	}
+}
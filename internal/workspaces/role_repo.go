@@ -0,0 +1,208 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/skelbigo/FinanceTracker/internal/audit"
+)
+
+const customRoleColumns = `id::text, workspace_id::text, name, permissions, category_ids::text[], tx_types, created_at, updated_at`
+
+func scanCustomRole(row pgx.Row) (CustomRole, error) {
+	var cr CustomRole
+	var perms []string
+	if err := row.Scan(&cr.ID, &cr.WorkspaceID, &cr.Name, &perms, &cr.CategoryIDs, &cr.TxTypes, &cr.CreatedAt, &cr.UpdatedAt); err != nil {
+		return CustomRole{}, err
+	}
+	cr.Permissions = toPermissions(perms)
+	if cr.CategoryIDs == nil {
+		cr.CategoryIDs = []string{}
+	}
+	if cr.TxTypes == nil {
+		cr.TxTypes = []string{}
+	}
+	return cr, nil
+}
+
+// toPermissions drops stored permissions custom roles may no longer grant.
+func toPermissions(in []string) []Permission {
+	out := make([]Permission, 0, len(in))
+	for _, p := range in {
+		if ValidPermission(Permission(p)) {
+			out = append(out, Permission(p))
+		}
+	}
+	return out
+}
+
+func fromPermissions(in []Permission) []string {
+	out := make([]string, 0, len(in))
+	for _, p := range in {
+		out = append(out, string(p))
+	}
+	return out
+}
+
+func mapCustomRoleErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrCustomRoleExists
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrCustomRoleNotFound
+	}
+	return err
+}
+
+// GetMemberAccess resolves the caller's built-in role and, if assigned, the
+// custom role that overrides its permissions. Non-members get a zero Access.
+func (r *Repo) GetMemberAccess(ctx context.Context, workspaceID, userID string) (Access, error) {
+	const q = `
+SELECT wm.role, cr.id::text, COALESCE(cr.name, ''), cr.permissions, COALESCE(cr.category_ids::text[], '{}'), COALESCE(cr.tx_types, '{}')
+FROM workspaces_members wm
+JOIN workspaces w ON w.id = wm.workspace_id AND w.deleted_at IS NULL
+LEFT JOIN workspace_roles cr ON cr.id = wm.custom_role_id
+WHERE wm.workspace_id = $1::uuid
+  AND wm.user_id = $2::uuid
+`
+	var role string
+	var a Access
+	var perms []string
+	err := r.pool.QueryRow(ctx, q, workspaceID, userID).Scan(&role, &a.CustomRoleID, &a.CustomRoleName, &perms, &a.CategoryIDs, &a.TxTypes)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Access{}, nil
+		}
+		return Access{}, err
+	}
+
+	a.Role = Role(role)
+	// Owners always keep the full preset.
+	if a.CustomRoleID == nil || a.Role == RoleOwner {
+		return NewPresetAccess(a.Role), nil
+	}
+	a.Permissions = toPermissions(perms)
+	return a, nil
+}
+
+func (r *Repo) ListCustomRoles(ctx context.Context, workspaceID string) ([]CustomRole, error) {
+	rows, err := r.pool.Query(ctx, `
+SELECT `+customRoleColumns+`
+FROM workspace_roles
+WHERE workspace_id = $1::uuid
+ORDER BY lower(name) ASC
+`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []CustomRole{}
+	for rows.Next() {
+		cr, err := scanCustomRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cr)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) CreateCustomRole(ctx context.Context, workspaceID, name string, perms []Permission, categoryIDs, txTypes []string) (CustomRole, error) {
+	cr, err := scanCustomRole(r.pool.QueryRow(ctx, `
+INSERT INTO workspace_roles (workspace_id, name, permissions, category_ids, tx_types)
+VALUES ($1::uuid, $2, $3, $4::uuid[], $5)
+RETURNING `+customRoleColumns, workspaceID, name, fromPermissions(perms), categoryIDs, txTypes))
+	if err != nil {
+		return CustomRole{}, mapCustomRoleErr(err)
+	}
+	return cr, nil
+}
+
+func (r *Repo) UpdateCustomRole(ctx context.Context, workspaceID, roleID, name string, perms []Permission, categoryIDs, txTypes []string) (CustomRole, error) {
+	cr, err := scanCustomRole(r.pool.QueryRow(ctx, `
+UPDATE workspace_roles
+SET name = $3, permissions = $4, category_ids = $5::uuid[], tx_types = $6, updated_at = now()
+WHERE id = $2::uuid AND workspace_id = $1::uuid
+RETURNING `+customRoleColumns, workspaceID, roleID, name, fromPermissions(perms), categoryIDs, txTypes))
+	if err != nil {
+		return CustomRole{}, mapCustomRoleErr(err)
+	}
+	return cr, nil
+}
+
+func (r *Repo) DeleteCustomRole(ctx context.Context, workspaceID, roleID string) error {
+	ct, err := r.pool.Exec(ctx, `
+DELETE FROM workspace_roles
+WHERE id = $2::uuid AND workspace_id = $1::uuid
+`, workspaceID, roleID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrCustomRoleNotFound
+	}
+	return nil
+}
+
+// AssignCustomRole sets or clears (nil roleID) a member's custom role.
+func (r *Repo) AssignCustomRole(ctx context.Context, workspaceID, actorUserID, userID string, roleID *string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := lockMember(ctx, tx, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if before.Role == RoleOwner && roleID != nil {
+		return ErrCannotAssignToOwner
+	}
+
+	if roleID != nil {
+		var one int
+		err := tx.QueryRow(ctx, `
+SELECT 1 FROM workspace_roles WHERE id = $2::uuid AND workspace_id = $1::uuid
+`, workspaceID, *roleID).Scan(&one)
+		if err != nil {
+			return mapCustomRoleErr(err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE workspaces_members
+SET custom_role_id = $3::uuid
+WHERE workspace_id = $1::uuid AND user_id = $2::uuid
+`, workspaceID, userID, roleID); err != nil {
+		return err
+	}
+
+	after := before
+	after.CustomRoleID = roleID
+	if err := recordMember(ctx, tx, workspaceID, actorUserID, userID, audit.ActionUpdate, &before, &after); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// CategoriesInWorkspace reports whether all ids belong to the workspace.
+func (r *Repo) CategoriesInWorkspace(ctx context.Context, workspaceID string, ids []string) (bool, error) {
+	if len(ids) == 0 {
+		return true, nil
+	}
+	var n int
+	err := r.pool.QueryRow(ctx, `
+SELECT count(*) FROM categories
+WHERE workspace_id = $1::uuid AND id = ANY($2::uuid[])
+`, workspaceID, ids).Scan(&n)
+	if err != nil {
+		return false, err
+	}
+	return n == len(ids), nil
+}
@@ -2,7 +2,15 @@ package workspaces
 
 import (
 	"context"
+	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/skelbigo/FinanceTracker/internal/auth"
+	"github.com/skelbigo/FinanceTracker/internal/tzx"
 )
 
 type Service struct {
@@ -13,11 +21,19 @@ func NewService(repo *Repo) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) CreateWorkspace(ctx context.Context, creatorID, name, currency string) (Workspace, Role, error) {
+func (s *Service) CreateWorkspace(ctx context.Context, creatorID, name, currency, timezone string) (Workspace, Role, error) {
 	name = strings.TrimSpace(name)
 	currency = strings.TrimSpace(currency)
 
-	w, err := s.repo.CreateWorkspaceWithOwner(ctx, creatorID, name, currency)
+	if strings.TrimSpace(timezone) != "" {
+		tz, err := tzx.Normalize(timezone)
+		if err != nil {
+			return Workspace{}, "", err
+		}
+		timezone = tz
+	}
+
+	w, err := s.repo.CreateWorkspaceWithOwner(ctx, creatorID, name, currency, timezone)
 	if err != nil {
 		return Workspace{}, "", err
 	}
@@ -36,19 +52,24 @@ func (s *Service) ListMembers(ctx context.Context, workspaceID string) ([]Member
 	return s.repo.ListMembersInfo(ctx, workspaceID)
 }
 
-func (s *Service) AddMemberByEmail(ctx context.Context, workspaceID, email string, role Role) error {
+func (s *Service) AddMemberByEmail(ctx context.Context, workspaceID, actorUserID, email string, role Role) error {
 	email = strings.TrimSpace(strings.ToLower(email))
 
 	if role != RoleOwner && role != RoleMember && role != RoleViewer {
 		return ErrInvalidRole
 	}
+	if role == RoleOwner {
+		if err := requireOwner(ctx, s.repo, workspaceID, actorUserID); err != nil {
+			return err
+		}
+	}
 
 	userID, err := s.repo.FindUserIDByEmail(ctx, email)
 	if err != nil {
 		return err
 	}
 
-	if err := s.repo.AddMemberByUserID(ctx, workspaceID, userID, role); err != nil {
+	if err := s.repo.AddMemberByUserID(ctx, actorUserID, workspaceID, userID, role); err != nil {
 		return err
 	}
 
@@ -59,9 +80,277 @@ func (s *Service) UpdateMemberRole(ctx context.Context, workspaceID, actorUserID
 	if newRole != RoleOwner && newRole != RoleMember && newRole != RoleViewer {
 		return ErrInvalidRole
 	}
+	if err := s.guardOwnerTarget(ctx, workspaceID, actorUserID, targetUserID, newRole == RoleOwner); err != nil {
+		return err
+	}
 	return s.repo.UpdateMemberRoleSafe(ctx, workspaceID, actorUserID, targetUserID, newRole)
 }
 
 func (s *Service) RemoveMember(ctx context.Context, workspaceID, actorUserID, targetUserID string) error {
+	if err := s.guardOwnerTarget(ctx, workspaceID, actorUserID, targetUserID, false); err != nil {
+		return err
+	}
 	return s.repo.RemoveMemberSafe(ctx, workspaceID, actorUserID, targetUserID)
 }
+
+// guardOwnerTarget keeps members with members:manage but without the owner
+// role from promoting to, or acting on, owners.
+func (s *Service) guardOwnerTarget(ctx context.Context, workspaceID, actorUserID, targetUserID string, grantsOwner bool) error {
+	if !grantsOwner {
+		target, err := s.repo.GetUserRole(ctx, workspaceID, targetUserID)
+		if err != nil {
+			return err
+		}
+		if Role(target) != RoleOwner {
+			return nil
+		}
+	}
+	return requireOwner(ctx, s.repo, workspaceID, actorUserID)
+}
+
+func requireOwner(ctx context.Context, repo *Repo, workspaceID, userID string) error {
+	role, err := repo.GetUserRole(ctx, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if Role(role) != RoleOwner {
+		return ErrOwnerRoleRequired
+	}
+	return nil
+}
+
+func (s *Service) UpdateTimezone(ctx context.Context, workspaceID, timezone string) error {
+	tz, err := tzx.Normalize(timezone)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateTimezone(ctx, workspaceID, tz)
+}
+
+func (s *Service) EffectiveLocation(ctx context.Context, workspaceID, userID string) (*time.Location, error) {
+	tz, err := s.repo.EffectiveTimezone(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return tzx.Load(tz), nil
+}
+
+const (
+	// DeletionGracePeriod is how long a soft-deleted workspace can be restored
+	// before it is purged for good.
+	DeletionGracePeriod = 30 * 24 * time.Hour
+
+	deletionTokenTTL = 15 * time.Minute
+	maxNameLen       = 100
+)
+
+var currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// UpdateSettings changes the name and/or default currency; nil leaves a
+// field untouched.
+func (s *Service) UpdateSettings(ctx context.Context, workspaceID string, name, defaultCurrency *string) error {
+	name, defaultCurrency, err := normalizeSettings(name, defaultCurrency)
+	if err != nil {
+		return err
+	}
+	if name == nil && defaultCurrency == nil {
+		return nil
+	}
+	return s.repo.UpdateSettings(ctx, workspaceID, name, defaultCurrency)
+}
+
+// normalizeSettings trims and validates the fields UpdateSettings changes,
+// upper-casing the currency code.
+func normalizeSettings(name, defaultCurrency *string) (*string, *string, error) {
+	if name != nil {
+		v := strings.TrimSpace(*name)
+		if v == "" || utf8.RuneCountInString(v) > maxNameLen {
+			return nil, nil, ErrInvalidName
+		}
+		name = &v
+	}
+	if defaultCurrency != nil {
+		v := strings.ToUpper(strings.TrimSpace(*defaultCurrency))
+		if !currencyRe.MatchString(v) {
+			return nil, nil, ErrInvalidCurrency
+		}
+		defaultCurrency = &v
+	}
+	return name, defaultCurrency, nil
+}
+
+// RequestDeletion issues a short-lived token that must be echoed back to
+// ConfirmDeletion.
+func (s *Service) RequestDeletion(ctx context.Context, workspaceID string) (string, time.Time, error) {
+	token, err := auth.GenerateResetToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(deletionTokenTTL)
+	if err := s.repo.SetDeletionToken(ctx, workspaceID, auth.HashResetToken(token), expiresAt); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ConfirmDeletion soft-deletes the workspace and returns when it will be purged.
+func (s *Service) ConfirmDeletion(ctx context.Context, workspaceID, token string) (time.Time, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return time.Time{}, ErrInvalidDeletionToken
+	}
+	deletedAt, err := s.repo.SoftDelete(ctx, workspaceID, auth.HashResetToken(token))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return deletedAt.Add(DeletionGracePeriod), nil
+}
+
+func (s *Service) Restore(ctx context.Context, workspaceID, userID string) error {
+	return s.repo.Restore(ctx, workspaceID, userID, time.Now().Add(-DeletionGracePeriod))
+}
+
+func (s *Service) ListDeleted(ctx context.Context, userID string) ([]DeletedWorkspace, error) {
+	items, err := s.repo.ListDeletedOwned(ctx, userID, time.Now().Add(-DeletionGracePeriod))
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].PurgeAt = items[i].DeletedAt.Add(DeletionGracePeriod)
+	}
+	return items, nil
+}
+
+// PurgeDeleted permanently removes workspaces whose grace period has passed.
+func (s *Service) PurgeDeleted(ctx context.Context) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, time.Now().Add(-DeletionGracePeriod))
+}
+
+// Leave removes the caller from the workspace; the last owner cannot leave.
+func (s *Service) Leave(ctx context.Context, workspaceID, userID string) error {
+	return s.repo.RemoveMemberSafe(ctx, workspaceID, userID, userID)
+}
+
+func (s *Service) NominateOwner(ctx context.Context, workspaceID, fromUserID, toUserID string) (OwnershipTransfer, error) {
+	toUserID = strings.TrimSpace(toUserID)
+	if toUserID == "" || toUserID == fromUserID {
+		return OwnershipTransfer{}, ErrInvalidTransferTarget
+	}
+	return s.repo.CreateOwnershipTransfer(ctx, workspaceID, fromUserID, toUserID)
+}
+
+func (s *Service) PendingTransfer(ctx context.Context, workspaceID string) (OwnershipTransfer, error) {
+	return s.repo.GetPendingTransfer(ctx, workspaceID)
+}
+
+func (s *Service) ListTransfers(ctx context.Context, workspaceID string) ([]OwnershipTransfer, error) {
+	return s.repo.ListTransfers(ctx, workspaceID)
+}
+
+func (s *Service) CancelTransfer(ctx context.Context, workspaceID string) error {
+	return s.repo.CloseTransfer(ctx, workspaceID, "", TransferCancelled)
+}
+
+func (s *Service) DeclineTransfer(ctx context.Context, workspaceID, userID string) error {
+	return s.repo.CloseTransfer(ctx, workspaceID, userID, TransferDeclined)
+}
+
+func (s *Service) AcceptTransfer(ctx context.Context, workspaceID, userID string) error {
+	return s.repo.AcceptTransfer(ctx, workspaceID, userID)
+}
+
+const maxCustomRoleNameLen = 50
+
+func (s *Service) Access(ctx context.Context, workspaceID, userID string) (Access, error) {
+	return s.repo.GetMemberAccess(ctx, workspaceID, userID)
+}
+
+func (s *Service) ListCustomRoles(ctx context.Context, workspaceID string) ([]CustomRole, error) {
+	return s.repo.ListCustomRoles(ctx, workspaceID)
+}
+
+func (s *Service) CreateCustomRole(ctx context.Context, workspaceID, name string, perms, categoryIDs, txTypes []string) (CustomRole, error) {
+	in, err := s.validateCustomRole(ctx, workspaceID, name, perms, categoryIDs, txTypes)
+	if err != nil {
+		return CustomRole{}, err
+	}
+	return s.repo.CreateCustomRole(ctx, workspaceID, in.name, in.perms, in.categoryIDs, in.txTypes)
+}
+
+func (s *Service) UpdateCustomRole(ctx context.Context, workspaceID, roleID, name string, perms, categoryIDs, txTypes []string) (CustomRole, error) {
+	in, err := s.validateCustomRole(ctx, workspaceID, name, perms, categoryIDs, txTypes)
+	if err != nil {
+		return CustomRole{}, err
+	}
+	return s.repo.UpdateCustomRole(ctx, workspaceID, roleID, in.name, in.perms, in.categoryIDs, in.txTypes)
+}
+
+func (s *Service) DeleteCustomRole(ctx context.Context, workspaceID, roleID string) error {
+	return s.repo.DeleteCustomRole(ctx, workspaceID, roleID)
+}
+
+// AssignCustomRole sets a member's custom role; a nil roleID reverts the
+// member to their built-in role preset.
+func (s *Service) AssignCustomRole(ctx context.Context, workspaceID, actorUserID, userID string, roleID *string) error {
+	if roleID != nil {
+		v := strings.TrimSpace(*roleID)
+		if v == "" {
+			roleID = nil
+		} else if _, err := uuid.Parse(v); err != nil {
+			return ErrCustomRoleNotFound
+		} else {
+			roleID = &v
+		}
+	}
+	return s.repo.AssignCustomRole(ctx, workspaceID, actorUserID, userID, roleID)
+}
+
+// customRoleInput is a validated custom role definition.
+type customRoleInput struct {
+	name        string
+	perms       []Permission
+	categoryIDs []string
+	txTypes     []string
+}
+
+func (s *Service) validateCustomRole(ctx context.Context, workspaceID, name string, perms, categoryIDs, txTypes []string) (customRoleInput, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxCustomRoleNameLen {
+		return customRoleInput{}, ErrInvalidName
+	}
+
+	ps, err := NormalizePermissions(perms)
+	if err != nil {
+		return customRoleInput{}, err
+	}
+
+	types, err := NormalizeTxTypes(txTypes)
+	if err != nil {
+		return customRoleInput{}, err
+	}
+
+	cats := make([]string, 0, len(categoryIDs))
+	seen := map[string]struct{}{}
+	for _, raw := range categoryIDs {
+		id := strings.TrimSpace(raw)
+		if id == "" {
+			continue
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return customRoleInput{}, ErrInvalidCategories
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		cats = append(cats, id)
+	}
+	ok, err := s.repo.CategoriesInWorkspace(ctx, workspaceID, cats)
+	if err != nil {
+		return customRoleInput{}, err
+	}
+	if !ok {
+		return customRoleInput{}, ErrInvalidCategories
+	}
+	return customRoleInput{name: name, perms: ps, categoryIDs: cats, txTypes: types}, nil
+}
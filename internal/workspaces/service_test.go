@@ -0,0 +1,129 @@
+package workspaces
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeSettings(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name         string
+		inName       *string
+		inCurrency   *string
+		wantName     *string
+		wantCurrency *string
+		want         error
+	}{
+		{name: "rename trims", inName: str("  Family  "), wantName: str("Family")},
+		{name: "currency upper-cased", inCurrency: str(" usd "), wantCurrency: str("USD")},
+		{name: "both", inName: str("Home"), inCurrency: str("UAH"), wantName: str("Home"), wantCurrency: str("UAH")},
+		{name: "nothing to change"},
+		{name: "blank name", inName: str("   "), want: ErrInvalidName},
+		{name: "long name", inName: str(strings.Repeat("я", maxNameLen+1)), want: ErrInvalidName},
+		{name: "longest name", inName: str(strings.Repeat("я", maxNameLen)), wantName: str(strings.Repeat("я", maxNameLen))},
+		{name: "short currency", inCurrency: str("US"), want: ErrInvalidCurrency},
+		{name: "currency with digits", inCurrency: str("US1"), want: ErrInvalidCurrency},
+		{name: "blank currency", inCurrency: str(""), want: ErrInvalidCurrency},
+		{name: "bad currency with good name", inName: str("Home"), inCurrency: str("dollars"), want: ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, currency, err := normalizeSettings(tt.inName, tt.inCurrency)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if !equalPtr(name, tt.wantName) || !equalPtr(currency, tt.wantCurrency) {
+				t.Errorf("got %v, %v; want %v, %v", deref(name), deref(currency), deref(tt.wantName), deref(tt.wantCurrency))
+			}
+		})
+	}
+}
+
+func TestUpdateSettings_RejectsBeforeSaving(t *testing.T) {
+	// A zero Service has no repo, so reaching it would panic.
+	s := &Service{}
+	blank, bad := " ", "euro"
+	if err := s.UpdateSettings(context.Background(), "ws", &blank, nil); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("blank name err = %v, want ErrInvalidName", err)
+	}
+	if err := s.UpdateSettings(context.Background(), "ws", nil, &bad); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("bad currency err = %v, want ErrInvalidCurrency", err)
+	}
+	if err := s.UpdateSettings(context.Background(), "ws", nil, nil); err != nil {
+		t.Errorf("no-op err = %v, want nil", err)
+	}
+}
+
+func TestConfirmDeletion_BlankToken(t *testing.T) {
+	s := &Service{}
+	for _, token := range []string{"", "   "} {
+		if _, err := s.ConfirmDeletion(context.Background(), "ws", token); !errors.Is(err, ErrInvalidDeletionToken) {
+			t.Errorf("ConfirmDeletion(%q) err = %v, want ErrInvalidDeletionToken", token, err)
+		}
+	}
+}
+
+func TestDeletionRequestConfirmableWith(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	hash, other := "hash", "other"
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+
+	tests := []struct {
+		name string
+		d    deletionRequest
+		want error
+	}{
+		{name: "requested and fresh", d: deletionRequest{TokenHash: &hash, ExpiresAt: &later}},
+		{name: "never requested", d: deletionRequest{}, want: ErrInvalidDeletionToken},
+		{name: "another token", d: deletionRequest{TokenHash: &other, ExpiresAt: &later}, want: ErrInvalidDeletionToken},
+		{name: "expired", d: deletionRequest{TokenHash: &hash, ExpiresAt: &earlier}, want: ErrInvalidDeletionToken},
+		{name: "expires now", d: deletionRequest{TokenHash: &hash, ExpiresAt: &now}, want: ErrInvalidDeletionToken},
+		{name: "already deleted", d: deletionRequest{TokenHash: &hash, ExpiresAt: &later, DeletedAt: &earlier}, want: ErrInvalidDeletionToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.d.confirmableWith(hash, now); !errors.Is(err, tt.want) {
+				t.Errorf("confirmableWith = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRemoval(t *testing.T) {
+	tests := []struct {
+		name    string
+		current Role
+		owners  int
+		want    error
+	}{
+		{name: "last owner leaves", current: RoleOwner, owners: 1, want: ErrLastOwner},
+		{name: "one of two owners leaves", current: RoleOwner, owners: 2},
+		{name: "member leaves", current: RoleMember, owners: 1},
+		{name: "viewer leaves", current: RoleViewer, owners: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkRemoval(tt.current, tt.owners); !errors.Is(err, tt.want) {
+				t.Errorf("checkRemoval = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func equalPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref(p *string) string {
+	if p == nil {
+		return "<nil>"
+	}
+	return *p
+}
@@ -0,0 +1,5 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone;
+
+ALTER TABLE workspaces
+DROP COLUMN IF EXISTS timezone;
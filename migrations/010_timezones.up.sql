@@ -0,0 +1,5 @@
+ALTER TABLE workspaces
+ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS timezone TEXT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS manual_account_valuations;
+DROP TABLE IF EXISTS manual_accounts;
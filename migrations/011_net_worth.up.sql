@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS manual_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('asset', 'liability')),
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_manual_accounts_workspace_name
+ON manual_accounts(workspace_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS manual_account_valuations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES manual_accounts(id) ON DELETE CASCADE,
+    valued_on DATE NOT NULL,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT manual_account_valuations_unique_day UNIQUE (account_id, valued_on)
+);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (workspace_id, currency, rate_date)
+);
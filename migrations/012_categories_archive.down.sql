@@ -0,0 +1,2 @@
+ALTER TABLE categories
+DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,2 @@
+ALTER TABLE categories
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ NULL;
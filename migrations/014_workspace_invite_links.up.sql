@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS workspace_invite_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'member', 'viewer')),
+    token_hash TEXT NOT NULL UNIQUE,
+    max_uses INT CHECK (max_uses IS NULL OR max_uses > 0),
+    use_count INT NOT NULL DEFAULT 0 CHECK (use_count >= 0),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_invite_links_workspace_id ON workspace_invite_links(workspace_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_workspaces_deleted_at;
+
+ALTER TABLE workspaces
+DROP COLUMN IF EXISTS deletion_token_expires_at,
+DROP COLUMN IF EXISTS deletion_token_hash,
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,6 @@
+ALTER TABLE workspaces
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL,
+ADD COLUMN IF NOT EXISTS deletion_token_hash TEXT NULL,
+ADD COLUMN IF NOT EXISTS deletion_token_expires_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_workspaces_deleted_at ON workspaces(deleted_at) WHERE deleted_at IS NOT NULL;
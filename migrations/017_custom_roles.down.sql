@@ -0,0 +1,4 @@
+ALTER TABLE workspaces_members
+DROP COLUMN IF EXISTS custom_role_id;
+
+DROP TABLE IF EXISTS workspace_roles;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS workspace_roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    category_ids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_workspace_roles_workspace_name
+ON workspace_roles(workspace_id, lower(name));
+
+ALTER TABLE workspaces_members
+ADD COLUMN IF NOT EXISTS custom_role_id UUID NULL REFERENCES workspace_roles(id) ON DELETE SET NULL;
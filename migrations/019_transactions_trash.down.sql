@@ -0,0 +1,12 @@
+DELETE FROM transactions WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE audit_events DROP CONSTRAINT IF EXISTS audit_events_action_check;
+
+-- Restore events may already exist; keep them but validate new rows only.
+ALTER TABLE audit_events
+    ADD CONSTRAINT audit_events_action_check
+    CHECK (action IN ('create', 'update', 'delete')) NOT VALID;
+
+DROP INDEX IF EXISTS idx_transactions_deleted;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,12 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted
+ON transactions(workspace_id, deleted_at DESC)
+WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE audit_events DROP CONSTRAINT IF EXISTS audit_events_action_check;
+
+ALTER TABLE audit_events
+    ADD CONSTRAINT audit_events_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'restore'));
@@ -0,0 +1,11 @@
+-- Each row is a previous state of a transaction, captured right before an
+-- update replaced it. The current state lives in transactions itself.
+CREATE TABLE IF NOT EXISTS transaction_versions (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    version INT NOT NULL CHECK (version > 0),
+    snapshot JSONB NOT NULL,
+    replaced_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (transaction_id, version)
+);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_transaction_attachments_queue_deletion ON transaction_attachments;
+DROP FUNCTION IF EXISTS queue_attachment_deletion();
+DROP TABLE IF EXISTS attachment_deletions;
+DROP TABLE IF EXISTS transaction_attachments;
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS transaction_attachments (
+    id UUID PRIMARY KEY,
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    storage_key TEXT NOT NULL UNIQUE,
+    uploaded_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_attachments_tx
+ON transaction_attachments(transaction_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_attachments_workspace
+ON transaction_attachments(workspace_id);
+
+-- Stored objects outlive their rows when a transaction or workspace is purged
+-- through a cascade. Every removed row queues its object for deletion so the
+-- background job can clean up the storage backend.
+CREATE TABLE IF NOT EXISTS attachment_deletions (
+    storage_key TEXT PRIMARY KEY,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE OR REPLACE FUNCTION queue_attachment_deletion()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO attachment_deletions (storage_key) VALUES (OLD.storage_key)
+    ON CONFLICT (storage_key) DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_transaction_attachments_queue_deletion ON transaction_attachments;
+
+CREATE TRIGGER trg_transaction_attachments_queue_deletion
+AFTER DELETE ON transaction_attachments
+FOR EACH ROW
+EXECUTE FUNCTION queue_attachment_deletion();
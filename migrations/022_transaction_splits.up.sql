@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    category_id UUID NULL REFERENCES categories(id),
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    note TEXT NULL,
+    UNIQUE (transaction_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_category
+ON transaction_splits(category_id);
+
+-- transaction_lines is what per-category reports aggregate: one row per split
+-- line for split transactions and one row for every other transaction.
+CREATE OR REPLACE VIEW transaction_lines AS
+SELECT
+    t.id AS transaction_id,
+    t.workspace_id,
+    t.user_id,
+    t.type,
+    t.currency,
+    t.occurred_at,
+    t.deleted_at,
+    CASE WHEN s.id IS NULL THEN t.category_id ELSE s.category_id END AS category_id,
+    COALESCE(s.amount_minor, t.amount_minor) AS amount_minor,
+    CASE WHEN s.id IS NULL THEN t.note ELSE s.note END AS note
+FROM transactions t
+LEFT JOIN transaction_splits s ON s.transaction_id = t.id;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transactions_ws_amount_id;
+DROP INDEX IF EXISTS idx_transactions_ws_occurred_id;
@@ -0,0 +1,8 @@
+-- Keyset pagination walks (sort column, id) in either direction.
+CREATE INDEX IF NOT EXISTS idx_transactions_ws_occurred_id
+ON transactions(workspace_id, occurred_at DESC, id DESC)
+WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_ws_amount_id
+ON transactions(workspace_id, amount_minor DESC, id DESC)
+WHERE deleted_at IS NULL;
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_transactions_search_vector;
+
+DROP TRIGGER IF EXISTS trg_categories_search_vector ON categories;
+DROP FUNCTION IF EXISTS categories_search_vector_refresh();
+
+DROP TRIGGER IF EXISTS trg_transaction_splits_search_vector ON transaction_splits;
+DROP FUNCTION IF EXISTS transaction_splits_search_vector_refresh();
+
+DROP TRIGGER IF EXISTS trg_transactions_search_vector ON transactions;
+DROP FUNCTION IF EXISTS transactions_search_vector_refresh();
+
+DROP FUNCTION IF EXISTS transaction_search_vector(UUID, TEXT, TEXT[], UUID);
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS search_vector;
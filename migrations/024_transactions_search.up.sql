@@ -0,0 +1,83 @@
+-- search_vector indexes a transaction's note, tags and category names for
+-- full-text search. A generated column cannot read category names or split
+-- lines, so triggers keep it current instead.
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS search_vector tsvector NOT NULL DEFAULT ''::tsvector;
+
+CREATE OR REPLACE FUNCTION transaction_search_vector(p_id UUID, p_note TEXT, p_tags TEXT[], p_category_id UUID)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(p_note, '')), 'A')
+        || setweight(to_tsvector('simple', array_to_string(COALESCE(p_tags, '{}'), ' ')), 'B')
+        || setweight(to_tsvector('simple', COALESCE((SELECT name FROM categories WHERE id = p_category_id), '')), 'C')
+        || setweight(to_tsvector('simple', COALESCE((
+               SELECT string_agg(COALESCE(c.name, '') || ' ' || COALESCE(s.note, ''), ' ')
+               FROM transaction_splits s
+               LEFT JOIN categories c ON c.id = s.category_id
+               WHERE s.transaction_id = p_id
+           ), '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION transactions_search_vector_refresh()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := transaction_search_vector(NEW.id, NEW.note, NEW.tags, NEW.category_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_transactions_search_vector ON transactions;
+
+CREATE TRIGGER trg_transactions_search_vector
+BEFORE INSERT OR UPDATE OF note, tags, category_id ON transactions
+FOR EACH ROW
+EXECUTE FUNCTION transactions_search_vector_refresh();
+
+CREATE OR REPLACE FUNCTION transaction_splits_search_vector_refresh()
+RETURNS TRIGGER AS $$
+DECLARE
+    tx_id UUID := CASE WHEN TG_OP = 'DELETE' THEN OLD.transaction_id ELSE NEW.transaction_id END;
+BEGIN
+    UPDATE transactions
+    SET search_vector = transaction_search_vector(id, note, tags, category_id)
+    WHERE id = tx_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_transaction_splits_search_vector ON transaction_splits;
+
+CREATE TRIGGER trg_transaction_splits_search_vector
+AFTER INSERT OR UPDATE OR DELETE ON transaction_splits
+FOR EACH ROW
+EXECUTE FUNCTION transaction_splits_search_vector_refresh();
+
+-- Renaming a category rewrites the search_vector of every transaction filed
+-- under it, directly or through a split line, inside the renaming statement.
+-- That is one row update per transaction and holds their row locks until the
+-- rename commits, so renaming a category with a long history is noticeably
+-- slower than other category edits. Renames are rare enough to accept this in
+-- exchange for search results that never show a stale category name.
+CREATE OR REPLACE FUNCTION categories_search_vector_refresh()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE transactions
+    SET search_vector = transaction_search_vector(id, note, tags, category_id)
+    WHERE category_id = NEW.id
+       OR id IN (SELECT transaction_id FROM transaction_splits WHERE category_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_categories_search_vector ON categories;
+
+CREATE TRIGGER trg_categories_search_vector
+AFTER UPDATE OF name ON categories
+FOR EACH ROW
+WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION categories_search_vector_refresh();
+
+UPDATE transactions
+SET search_vector = transaction_search_vector(id, note, tags, category_id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_search_vector
+ON transactions USING GIN (search_vector);
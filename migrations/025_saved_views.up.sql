@@ -0,0 +1,21 @@
+-- A saved view is a named set of transaction list filters. Views with a
+-- user_id are personal; views without one are shared with the workspace.
+CREATE TABLE IF NOT EXISTS saved_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (length(btrim(name)) > 0),
+    filters JSONB NOT NULL DEFAULT '{}'::jsonb,
+    sort TEXT NOT NULL DEFAULT '',
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_saved_views_shared_name
+ON saved_views(workspace_id, lower(name))
+WHERE user_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_saved_views_personal_name
+ON saved_views(workspace_id, user_id, lower(name))
+WHERE user_id IS NOT NULL;
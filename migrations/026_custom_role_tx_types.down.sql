@@ -0,0 +1,2 @@
+ALTER TABLE workspace_roles
+DROP COLUMN IF EXISTS tx_types;
@@ -0,0 +1,2 @@
+ALTER TABLE workspace_roles
+ADD COLUMN IF NOT EXISTS tx_types TEXT[] NOT NULL DEFAULT '{}';